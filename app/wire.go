@@ -14,7 +14,11 @@ import (
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/services"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/cache"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/fiber"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/jwtkeys"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/mailer"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/secretbox"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/storage"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/websocket"
 )
 
@@ -24,6 +28,10 @@ var AppProviderSet = wire.NewSet(
 	database.DatabaseProviderSet,
 	cache.ProvideCache,
 	cache.ProvideRedisClient,
+	jwtkeys.ProvideKeySet,
+	storage.ProvideStorage,
+	mailer.ProvideMailer,
+	secretbox.ProvideBox,
 	fiber.NewFiber,
 	guards.GuardProviderSet,
 	websocket.WebSocketProviderSet,
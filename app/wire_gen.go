@@ -17,7 +17,11 @@ import (
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/services"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/cache"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/fiber"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/jwtkeys"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/mailer"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/secretbox"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/storage"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/websocket"
 )
 
@@ -43,27 +47,65 @@ func AppFactory() (*ServiceApp, error) {
 	healthHandler := handlers.ProvideHealthHandler(configConfig)
 	queries := database.ProvideDatabaseQueries(databaseConnection)
 	authRepository := repositories.ProvideAuthRepository(queries)
-	tokenService := services.ProvideTokenService(cacheCache, configConfig)
-	authService := services.ProvideAuthService(authRepository, tokenService, loggerLogger)
-	authGuard := guards.ProvideAuthGuard(tokenService, cacheCache)
-	authHandler := handlers.ProvideAuthHandler(authService, authGuard)
+	auditLogRepository := repositories.ProvideAuditLogRepository(queries)
+	auditService := services.ProvideAuditService(loggerLogger, auditLogRepository)
+	keySet, err := jwtkeys.ProvideKeySet(configConfig, loggerLogger)
+	if err != nil {
+		return nil, err
+	}
 	userRepository := repositories.ProvideUserRepository(queries)
+	tokenService := services.ProvideTokenService(cacheCache, configConfig, keySet, loggerLogger, userRepository, auditService)
+	mailerMailer, err := mailer.ProvideMailer(configConfig, loggerLogger)
+	if err != nil {
+		return nil, err
+	}
+	loginAttemptService := services.ProvideLoginAttemptService(cacheCache, configConfig)
+	pool := database.ProvideDatabasePool(databaseConnection)
+	box, err := secretbox.ProvideBox(configConfig, loggerLogger)
+	if err != nil {
+		return nil, err
+	}
+	mfaRepository := repositories.ProvideMFARepository(queries, box)
+	mfaService := services.ProvideMFAService(pool, configConfig, loggerLogger, mfaRepository, loginAttemptService)
+	apiKeyRepository := repositories.ProvideAPIKeyRepository(queries)
+	authService := services.ProvideAuthService(pool, authRepository, apiKeyRepository, tokenService, loginAttemptService, mfaService, mailerMailer, configConfig, loggerLogger)
+	identityRepository := repositories.ProvideIdentityRepository(queries)
+	oidcService := services.ProvideOIDCService(pool, cacheCache, configConfig, loggerLogger, authRepository, identityRepository, tokenService, loginAttemptService, mfaService)
+	apiKeyService := services.ProvideAPIKeyService(loggerLogger, apiKeyRepository)
+	authGuard := guards.ProvideAuthGuard(tokenService, apiKeyService)
+	authHandler := handlers.ProvideAuthHandler(authService, oidcService, mfaService, authGuard)
 	userService := services.ProvideUserService(userRepository, tokenService, loggerLogger)
-	userHandler := handlers.ProvideUserHandler(userService, authGuard)
+	userHandler := handlers.ProvideUserHandler(userService, apiKeyService, authGuard)
 	quizRepository := repositories.ProvideQuizRepository(queries)
 	questionRepository := repositories.ProvideQuestionRepository(queries)
-	validationService := services.ProvideValidationService(quizRepository, questionRepository)
-	quizService := services.ProvideQuizService(loggerLogger, quizRepository, questionRepository, validationService)
-	quizHandler := handlers.ProvideQuizHandler(quizService, authGuard)
-	questionService := services.ProvideQuestionService(loggerLogger, questionRepository, quizRepository, validationService)
+	quizCollaboratorRepository := repositories.ProvideQuizCollaboratorRepository(queries)
+	validationService := services.ProvideValidationService(quizRepository, questionRepository, quizCollaboratorRepository)
+	quizVersionRepository := repositories.ProvideQuizVersionRepository(queries)
+	quizVersionService := services.ProvideQuizVersionService(configConfig, pool, loggerLogger, quizVersionRepository, quizRepository, questionRepository, validationService)
+	quizService := services.ProvideQuizService(configConfig, pool, loggerLogger, quizRepository, questionRepository, quizCollaboratorRepository, validationService, quizVersionService)
+	tagRepository := repositories.ProvideTagRepository(queries)
+	tagService := services.ProvideTagService(pool, loggerLogger, tagRepository, validationService, quizService)
+	quizCollaboratorService := services.ProvideQuizCollaboratorService(pool, loggerLogger, quizCollaboratorRepository, quizRepository, userRepository, validationService)
+	quizHandler := handlers.ProvideQuizHandler(quizService, quizVersionService, tagService, quizCollaboratorService, authGuard)
+	storageStorage, err := storage.ProvideStorage(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	mediaRepository := repositories.ProvideMediaRepository(queries)
+	mediaService := services.ProvideMediaService(configConfig, loggerLogger, storageStorage, mediaRepository)
+	questionService := services.ProvideQuestionService(configConfig, pool, loggerLogger, questionRepository, quizRepository, validationService, quizVersionService, mediaService)
 	questionHandler := handlers.ProvideQuestionHandler(configConfig, questionService, authGuard)
 	sessionRepository := repositories.ProvideSessionRepository(queries)
-	sessionService := services.ProvideSessionService(sessionRepository, quizRepository, questionRepository, loggerLogger)
+	sessionService := services.ProvideSessionService(pool, sessionRepository, quizRepository, questionRepository, quizVersionRepository, quizVersionService, validationService, loggerLogger)
 	gameHandler := handlers.ProvideGameHandler(sessionService, authGuard)
 	sessionHandler := handlers.ProvideSessionHandler(hub, loggerLogger)
 	webSocketHandler := handlers.ProvideWebSocketHandler(sessionHandler)
-	v := handlers.ProvideAppHandlers(healthHandler, authHandler, userHandler, quizHandler, questionHandler, gameHandler, webSocketHandler)
-	gameEventHandler := events.ProvideGameEventHandler(sessionRepository, questionRepository, hub, loggerLogger)
+	tagHandler := handlers.ProvideTagHandler(tagService)
+	mediaHandler := handlers.ProvideMediaHandler(configConfig, mediaService, authGuard)
+	gameEventHandler := events.ProvideGameEventHandler(sessionRepository, questionRepository, quizVersionRepository, hub, loggerLogger)
+	adminService := services.ProvideAdminService(pool, loggerLogger, userRepository, quizRepository, sessionRepository, tokenService, auditService, hub, gameEventHandler)
+	adminHandler := handlers.ProvideAdminHandler(adminService, authGuard)
+	v := handlers.ProvideAppHandlers(healthHandler, authHandler, userHandler, quizHandler, questionHandler, tagHandler, mediaHandler, gameHandler, webSocketHandler, adminHandler)
 	serviceApp := NewServiceApp(configConfig, loggerLogger, app, databaseConnection, cacheCache, hub, v, gameEventHandler)
 	return serviceApp, nil
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -13,6 +14,12 @@ type Config struct {
 	Redis     RedisConfig
 	CORS      CORSConfig
 	Database  DatabaseConfig
+	Storage   StorageConfig
+	Question  QuestionConfig
+	Quiz      QuizConfig
+	Auth      AuthConfig
+	Mail      MailConfig
+	OIDC      OIDCConfig
 }
 
 type ServerConfig struct {
@@ -24,10 +31,10 @@ type ServerConfig struct {
 }
 
 type JWTConfig struct {
-	AccessTokenSecret      string
 	AccessTokenExpiration  int
-	RefreshTokenSecret     string
 	RefreshTokenExpiration int
+	KeyDir                 string // PEM signing keys named <kid>.pem, and <kid>.pub.pem for retired ones
+	SigningKeyID           string // kid to sign with, defaults to the greatest kid with a private key
 }
 
 type RateLimitConfig struct {
@@ -65,6 +72,64 @@ type DatabaseConfig struct {
 	MaxConnIdleTime int // minutes
 }
 
+type StorageConfig struct {
+	Driver       string // Only "local" for now
+	LocalRoot    string
+	PublicURL    string // Base URL media URLs are built from; defaults to the API's own file route
+	MaxImageSize int64  // bytes
+	MaxAudioSize int64  // bytes
+}
+
+type QuestionConfig struct {
+	MinTimeLimit     int32 // seconds
+	MaxTimeLimit     int32 // seconds
+	DefaultTimeLimit int32 // seconds, used when a question does not set one
+}
+
+type QuizConfig struct {
+	MinPublishQuestions int32
+}
+
+type AuthConfig struct {
+	EmailVerificationTTL int    // seconds
+	PasswordResetTTL     int    // seconds
+	FrontendURL          string // links in emails point here
+
+	MaxFailedAttempts      int // failed sign-ins per email before it is locked
+	MaxFailedAttemptsPerIP int // failed sign-ins per IP before the IP is throttled
+	FailedAttemptWindow    int // seconds failed sign-ins are counted for
+	LockoutDuration        int // seconds
+
+	MFAChallengeTTL   int    // seconds a password sign-in waits for its second factor
+	TOTPIssuer        string // shown by authenticator apps
+	TOTPEncryptionKey string // base64 AES-256 key TOTP secrets are encrypted with at rest
+}
+
+type MailConfig struct {
+	Driver       string // "log" (default) or "smtp"
+	From         string
+	LogDir       string // log driver only, also writes each message here when set
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	StateTTL  int // seconds a login may take between redirect and callback
+}
+
+type OIDCProviderConfig struct {
+	Name         string // used in URLs, e.g. /auth/oidc/google/login
+	DisplayName  string
+	Issuer       string // discovery document is read from <Issuer>/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
 var (
 	config     *Config
 	configOnce sync.Once
@@ -87,6 +152,31 @@ func ProvideConfig() *Config {
 		dbMaxConnLifetime, _ := strconv.Atoi(os.Getenv("DB_MAX_CONN_LIFETIME"))
 		dbMaxConnIdleTime, _ := strconv.Atoi(os.Getenv("DB_MAX_CONN_IDLE_TIME"))
 
+		// Storage config
+		storageMaxImageSize, _ := strconv.ParseInt(os.Getenv("STORAGE_MAX_IMAGE_SIZE"), 10, 64)
+		storageMaxAudioSize, _ := strconv.ParseInt(os.Getenv("STORAGE_MAX_AUDIO_SIZE"), 10, 64)
+
+		// Question config
+		questionMinTimeLimit, _ := strconv.Atoi(os.Getenv("QUESTION_MIN_TIME_LIMIT"))
+		questionMaxTimeLimit, _ := strconv.Atoi(os.Getenv("QUESTION_MAX_TIME_LIMIT"))
+		questionDefaultTimeLimit, _ := strconv.Atoi(os.Getenv("QUESTION_DEFAULT_TIME_LIMIT"))
+
+		// Quiz config
+		quizMinPublishQuestions, _ := strconv.Atoi(os.Getenv("QUIZ_MIN_PUBLISH_QUESTIONS"))
+
+		// Auth and mail config
+		authEmailVerificationTTL, _ := strconv.Atoi(os.Getenv("AUTH_EMAIL_VERIFICATION_TTL"))
+		authPasswordResetTTL, _ := strconv.Atoi(os.Getenv("AUTH_PASSWORD_RESET_TTL"))
+		smtpPort, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		authMaxFailedAttempts, _ := strconv.Atoi(os.Getenv("AUTH_MAX_FAILED_ATTEMPTS"))
+		authMaxFailedAttemptsPerIP, _ := strconv.Atoi(os.Getenv("AUTH_MAX_FAILED_ATTEMPTS_PER_IP"))
+		authFailedAttemptWindow, _ := strconv.Atoi(os.Getenv("AUTH_FAILED_ATTEMPT_WINDOW"))
+		authLockoutDuration, _ := strconv.Atoi(os.Getenv("AUTH_LOCKOUT_DURATION"))
+		authMFAChallengeTTL, _ := strconv.Atoi(os.Getenv("AUTH_MFA_CHALLENGE_TTL"))
+
+		// OIDC config
+		oidcStateTTL, _ := strconv.Atoi(os.Getenv("OIDC_STATE_TTL"))
+
 		config = &Config{
 			Server: ServerConfig{
 				GoEnv:          os.Getenv("GO_ENV"),
@@ -96,10 +186,10 @@ func ProvideConfig() *Config {
 				Port:           os.Getenv("SERVER_PORT"),
 			},
 			JWT: JWTConfig{
-				AccessTokenSecret:      os.Getenv("JWT_ACCESS_TOKEN_SECRET"),
 				AccessTokenExpiration:  accessTokenExp,
-				RefreshTokenSecret:     os.Getenv("JWT_REFRESH_TOKEN_SECRET"),
 				RefreshTokenExpiration: refreshTokenExp,
+				KeyDir:                 os.Getenv("JWT_KEY_DIR"),
+				SigningKeyID:           os.Getenv("JWT_SIGNING_KEY_ID"),
 			},
 			RateLimit: RateLimitConfig{
 				RPS:   rateRPS,
@@ -132,15 +222,246 @@ func ProvideConfig() *Config {
 				MaxConnLifetime: dbMaxConnLifetime,
 				MaxConnIdleTime: dbMaxConnIdleTime,
 			},
+			Storage: StorageConfig{
+				Driver:       os.Getenv("STORAGE_DRIVER"),
+				LocalRoot:    os.Getenv("STORAGE_LOCAL_ROOT"),
+				PublicURL:    os.Getenv("STORAGE_PUBLIC_URL"),
+				MaxImageSize: storageMaxImageSize,
+				MaxAudioSize: storageMaxAudioSize,
+			},
+			Question: QuestionConfig{
+				MinTimeLimit:     int32(questionMinTimeLimit),
+				MaxTimeLimit:     int32(questionMaxTimeLimit),
+				DefaultTimeLimit: int32(questionDefaultTimeLimit),
+			},
+			Quiz: QuizConfig{
+				MinPublishQuestions: int32(quizMinPublishQuestions),
+			},
+			Auth: AuthConfig{
+				EmailVerificationTTL: authEmailVerificationTTL,
+				PasswordResetTTL:     authPasswordResetTTL,
+				FrontendURL:          os.Getenv("AUTH_FRONTEND_URL"),
+
+				MaxFailedAttempts:      authMaxFailedAttempts,
+				MaxFailedAttemptsPerIP: authMaxFailedAttemptsPerIP,
+				FailedAttemptWindow:    authFailedAttemptWindow,
+				LockoutDuration:        authLockoutDuration,
+
+				MFAChallengeTTL:   authMFAChallengeTTL,
+				TOTPIssuer:        os.Getenv("AUTH_TOTP_ISSUER"),
+				TOTPEncryptionKey: os.Getenv("AUTH_TOTP_ENCRYPTION_KEY"),
+			},
+			Mail: MailConfig{
+				Driver:       os.Getenv("MAIL_DRIVER"),
+				From:         os.Getenv("MAIL_FROM"),
+				LogDir:       os.Getenv("MAIL_LOG_DIR"),
+				SMTPHost:     os.Getenv("SMTP_HOST"),
+				SMTPPort:     smtpPort,
+				SMTPUsername: os.Getenv("SMTP_USERNAME"),
+				SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+			},
+			OIDC: OIDCConfig{
+				Providers: loadOIDCProviders(),
+				StateTTL:  oidcStateTTL,
+			},
 		}
 	})
 
 	return config
 }
 
+// loadOIDCProviders reads OIDC_PROVIDERS=google,keycloak and then OIDC_<NAME>_* for each provider
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		env := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			DisplayName:  os.Getenv(env + "DISPLAY_NAME"),
+			Issuer:       os.Getenv(env + "ISSUER"),
+			ClientID:     os.Getenv(env + "CLIENT_ID"),
+			ClientSecret: os.Getenv(env + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(env + "REDIRECT_URL"),
+			Scopes:       strings.Fields(strings.ReplaceAll(os.Getenv(env+"SCOPES"), ",", " ")),
+		})
+	}
+	return providers
+}
+
+// IsDevelopment reports whether GO_ENV names a local environment. Defaults that are only safe on a
+// developer machine check this, so an unset GO_ENV does not count.
+func (c *ServerConfig) IsDevelopment() bool {
+	switch c.GoEnv {
+	case "dev", "development", "local":
+		return true
+	}
+	return false
+}
+
+func (c *JWTConfig) GetKeyDir() string {
+	if c.KeyDir == "" {
+		return "./keys"
+	}
+	return c.KeyDir
+}
+
 func (c *CORSConfig) GetAllowOrigins() string {
 	if c.AllowOrigins == "" || c.AllowOrigins == "*" {
 		return "*"
 	}
 	return c.AllowOrigins
 }
+
+func (c *StorageConfig) GetMaxImageSize() int64 {
+	if c.MaxImageSize <= 0 {
+		return 5 << 20 // 5 MB
+	}
+	return c.MaxImageSize
+}
+
+func (c *StorageConfig) GetMaxAudioSize() int64 {
+	if c.MaxAudioSize <= 0 {
+		return 10 << 20 // 10 MB
+	}
+	return c.MaxAudioSize
+}
+
+func (c *QuestionConfig) GetMinTimeLimit() int32 {
+	if c.MinTimeLimit <= 0 {
+		return 5
+	}
+	return c.MinTimeLimit
+}
+
+func (c *QuestionConfig) GetMaxTimeLimit() int32 {
+	if c.MaxTimeLimit <= 0 {
+		return 300
+	}
+	return c.MaxTimeLimit
+}
+
+func (c *QuestionConfig) GetDefaultTimeLimit() int32 {
+	if c.DefaultTimeLimit <= 0 {
+		return 20
+	}
+	return c.DefaultTimeLimit
+}
+
+func (c *QuizConfig) GetMinPublishQuestions() int32 {
+	if c.MinPublishQuestions <= 0 {
+		return 1
+	}
+	return c.MinPublishQuestions
+}
+
+func (c *AuthConfig) GetEmailVerificationTTL() int {
+	if c.EmailVerificationTTL <= 0 {
+		return 24 * 60 * 60
+	}
+	return c.EmailVerificationTTL
+}
+
+func (c *AuthConfig) GetPasswordResetTTL() int {
+	if c.PasswordResetTTL <= 0 {
+		return 60 * 60
+	}
+	return c.PasswordResetTTL
+}
+
+func (c *AuthConfig) GetFrontendURL() string {
+	if c.FrontendURL == "" {
+		return "http://localhost:3000"
+	}
+	return strings.TrimSuffix(c.FrontendURL, "/")
+}
+
+func (c *AuthConfig) GetMaxFailedAttempts() int {
+	if c.MaxFailedAttempts <= 0 {
+		return 10
+	}
+	return c.MaxFailedAttempts
+}
+
+func (c *AuthConfig) GetMaxFailedAttemptsPerIP() int {
+	if c.MaxFailedAttemptsPerIP <= 0 {
+		return 50
+	}
+	return c.MaxFailedAttemptsPerIP
+}
+
+func (c *AuthConfig) GetFailedAttemptWindow() int {
+	if c.FailedAttemptWindow <= 0 {
+		return 15 * 60
+	}
+	return c.FailedAttemptWindow
+}
+
+func (c *AuthConfig) GetLockoutDuration() int {
+	if c.LockoutDuration <= 0 {
+		return 30 * 60
+	}
+	return c.LockoutDuration
+}
+
+func (c *AuthConfig) GetMFAChallengeTTL() int {
+	if c.MFAChallengeTTL <= 0 {
+		return 5 * 60
+	}
+	return c.MFAChallengeTTL
+}
+
+func (c *AuthConfig) GetTOTPIssuer() string {
+	if c.TOTPIssuer == "" {
+		return "bTaskee Quiz"
+	}
+	return c.TOTPIssuer
+}
+
+func (c *MailConfig) GetFrom() string {
+	if c.From == "" {
+		return "bTaskee Quiz <no-reply@localhost>"
+	}
+	return c.From
+}
+
+func (c *MailConfig) GetSMTPPort() int {
+	if c.SMTPPort <= 0 {
+		return 587
+	}
+	return c.SMTPPort
+}
+
+func (c *OIDCConfig) GetProvider(name string) (*OIDCProviderConfig, bool) {
+	for i := range c.Providers {
+		if c.Providers[i].Name == name {
+			return &c.Providers[i], true
+		}
+	}
+	return nil, false
+}
+
+func (c *OIDCConfig) GetStateTTL() int {
+	if c.StateTTL <= 0 {
+		return 10 * 60
+	}
+	return c.StateTTL
+}
+
+func (c *OIDCProviderConfig) GetDisplayName() string {
+	if c.DisplayName == "" {
+		return c.Name
+	}
+	return c.DisplayName
+}
+
+func (c *OIDCProviderConfig) GetScopes() []string {
+	if len(c.Scopes) == 0 {
+		return []string{"openid", "email", "profile"}
+	}
+	return c.Scopes
+}
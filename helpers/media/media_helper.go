@@ -0,0 +1,81 @@
+package helpers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/utils"
+)
+
+type mediaType struct {
+	kind      models.MediaKind
+	extension string
+}
+
+// Content types accepted for upload, as sniffed from the file itself rather than trusted from the client.
+// SVG is deliberately left out: it can carry scripts and is served from our own origin.
+var mediaTypes = map[string]mediaType{
+	"image/png":  {models.MediaKindImage, "png"},
+	"image/jpeg": {models.MediaKindImage, "jpg"},
+	"image/gif":  {models.MediaKindImage, "gif"},
+	"image/webp": {models.MediaKindImage, "webp"},
+	"audio/mpeg": {models.MediaKindAudio, "mp3"},
+	"audio/wave": {models.MediaKindAudio, "wav"},
+	"audio/ogg":  {models.MediaKindAudio, "ogg"},
+	// http.DetectContentType reports every Ogg container this way
+	"application/ogg": {models.MediaKindAudio, "ogg"},
+}
+
+// DetectMediaType sniffs the content type of an upload. ok is false for types that cannot be attached to questions.
+func DetectMediaType(content []byte) (contentType string, kind models.MediaKind, ok bool) {
+	contentType = utils.DetectContentType(content)
+
+	mediaType, ok := mediaTypes[contentType]
+	if !ok {
+		return contentType, "", false
+	}
+	if contentType == "application/ogg" {
+		contentType = "audio/ogg"
+	}
+
+	return contentType, mediaType.kind, true
+}
+
+// MediaStorageKey builds a unique storage key, grouped by owner and month
+func MediaStorageKey(ownerID int64, contentType string) string {
+	return fmt.Sprintf("media/%d/%s/%s.%s",
+		ownerID,
+		time.Now().UTC().Format("2006-01"),
+		utils.GenerateRandomHex(32),
+		mediaTypes[contentType].extension,
+	)
+}
+
+// QuestionMediaAttachments returns the attachments of a question and its answer options, so they can be filled in place
+func QuestionMediaAttachments(question *models.Question) []*models.MediaAttachment {
+	attachments := make([]*models.MediaAttachment, 0)
+	if question.Media != nil {
+		attachments = append(attachments, question.Media)
+	}
+	for i := range question.Answers {
+		if question.Answers[i].Media != nil {
+			attachments = append(attachments, question.Answers[i].Media)
+		}
+	}
+
+	return attachments
+}
+
+// StripQuestionMedia removes the attachments of a question and its answer options. The answers are
+// copied first, so a question sharing them with another is left untouched.
+func StripQuestionMedia(question *models.Question) {
+	question.Media = nil
+
+	answers := make([]models.AnswerData, len(question.Answers))
+	copy(answers, question.Answers)
+	for i := range answers {
+		answers[i].Media = nil
+	}
+	question.Answers = answers
+}
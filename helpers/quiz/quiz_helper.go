@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/samber/lo"
 )
 
 type QuizCursorData struct {
@@ -16,6 +17,7 @@ type QuizCursorData struct {
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 	ViewCount *int32     `json:"view_count,omitempty"`
 	PlayCount *int32     `json:"play_count,omitempty"`
+	Relevance *float32   `json:"relevance,omitempty"`
 	SortBy    string     `json:"sort_by"`
 }
 
@@ -38,6 +40,9 @@ func EncodeQuizCursor(quiz *models.Quiz, sortBy string) (string, error) {
 		cursor.ViewCount = &quiz.ViewCount
 	case "play_count":
 		cursor.PlayCount = &quiz.PlayCount
+	case "relevance":
+		relevance := lo.FromPtr(quiz.Relevance)
+		cursor.Relevance = &relevance
 	default:
 		// Default to time-based cursor
 		cursor.CreatedAt = &quiz.CreatedAt
@@ -87,6 +92,7 @@ func ValidateQuizSortBy(sortBy *string) string {
 		"time_oldest": true,
 		"view_count":  true,
 		"play_count":  true,
+		"relevance":   true,
 	}
 
 	if validSorts[*sortBy] {
@@ -0,0 +1,151 @@
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/samber/lo"
+)
+
+// Quiz lint issue codes
+const (
+	LintTooFewQuestions        = "too_few_questions"
+	LintTooFewOptions          = "too_few_options"
+	LintEmptyOption            = "empty_option"
+	LintDuplicateOption        = "duplicate_option"
+	LintNoCorrectAnswer        = "no_correct_answer"
+	LintMultipleCorrectAnswers = "multiple_correct_answers"
+	LintAllOptionsCorrect      = "all_options_correct"
+	LintNoScoredQuestions      = "no_scored_questions"
+	LintMissingDescription     = "missing_description"
+)
+
+// LintQuiz checks that a quiz, loaded with its questions, can be played as published.
+// Errors block publishing, warnings are worth a look but do not.
+func LintQuiz(quiz *models.Quiz, minQuestions int32) *models.QuizLintReport {
+	issues := []models.QuizLintIssue{}
+
+	if int32(len(quiz.Questions)) < minQuestions {
+		issues = append(issues, models.QuizLintIssue{
+			Severity: models.QuizLintSeverityError,
+			Code:     LintTooFewQuestions,
+			Message:  fmt.Sprintf("A published quiz needs at least %d questions, this one has %d", minQuestions, len(quiz.Questions)),
+		})
+	}
+
+	if strings.TrimSpace(lo.FromPtr(quiz.Description)) == "" {
+		issues = append(issues, models.QuizLintIssue{
+			Severity: models.QuizLintSeverityWarning,
+			Code:     LintMissingDescription,
+			Message:  "The quiz has no description",
+		})
+	}
+
+	for i := range quiz.Questions {
+		issues = append(issues, lintQuestion(&quiz.Questions[i])...)
+	}
+
+	scored := lo.ContainsBy(quiz.Questions, func(question models.Question) bool {
+		return question.PointsMultiplier > 0
+	})
+	if len(quiz.Questions) > 0 && !scored {
+		issues = append(issues, models.QuizLintIssue{
+			Severity: models.QuizLintSeverityWarning,
+			Code:     LintNoScoredQuestions,
+			Message:  "Every question has a points multiplier of 0, so nobody can score",
+		})
+	}
+
+	report := &models.QuizLintReport{Issues: issues}
+	for _, issue := range issues {
+		if issue.Severity == models.QuizLintSeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	report.Publishable = report.Errors == 0
+
+	return report
+}
+
+func lintQuestion(question *models.Question) []models.QuizLintIssue {
+	var issues []models.QuizLintIssue
+	addIssue := func(severity models.QuizLintSeverity, code, message string, answerIndexes []int) {
+		issues = append(issues, models.QuizLintIssue{
+			Severity:      severity,
+			Code:          code,
+			Message:       fmt.Sprintf("Question %d: %s", question.Index, message),
+			QuestionID:    &question.ID,
+			QuestionIndex: &question.Index,
+			AnswerIndexes: answerIndexes,
+		})
+	}
+
+	correctCount := lo.CountBy(question.Answers, func(answer models.AnswerData) bool {
+		return answer.IsCorrect
+	})
+
+	if correctCount == 0 {
+		if question.Type == models.QuestionTypeTextInput {
+			addIssue(models.QuizLintSeverityError, LintNoCorrectAnswer, "has no accepted answer", nil)
+		} else {
+			addIssue(models.QuizLintSeverityError, LintNoCorrectAnswer, "has no correct option", nil)
+		}
+	}
+
+	// Text input answers are accepted spellings rather than options players pick from
+	if question.Type == models.QuestionTypeTextInput {
+		return issues
+	}
+
+	if len(question.Answers) < 2 {
+		addIssue(models.QuizLintSeverityError, LintTooFewOptions, "needs at least 2 options", nil)
+	}
+
+	switch {
+	case question.Type == models.QuestionTypeSingleChoice && correctCount > 1:
+		addIssue(models.QuizLintSeverityError, LintMultipleCorrectAnswers,
+			fmt.Sprintf("is single choice but has %d correct options", correctCount), nil)
+	case question.Type == models.QuestionTypeMultipleChoice && len(question.Answers) > 1 && correctCount == len(question.Answers):
+		addIssue(models.QuizLintSeverityWarning, LintAllOptionsCorrect, "has every option marked correct", nil)
+	}
+
+	optionIndexes := make(map[string][]int, len(question.Answers))
+	var optionKeys []string
+	for i, answer := range question.Answers {
+		key := optionKey(answer)
+		if key == "" {
+			addIssue(models.QuizLintSeverityError, LintEmptyOption, fmt.Sprintf("option %d has no text or media", i+1), []int{i})
+			continue
+		}
+		if _, seen := optionIndexes[key]; !seen {
+			optionKeys = append(optionKeys, key)
+		}
+		optionIndexes[key] = append(optionIndexes[key], i)
+	}
+
+	for _, key := range optionKeys {
+		if indexes := optionIndexes[key]; len(indexes) > 1 {
+			positions := lo.Map(indexes, func(index int, _ int) string { return strconv.Itoa(index + 1) })
+			addIssue(models.QuizLintSeverityError, LintDuplicateOption,
+				fmt.Sprintf("options %s are the same", strings.Join(positions, ", ")), indexes)
+		}
+	}
+
+	return issues
+}
+
+// optionKey identifies what players see for an option: its text ignoring case and spacing, and its media
+func optionKey(answer models.AnswerData) string {
+	text := strings.ToLower(strings.Join(strings.Fields(answer.Text), " "))
+	if text == "" && answer.Media == nil {
+		return ""
+	}
+	if answer.Media == nil {
+		return text
+	}
+	return text + "\x00" + strconv.FormatInt(answer.Media.ID, 10)
+}
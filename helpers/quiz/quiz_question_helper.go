@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"fmt"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
+	"github.com/samber/lo"
+)
+
+// ResolveTimeLimit applies the configured default to an unset time limit and checks it against the configured bounds
+func ResolveTimeLimit(timeLimit int32, cfg *config.QuestionConfig) (int32, error) {
+	if timeLimit == 0 {
+		return cfg.GetDefaultTimeLimit(), nil
+	}
+
+	if timeLimit < cfg.GetMinTimeLimit() || timeLimit > cfg.GetMaxTimeLimit() {
+		return 0, fmt.Errorf("time limit must be between %d and %d seconds", cfg.GetMinTimeLimit(), cfg.GetMaxTimeLimit())
+	}
+
+	return timeLimit, nil
+}
+
+func ResolvePointsMultiplier(multiplier *int32) int32 {
+	return lo.FromPtrOr(multiplier, constants.DefaultPointsMultiplier)
+}
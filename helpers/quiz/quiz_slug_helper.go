@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/utils"
+)
+
+const (
+	MaxQuizSlugLength = 100
+
+	// Room left at the end of a generated slug for a collision suffix
+	quizSlugSuffixLength = 8
+
+	// Attempts that use a sequential "-2", "-3"... suffix before falling back to a random one
+	quizSlugSequentialAttempts = 10
+)
+
+var quizSlugPattern = regexp.MustCompile(`[a-z0-9]`)
+
+// QuizSlugBase turns a quiz title into the slug every generated candidate starts from
+func QuizSlugBase(title string) string {
+	if !quizSlugPattern.MatchString(strings.ToLower(title)) {
+		return "quiz"
+	}
+
+	slug := utils.TextToSlug(title)
+	if len(slug) > MaxQuizSlugLength-quizSlugSuffixLength {
+		slug = strings.TrimRight(slug[:MaxQuizSlugLength-quizSlugSuffixLength], "-")
+	}
+
+	return slug
+}
+
+// QuizSlugCandidate returns the slug to try on the given attempt: the base itself,
+// then base-2, base-3... and finally a random suffix once the sequential ones are exhausted
+func QuizSlugCandidate(base string, attempt int) string {
+	switch {
+	case attempt == 0:
+		return base
+	case attempt < quizSlugSequentialAttempts:
+		return fmt.Sprintf("%s-%d", base, attempt+1)
+	default:
+		return fmt.Sprintf("%s-%s", base, utils.GenerateRandomHex(6))
+	}
+}
+
+// NormalizeQuizSlug lower-cases a custom slug chosen by the owner
+func NormalizeQuizSlug(slug string) string {
+	return strings.Trim(strings.ToLower(slug), "-")
+}
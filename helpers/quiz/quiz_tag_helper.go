@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"strings"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/utils"
+)
+
+const (
+	MaxQuizTags      = 10
+	MaxTagSlugLength = 60
+)
+
+// NewQuizTags turns the tag names an owner typed into tags keyed by slug.
+// Names that slugify to the same value are kept once; ok is false if a name has nothing to slugify.
+func NewQuizTags(names []string) (tags []models.Tag, ok bool) {
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !quizSlugPattern.MatchString(strings.ToLower(name)) {
+			return nil, false
+		}
+
+		slug := utils.TextToSlug(name)
+		if len(slug) > MaxTagSlugLength {
+			slug = strings.TrimRight(slug[:MaxTagSlugLength], "-")
+		}
+
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		tags = append(tags, models.Tag{Name: name, Slug: slug})
+	}
+
+	return tags, true
+}
+
+// NormalizeTagSlug lower-cases a tag slug taken from a filter or path
+func NormalizeTagSlug(slug string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(slug)), "-")
+}
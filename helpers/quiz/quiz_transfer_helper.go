@@ -0,0 +1,425 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+)
+
+const (
+	QuizFormatJSON      = "json"
+	QuizFormatCSV       = "csv"
+	QuizFormatGIFT      = "gift"
+	QuizFormatMoodleXML = "moodle_xml"
+	QuizFormatAiken     = "aiken"
+)
+
+// QuizTransferVersion is bumped whenever the lossless JSON layout changes
+const QuizTransferVersion = 2
+
+type QuizTransferData struct {
+	Version         int                    `json:"version"`
+	Title           string                 `json:"title"`
+	Description     *string                `json:"description,omitempty"`
+	MaxParticipants *int32                 `json:"max_participants,omitempty"`
+	Questions       []QuizTransferQuestion `json:"questions"`
+}
+
+// TransferTimeLimit reads time limits in seconds, written as strings by version 1 exports
+type TransferTimeLimit int32
+
+func (t *TransferTimeLimit) UnmarshalJSON(data []byte) error {
+	var seconds int32
+	if err := json.Unmarshal(bytes.Trim(data, `"`), &seconds); err != nil {
+		return fmt.Errorf("invalid time_limit %s", data)
+	}
+
+	*t = TransferTimeLimit(seconds)
+	return nil
+}
+
+type QuizTransferQuestion struct {
+	Row              int                     `json:"-"` // Position in the source file, used for error reporting
+	Question         string                  `json:"question"`
+	ContentFormat    models.ContentFormat    `json:"content_format,omitempty"` // Only the JSON format carries it, other formats import as plain text
+	Type             models.QuestionType     `json:"type"`
+	Answers          []models.AnswerData     `json:"answers"`
+	TimeLimit        TransferTimeLimit       `json:"time_limit,omitempty"` // Seconds, 0 when the source format has none
+	PointsMultiplier *int32                  `json:"points_multiplier,omitempty"`
+	Media            *models.MediaAttachment `json:"media,omitempty"` // Exported for reference, dropped on import
+	Explanation      *string                 `json:"explanation,omitempty"`
+	ReferenceURL     *string                 `json:"reference_url,omitempty"`
+}
+
+func NewQuizTransferData(quiz *models.Quiz) *QuizTransferData {
+	data := &QuizTransferData{
+		Version:         QuizTransferVersion,
+		Title:           quiz.Title,
+		Description:     quiz.Description,
+		MaxParticipants: quiz.MaxParticipants,
+		Questions:       make([]QuizTransferQuestion, len(quiz.Questions)),
+	}
+
+	for i, question := range quiz.Questions {
+		data.Questions[i] = QuizTransferQuestion{
+			Row:              i + 1,
+			Question:         question.Question,
+			ContentFormat:    question.ContentFormat,
+			Type:             question.Type,
+			Answers:          transformers.StripAnswersHTML(question.Answers),
+			TimeLimit:        TransferTimeLimit(question.TimeLimit),
+			PointsMultiplier: &question.PointsMultiplier,
+			Media:            question.Media,
+			Explanation:      question.Explanation,
+			ReferenceURL:     question.ReferenceURL,
+		}
+	}
+
+	return data
+}
+
+func QuizFormatContentType(format string) string {
+	switch format {
+	case QuizFormatJSON:
+		return "application/json"
+	case QuizFormatCSV:
+		return "text/csv"
+	case QuizFormatMoodleXML:
+		return "application/xml"
+	default:
+		return "text/plain"
+	}
+}
+
+func QuizFormatFileExtension(format string) string {
+	switch format {
+	case QuizFormatMoodleXML:
+		return "xml"
+	case QuizFormatGIFT, QuizFormatAiken:
+		return "txt"
+	default:
+		return format
+	}
+}
+
+func EncodeQuizTransfer(format string, data *QuizTransferData) ([]byte, error) {
+	switch format {
+	case QuizFormatJSON:
+		return json.MarshalIndent(data, "", "  ")
+	case QuizFormatCSV:
+		return encodeQuizCSV(data)
+	case QuizFormatGIFT:
+		return encodeQuizGIFT(data)
+	case QuizFormatMoodleXML:
+		return encodeQuizMoodleXML(data)
+	case QuizFormatAiken:
+		return encodeQuizAiken(data)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func DecodeQuizTransfer(format string, content []byte) (*QuizTransferData, error) {
+	var (
+		data *QuizTransferData
+		err  error
+	)
+
+	switch format {
+	case QuizFormatJSON:
+		data, err = decodeQuizJSON(content)
+	case QuizFormatCSV:
+		data, err = decodeQuizCSV(content)
+	case QuizFormatGIFT:
+		data, err = decodeQuizGIFT(content)
+	case QuizFormatMoodleXML:
+		data, err = decodeQuizMoodleXML(content)
+	case QuizFormatAiken:
+		data, err = decodeQuizAiken(content)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data.Questions) == 0 {
+		return nil, fmt.Errorf("no questions found")
+	}
+
+	return data, nil
+}
+
+// parseTransferTimeLimit reads a time limit in seconds from text formats. Blank means the default applies.
+func parseTransferTimeLimit(value string) (TransferTimeLimit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time_limit %q", value)
+	}
+	return TransferTimeLimit(seconds), nil
+}
+
+// ===== JSON =====
+
+func decodeQuizJSON(content []byte) (*QuizTransferData, error) {
+	var data QuizTransferData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if data.Version > QuizTransferVersion {
+		return nil, fmt.Errorf("unsupported export version %d", data.Version)
+	}
+
+	for i := range data.Questions {
+		data.Questions[i].Row = i + 1
+	}
+
+	return &data, nil
+}
+
+// ===== CSV =====
+//
+// Layout: question,type,time_limit,correct,answer_1,answer_2,...
+// "correct" holds the 1-based positions of the correct answers separated by "|".
+// It may be left empty for text_input rows, in which case every answer is accepted.
+
+var quizCSVHeader = []string{"question", "type", "time_limit", "correct"}
+
+func encodeQuizCSV(data *QuizTransferData) ([]byte, error) {
+	maxAnswers := 0
+	for _, question := range data.Questions {
+		maxAnswers = max(maxAnswers, len(question.Answers))
+	}
+
+	header := append([]string{}, quizCSVHeader...)
+	for i := 1; i <= maxAnswers; i++ {
+		header = append(header, fmt.Sprintf("answer_%d", i))
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, question := range data.Questions {
+		correct := make([]string, 0, len(question.Answers))
+		record := []string{question.Question, string(question.Type), strconv.Itoa(int(question.TimeLimit)), ""}
+		for i, answer := range question.Answers {
+			if answer.IsCorrect {
+				correct = append(correct, strconv.Itoa(i+1))
+			}
+			record = append(record, answer.Text)
+		}
+		record[3] = strings.Join(correct, "|")
+
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeQuizCSV(content []byte) (*QuizTransferData, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	data := &QuizTransferData{Version: QuizTransferVersion}
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		if row == 1 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), quizCSVHeader[0]) {
+			continue
+		}
+
+		if len(record) < len(quizCSVHeader) {
+			return nil, fmt.Errorf("row %d: expected at least %d columns, got %d", row, len(quizCSVHeader), len(record))
+		}
+
+		timeLimit, err := parseTransferTimeLimit(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		question := QuizTransferQuestion{
+			Row:       row,
+			Question:  strings.TrimSpace(record[0]),
+			Type:      models.QuestionType(strings.TrimSpace(record[1])),
+			TimeLimit: timeLimit,
+			Answers:   []models.AnswerData{},
+		}
+
+		for _, text := range record[len(quizCSVHeader):] {
+			if text = strings.TrimSpace(text); text != "" {
+				question.Answers = append(question.Answers, models.AnswerData{Text: text})
+			}
+		}
+
+		correct := strings.TrimSpace(record[3])
+		if correct == "" && question.Type == models.QuestionTypeTextInput {
+			for i := range question.Answers {
+				question.Answers[i].IsCorrect = true
+			}
+		} else if correct != "" {
+			for _, position := range strings.Split(correct, "|") {
+				index, err := strconv.Atoi(strings.TrimSpace(position))
+				if err != nil || index < 1 || index > len(question.Answers) {
+					return nil, fmt.Errorf("row %d: invalid correct answer position %q", row, position)
+				}
+				question.Answers[index-1].IsCorrect = true
+			}
+		}
+
+		data.Questions = append(data.Questions, question)
+	}
+
+	return data, nil
+}
+
+// ===== Aiken =====
+//
+// Aiken only describes single choice questions:
+//
+//	What is 2 + 2?
+//	A. 3
+//	B. 4
+//	ANSWER: B
+
+var (
+	aikenOptionPattern = regexp.MustCompile(`^([A-Z])[.)]\s+(.*)$`)
+	aikenAnswerPattern = regexp.MustCompile(`^ANSWER:\s*([A-Z])\s*$`)
+)
+
+// encodeQuizAiken refuses the whole quiz when any question cannot be written, listing every one of
+// them, rather than exporting an ANSWER line the importer would reject
+func encodeQuizAiken(data *QuizTransferData) ([]byte, error) {
+	var (
+		buf      bytes.Buffer
+		problems []string
+	)
+
+	for i, question := range data.Questions {
+		if question.Type != models.QuestionTypeSingleChoice {
+			problems = append(problems, fmt.Sprintf("question %d: only single choice questions are supported", i+1))
+			continue
+		}
+		if len(question.Answers) > 26 {
+			problems = append(problems, fmt.Sprintf("question %d: at most 26 options are supported", i+1))
+			continue
+		}
+
+		var correct []string
+		for j, answer := range question.Answers {
+			if answer.IsCorrect {
+				correct = append(correct, string(rune('A'+j)))
+			}
+		}
+		if len(correct) != 1 {
+			problems = append(problems, fmt.Sprintf("question %d: needs exactly one correct option, has %d", i+1, len(correct)))
+			continue
+		}
+
+		buf.WriteString(flattenLine(question.Question) + "\n")
+		for j, answer := range question.Answers {
+			buf.WriteString(fmt.Sprintf("%s. %s\n", string(rune('A'+j)), flattenLine(answer.Text)))
+		}
+		buf.WriteString("ANSWER: " + correct[0] + "\n\n")
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("aiken cannot represent %d question(s): %s", len(problems), strings.Join(problems, "; "))
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeQuizAiken(content []byte) (*QuizTransferData, error) {
+	data := &QuizTransferData{Version: QuizTransferVersion}
+
+	var current *QuizTransferQuestion
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if current == nil {
+			current = &QuizTransferQuestion{
+				Row:      line,
+				Question: text,
+				Type:     models.QuestionTypeSingleChoice,
+				Answers:  []models.AnswerData{},
+			}
+			continue
+		}
+
+		if match := aikenAnswerPattern.FindStringSubmatch(text); match != nil {
+			index := int(match[1][0] - 'A')
+			if index >= len(current.Answers) {
+				return nil, fmt.Errorf("line %d: answer %s does not match any option", line, match[1])
+			}
+			current.Answers[index].IsCorrect = true
+			data.Questions = append(data.Questions, *current)
+			current = nil
+			continue
+		}
+
+		if match := aikenOptionPattern.FindStringSubmatch(text); match != nil {
+			if int(match[1][0]-'A') != len(current.Answers) {
+				return nil, fmt.Errorf("line %d: option %s is out of order", line, match[1])
+			}
+			current.Answers = append(current.Answers, models.AnswerData{Text: strings.TrimSpace(match[2])})
+			continue
+		}
+
+		if len(current.Answers) > 0 {
+			return nil, fmt.Errorf("line %d: expected an option or ANSWER line", line)
+		}
+		current.Question += " " + text
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("line %d: question is missing its ANSWER line", current.Row)
+	}
+
+	return data, nil
+}
+
+func flattenLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,359 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+func sampleTransferData() *QuizTransferData {
+	description := "Arithmetic, primes and capitals"
+	return &QuizTransferData{
+		Version:     QuizTransferVersion,
+		Title:       "Mixed bag",
+		Description: &description,
+		Questions: []QuizTransferQuestion{
+			{
+				Row:       1,
+				Question:  `What is 2 + 2? {hint} a=b: #1 ~ "quoted", with commas`,
+				Type:      models.QuestionTypeSingleChoice,
+				TimeLimit: 20,
+				Answers: []models.AnswerData{
+					{Text: "3"},
+					{Text: "4", IsCorrect: true},
+					{Text: "5 = five"},
+				},
+			},
+			{
+				Row:       2,
+				Question:  "Pick the primes",
+				Type:      models.QuestionTypeMultipleChoice,
+				TimeLimit: 30,
+				Answers: []models.AnswerData{
+					{Text: "2", IsCorrect: true},
+					{Text: "3", IsCorrect: true},
+					{Text: "4"},
+				},
+			},
+			{
+				Row:       3,
+				Question:  "Capital of France?",
+				Type:      models.QuestionTypeTextInput,
+				TimeLimit: 45,
+				Answers: []models.AnswerData{
+					{Text: "Paris", IsCorrect: true},
+				},
+			},
+		},
+	}
+}
+
+func assertSameQuestions(t *testing.T, want, got []QuizTransferQuestion, withTimeLimit bool) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d questions, want %d", len(got), len(want))
+	}
+	for i := range want {
+		w, g := want[i], got[i]
+		if g.Question != w.Question {
+			t.Errorf("question %d: text = %q, want %q", i+1, g.Question, w.Question)
+		}
+		if g.Type != w.Type {
+			t.Errorf("question %d: type = %s, want %s", i+1, g.Type, w.Type)
+		}
+		if withTimeLimit && g.TimeLimit != w.TimeLimit {
+			t.Errorf("question %d: time limit = %d, want %d", i+1, g.TimeLimit, w.TimeLimit)
+		}
+		if len(g.Answers) != len(w.Answers) {
+			t.Errorf("question %d: got %d answers, want %d", i+1, len(g.Answers), len(w.Answers))
+			continue
+		}
+		for j := range w.Answers {
+			if g.Answers[j].Text != w.Answers[j].Text || g.Answers[j].IsCorrect != w.Answers[j].IsCorrect {
+				t.Errorf("question %d answer %d: got %+v, want %+v", i+1, j+1, g.Answers[j], w.Answers[j])
+			}
+		}
+	}
+}
+
+func TestQuizTransferRoundTrip(t *testing.T) {
+	tests := []struct {
+		format        string
+		withTimeLimit bool
+		singleOnly    bool // Aiken only describes single choice questions
+	}{
+		{QuizFormatJSON, true, false},
+		{QuizFormatCSV, true, false},
+		{QuizFormatGIFT, true, false},
+		{QuizFormatMoodleXML, false, false},
+		{QuizFormatAiken, false, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			data := sampleTransferData()
+			if tc.singleOnly {
+				data.Questions = data.Questions[:1]
+			}
+
+			content, err := EncodeQuizTransfer(tc.format, data)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+
+			decoded, err := DecodeQuizTransfer(tc.format, content)
+			if err != nil {
+				t.Fatalf("decode: %v\n%s", err, content)
+			}
+
+			assertSameQuestions(t, data.Questions, decoded.Questions, tc.withTimeLimit)
+		})
+	}
+}
+
+func TestQuizTransferJSONKeepsQuizFields(t *testing.T) {
+	data := sampleTransferData()
+
+	content, err := EncodeQuizTransfer(QuizFormatJSON, data)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	decoded, err := DecodeQuizTransfer(QuizFormatJSON, content)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if decoded.Title != data.Title || decoded.Description == nil || *decoded.Description != *data.Description {
+		t.Errorf("quiz fields not kept: %+v", decoded)
+	}
+	for i, question := range decoded.Questions {
+		if question.Row != i+1 {
+			t.Errorf("question %d: row = %d", i+1, question.Row)
+		}
+	}
+}
+
+func TestQuizTransferDecodesVersion1TimeLimits(t *testing.T) {
+	content := `{"version":1,"title":"Old","questions":[{"question":"Q","type":"single_choice","time_limit":"20","answers":[{"text":"a","is_correct":true}]}]}`
+
+	decoded, err := DecodeQuizTransfer(QuizFormatJSON, []byte(content))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.Questions[0].TimeLimit != 20 {
+		t.Errorf("time limit = %d, want 20", decoded.Questions[0].TimeLimit)
+	}
+}
+
+func TestQuizTransferDecodesForeignFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		want    []QuizTransferQuestion
+	}{
+		{
+			name:    "CSV with BOM, header and text input accepting every answer",
+			format:  QuizFormatCSV,
+			content: "\xef\xbb\xbfquestion,type,time_limit,correct,answer_1,answer_2\nColour of the sky?,text_input,,,blue, Blue \n",
+			want: []QuizTransferQuestion{{
+				Question: "Colour of the sky?",
+				Type:     models.QuestionTypeTextInput,
+				Answers:  []models.AnswerData{{Text: "blue", IsCorrect: true}, {Text: "Blue", IsCorrect: true}},
+			}},
+		},
+		{
+			name:    "GIFT true/false with category, comments and feedback",
+			format:  QuizFormatGIFT,
+			content: "$CATEGORY: $course$/Science\n// a comment\n::T1:: The sun is a star {TRUE}\n\nWater boils at {=100 #Correct ~90 #No} degrees\n",
+			want: []QuizTransferQuestion{
+				{
+					Question: "The sun is a star",
+					Type:     models.QuestionTypeSingleChoice,
+					Answers:  []models.AnswerData{{Text: "True", IsCorrect: true}, {Text: "False"}},
+				},
+				{
+					Question: "Water boils at degrees",
+					Type:     models.QuestionTypeSingleChoice,
+					Answers:  []models.AnswerData{{Text: "100", IsCorrect: true}, {Text: "90"}},
+				},
+			},
+		},
+		{
+			name:   "Moodle XML with HTML text, a category and true/false",
+			format: QuizFormatMoodleXML,
+			content: `<?xml version="1.0"?>
+<quiz>
+  <question type="category"><category><text>$course$/Top</text></category></question>
+  <question type="truefalse">
+    <questiontext format="html"><text><![CDATA[<p>Is <b>Go</b> &amp; C compiled?</p>]]></text></questiontext>
+    <answer fraction="100"><text>true</text></answer>
+    <answer fraction="0"><text>false</text></answer>
+  </question>
+</quiz>`,
+			want: []QuizTransferQuestion{{
+				Question: "Is Go & C compiled?",
+				Type:     models.QuestionTypeSingleChoice,
+				Answers:  []models.AnswerData{{Text: "True", IsCorrect: true}, {Text: "False"}},
+			}},
+		},
+		{
+			name:    "Aiken with a question over two lines and ) option markers",
+			format:  QuizFormatAiken,
+			content: "Which planet is\nknown as the red planet?\nA) Venus\nB) Mars\nANSWER: B\n",
+			want: []QuizTransferQuestion{{
+				Question: "Which planet is known as the red planet?",
+				Type:     models.QuestionTypeSingleChoice,
+				Answers:  []models.AnswerData{{Text: "Venus"}, {Text: "Mars", IsCorrect: true}},
+			}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := DecodeQuizTransfer(tc.format, []byte(tc.content))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			assertSameQuestions(t, tc.want, decoded.Questions, false)
+		})
+	}
+}
+
+func TestQuizTransferRejectsMalformedInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		wantErr string
+	}{
+		{"unknown format", "docx", "anything", "unsupported format"},
+
+		{"JSON syntax error", QuizFormatJSON, `{"title": "x", "questions": [`, "invalid JSON"},
+		{"JSON from a newer version", QuizFormatJSON, `{"version": 99, "questions": [{"question": "q"}]}`, "unsupported export version"},
+		{"JSON bad time limit", QuizFormatJSON, `{"questions": [{"question": "q", "time_limit": "soon"}]}`, "invalid time_limit"},
+		{"JSON without questions", QuizFormatJSON, `{"title": "x", "questions": []}`, "no questions found"},
+
+		{"CSV too few columns", QuizFormatCSV, "q,single_choice\n", "expected at least 4 columns"},
+		{"CSV bad time limit", QuizFormatCSV, "q,single_choice,soon,1,a\n", "invalid time_limit"},
+		{"CSV correct position out of range", QuizFormatCSV, "q,single_choice,20,3,a,b\n", "invalid correct answer position"},
+		{"CSV correct position not a number", QuizFormatCSV, "q,single_choice,20,first,a,b\n", "invalid correct answer position"},
+		{"CSV unterminated quote", QuizFormatCSV, "\"q,single_choice,20,1,a\n", "row 1"},
+		{"CSV header only", QuizFormatCSV, "question,type,time_limit,correct\n", "no questions found"},
+
+		{"GIFT missing answer block", QuizFormatGIFT, "Just a statement\n", "missing answer block"},
+		{"GIFT unterminated answer block", QuizFormatGIFT, "Question {=a ~b\n", "unterminated answer block"},
+		{"GIFT unterminated title", QuizFormatGIFT, "::Title Question {=a}\n", "unterminated question title"},
+		{"GIFT essay", QuizFormatGIFT, "Write about it {}\n", "essay questions are not supported"},
+		{"GIFT numerical", QuizFormatGIFT, "How many? {#5}\n", "numerical questions are not supported"},
+		{"GIFT matching", QuizFormatGIFT, "Match {=a -> 1 =b -> 2}\n", "matching questions are not supported"},
+		{"GIFT bad weight", QuizFormatGIFT, "Pick {~%abc%a ~b}\n", "invalid answer weight"},
+		{"GIFT bad time limit", QuizFormatGIFT, "// time_limit: soon\nQ {=a}\n", "invalid time_limit"},
+		{"GIFT comments only", QuizFormatGIFT, "// nothing here\n", "no questions found"},
+
+		{"Moodle XML syntax error", QuizFormatMoodleXML, "<quiz><question type=\"multichoice\">", "invalid Moodle XML"},
+		{"Moodle XML unsupported type", QuizFormatMoodleXML, `<quiz><question type="essay"><questiontext><text>Why?</text></questiontext></question></quiz>`, "not supported"},
+		{"Moodle XML bad fraction", QuizFormatMoodleXML, `<quiz><question type="multichoice"><answer fraction="most"><text>a</text></answer></question></quiz>`, "invalid answer fraction"},
+		{"Moodle XML categories only", QuizFormatMoodleXML, `<quiz><question type="category"></question></quiz>`, "no questions found"},
+
+		{"Aiken missing ANSWER", QuizFormatAiken, "Q?\nA. a\nB. b\n", "missing its ANSWER line"},
+		{"Aiken answer without option", QuizFormatAiken, "Q?\nA. a\nB. b\nANSWER: C\n", "does not match any option"},
+		{"Aiken options out of order", QuizFormatAiken, "Q?\nA. a\nC. c\nANSWER: A\n", "out of order"},
+		{"Aiken text after options", QuizFormatAiken, "Q?\nA. a\nmore text\nANSWER: A\n", "expected an option or ANSWER line"},
+		{"Aiken empty", QuizFormatAiken, "\n\n", "no questions found"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := DecodeQuizTransfer(tc.format, []byte(tc.content))
+			if err == nil {
+				t.Fatalf("decoded without error: %+v", decoded)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error = %q, want it to mention %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuizTransferAikenRefusesUnrepresentableQuestions(t *testing.T) {
+	manyOptions := make([]models.AnswerData, 27)
+	for i := range manyOptions {
+		manyOptions[i] = models.AnswerData{Text: fmt.Sprint(i), IsCorrect: i == 0}
+	}
+
+	tests := []struct {
+		name     string
+		question QuizTransferQuestion
+		wantErr  string
+	}{
+		{
+			name:     "multiple choice",
+			question: sampleTransferData().Questions[1],
+			wantErr:  "only single choice questions are supported",
+		},
+		{
+			name:     "text input",
+			question: sampleTransferData().Questions[2],
+			wantErr:  "only single choice questions are supported",
+		},
+		{
+			name: "no correct option",
+			question: QuizTransferQuestion{
+				Question: "Q",
+				Type:     models.QuestionTypeSingleChoice,
+				Answers:  []models.AnswerData{{Text: "a"}, {Text: "b"}},
+			},
+			wantErr: "needs exactly one correct option, has 0",
+		},
+		{
+			name: "two correct options",
+			question: QuizTransferQuestion{
+				Question: "Q",
+				Type:     models.QuestionTypeSingleChoice,
+				Answers:  []models.AnswerData{{Text: "a", IsCorrect: true}, {Text: "b", IsCorrect: true}},
+			},
+			wantErr: "needs exactly one correct option, has 2",
+		},
+		{
+			name: "more options than letters",
+			question: QuizTransferQuestion{
+				Question: "Q",
+				Type:     models.QuestionTypeSingleChoice,
+				Answers:  manyOptions,
+			},
+			wantErr: "at most 26 options are supported",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data := &QuizTransferData{Questions: []QuizTransferQuestion{sampleTransferData().Questions[0], tc.question}}
+
+			content, err := EncodeQuizTransfer(QuizFormatAiken, data)
+			if err == nil {
+				t.Fatalf("encoded without error:\n%s", content)
+			}
+			if !strings.Contains(err.Error(), "question 2: "+tc.wantErr) {
+				t.Errorf("error = %q, want it to mention question 2: %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuizTransferAikenListsEveryRefusedQuestion(t *testing.T) {
+	data := sampleTransferData()
+
+	_, err := EncodeQuizTransfer(QuizFormatAiken, data)
+	if err == nil {
+		t.Fatal("encoded without error")
+	}
+	for _, want := range []string{"2 question(s)", "question 2:", "question 3:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to mention %q", err, want)
+		}
+	}
+}
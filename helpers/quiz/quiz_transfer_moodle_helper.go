@@ -0,0 +1,450 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+// ===== GIFT =====
+//
+// Time limits are not part of GIFT, so they are carried in a "// time_limit: N" comment
+// placed before each question. Moodle ignores comments, which keeps the file importable there.
+
+const giftTimeLimitDirective = "// time_limit:"
+
+var giftEscaper = strings.NewReplacer(
+	`\`, `\\`, `~`, `\~`, `=`, `\=`, `#`, `\#`, `{`, `\{`, `}`, `\}`, `:`, `\:`, "\n", `\n`,
+)
+
+func encodeQuizGIFT(data *QuizTransferData) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for i, question := range data.Questions {
+		buf.WriteString(fmt.Sprintf("%s %d\n", giftTimeLimitDirective, question.TimeLimit))
+		buf.WriteString(fmt.Sprintf("::Q%d:: %s {\n", i+1, giftEscaper.Replace(question.Question)))
+
+		switch question.Type {
+		case models.QuestionTypeMultipleChoice:
+			correctCount := 0
+			for _, answer := range question.Answers {
+				if answer.IsCorrect {
+					correctCount++
+				}
+			}
+			weight := strconv.FormatFloat(math.Floor(100/float64(max(correctCount, 1))*100000)/100000, 'f', -1, 64)
+
+			for _, answer := range question.Answers {
+				if answer.IsCorrect {
+					buf.WriteString(fmt.Sprintf("\t~%%%s%%%s\n", weight, giftEscaper.Replace(answer.Text)))
+				} else {
+					buf.WriteString(fmt.Sprintf("\t~%%-100%%%s\n", giftEscaper.Replace(answer.Text)))
+				}
+			}
+		case models.QuestionTypeTextInput:
+			for _, answer := range question.Answers {
+				buf.WriteString("\t=" + giftEscaper.Replace(answer.Text) + "\n")
+			}
+		default:
+			for _, answer := range question.Answers {
+				marker := "~"
+				if answer.IsCorrect {
+					marker = "="
+				}
+				buf.WriteString("\t" + marker + giftEscaper.Replace(answer.Text) + "\n")
+			}
+		}
+
+		buf.WriteString("}\n\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeQuizGIFT(content []byte) (*QuizTransferData, error) {
+	data := &QuizTransferData{Version: QuizTransferVersion}
+
+	var (
+		block     []string
+		blockRow  int
+		timeLimit TransferTimeLimit
+	)
+
+	flush := func() error {
+		if len(block) == 0 {
+			return nil
+		}
+		question, err := parseGIFTQuestion(strings.Join(block, "\n"))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", blockRow, err)
+		}
+		question.Row = blockRow
+		question.TimeLimit = timeLimit
+		data.Questions = append(data.Questions, *question)
+
+		block = nil
+		timeLimit = 0
+		return nil
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, giftTimeLimitDirective):
+			seconds, err := parseTransferTimeLimit(strings.TrimPrefix(trimmed, giftTimeLimitDirective))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			timeLimit = seconds
+		case strings.HasPrefix(trimmed, "//"), strings.HasPrefix(trimmed, "$CATEGORY:"):
+			continue
+		case trimmed == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			if len(block) == 0 {
+				blockRow = i + 1
+			}
+			block = append(block, line)
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func parseGIFTQuestion(text string) (*QuizTransferQuestion, error) {
+	text = strings.TrimSpace(text)
+
+	// Drop the optional ::title::
+	if strings.HasPrefix(text, "::") {
+		end := indexUnescaped(text[2:], "::")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated question title")
+		}
+		text = text[end+4:]
+	}
+
+	open := indexUnescaped(text, "{")
+	if open < 0 {
+		return nil, fmt.Errorf("missing answer block")
+	}
+	closing := indexUnescaped(text[open:], "}")
+	if closing < 0 {
+		return nil, fmt.Errorf("unterminated answer block")
+	}
+	closing += open
+
+	stem := strings.TrimSpace(text[:open] + " " + text[closing+1:])
+	stem = strings.TrimPrefix(stem, "[plain]")
+	body := strings.TrimSpace(text[open+1 : closing])
+
+	question := &QuizTransferQuestion{
+		Question: flattenLine(unescapeGIFT(stem)),
+		Answers:  []models.AnswerData{},
+	}
+
+	switch strings.ToUpper(body) {
+	case "":
+		return nil, fmt.Errorf("essay questions are not supported")
+	case "T", "TRUE", "F", "FALSE":
+		isTrue := strings.HasPrefix(strings.ToUpper(body), "T")
+		question.Type = models.QuestionTypeSingleChoice
+		question.Answers = []models.AnswerData{
+			{Text: "True", IsCorrect: isTrue},
+			{Text: "False", IsCorrect: !isTrue},
+		}
+		return question, nil
+	}
+
+	if strings.HasPrefix(body, "#") {
+		return nil, fmt.Errorf("numerical questions are not supported")
+	}
+
+	var (
+		equalsCount   int
+		tildeCount    int
+		weightedCount int
+	)
+
+	for _, token := range splitGIFTAnswers(body) {
+		marker, answerText := token[0], strings.TrimSpace(token[1:])
+		if feedback := indexUnescaped(answerText, "#"); feedback >= 0 {
+			answerText = strings.TrimSpace(answerText[:feedback])
+		}
+		if strings.Contains(answerText, "->") {
+			return nil, fmt.Errorf("matching questions are not supported")
+		}
+
+		isCorrect := marker == '='
+		if strings.HasPrefix(answerText, "%") {
+			end := strings.Index(answerText[1:], "%")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid answer weight")
+			}
+			weight, err := strconv.ParseFloat(answerText[1:end+1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid answer weight %q", answerText[1:end+1])
+			}
+			isCorrect = weight > 0
+			if isCorrect {
+				weightedCount++
+			}
+			answerText = strings.TrimSpace(answerText[end+2:])
+		}
+
+		if marker == '=' {
+			equalsCount++
+		} else {
+			tildeCount++
+		}
+
+		question.Answers = append(question.Answers, models.AnswerData{
+			Text:      unescapeGIFT(answerText),
+			IsCorrect: isCorrect,
+		})
+	}
+
+	switch {
+	case tildeCount == 0:
+		question.Type = models.QuestionTypeTextInput
+	case equalsCount+weightedCount > 1:
+		question.Type = models.QuestionTypeMultipleChoice
+	default:
+		question.Type = models.QuestionTypeSingleChoice
+	}
+
+	return question, nil
+}
+
+func splitGIFTAnswers(body string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+	)
+
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		if ch == '\\' && i+1 < len(body) {
+			current.WriteByte(ch)
+			current.WriteByte(body[i+1])
+			i++
+			continue
+		}
+		if ch == '=' || ch == '~' {
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		}
+		if current.Len() == 0 && ch != '=' && ch != '~' && (ch == ' ' || ch == '\t' || ch == '\n') {
+			continue
+		}
+		current.WriteByte(ch)
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	// Text before the first marker is not an answer
+	if len(tokens) > 0 && tokens[0][0] != '=' && tokens[0][0] != '~' {
+		tokens = tokens[1:]
+	}
+
+	return tokens
+}
+
+func indexUnescaped(text, sep string) int {
+	for i := 0; i+len(sep) <= len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if text[i:i+len(sep)] == sep {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeGIFT(text string) string {
+	var out strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			i++
+			if text[i] == 'n' {
+				out.WriteByte('\n')
+			} else {
+				out.WriteByte(text[i])
+			}
+			continue
+		}
+		out.WriteByte(text[i])
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// ===== Moodle XML =====
+
+type (
+	moodleQuiz struct {
+		XMLName   xml.Name         `xml:"quiz"`
+		Questions []moodleQuestion `xml:"question"`
+	}
+
+	moodleText struct {
+		Format string `xml:"format,attr,omitempty"`
+		Text   string `xml:"text"`
+	}
+
+	moodleAnswer struct {
+		Fraction string `xml:"fraction,attr"`
+		Format   string `xml:"format,attr,omitempty"`
+		Text     string `xml:"text"`
+	}
+
+	moodleQuestion struct {
+		Type         string         `xml:"type,attr"`
+		Name         *moodleText    `xml:"name,omitempty"`
+		QuestionText *moodleText    `xml:"questiontext,omitempty"`
+		Single       string         `xml:"single,omitempty"`
+		Answers      []moodleAnswer `xml:"answer"`
+	}
+)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func encodeQuizMoodleXML(data *QuizTransferData) ([]byte, error) {
+	quiz := moodleQuiz{Questions: make([]moodleQuestion, 0, len(data.Questions))}
+
+	for i, question := range data.Questions {
+		item := moodleQuestion{
+			Type:         "multichoice",
+			Name:         &moodleText{Text: fmt.Sprintf("Q%d", i+1)},
+			QuestionText: &moodleText{Format: "plain_text", Text: question.Question},
+		}
+
+		correctCount := 0
+		for _, answer := range question.Answers {
+			if answer.IsCorrect {
+				correctCount++
+			}
+		}
+
+		switch question.Type {
+		case models.QuestionTypeTextInput:
+			item.Type = "shortanswer"
+		case models.QuestionTypeMultipleChoice:
+			item.Single = "false"
+		default:
+			item.Single = "true"
+		}
+
+		for _, answer := range question.Answers {
+			fraction := "0"
+			switch {
+			case answer.IsCorrect && question.Type == models.QuestionTypeMultipleChoice:
+				fraction = strconv.FormatFloat(math.Floor(100/float64(max(correctCount, 1))*100000)/100000, 'f', -1, 64)
+			case answer.IsCorrect:
+				fraction = "100"
+			case question.Type == models.QuestionTypeMultipleChoice:
+				fraction = "-100"
+			}
+			item.Answers = append(item.Answers, moodleAnswer{
+				Fraction: fraction,
+				Format:   "plain_text",
+				Text:     answer.Text,
+			})
+		}
+
+		quiz.Questions = append(quiz.Questions, item)
+	}
+
+	content, err := xml.MarshalIndent(quiz, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), content...), nil
+}
+
+func decodeQuizMoodleXML(content []byte) (*QuizTransferData, error) {
+	var quiz moodleQuiz
+	if err := xml.Unmarshal(content, &quiz); err != nil {
+		return nil, fmt.Errorf("invalid Moodle XML: %w", err)
+	}
+
+	data := &QuizTransferData{Version: QuizTransferVersion}
+
+	row := 0
+	for _, item := range quiz.Questions {
+		if item.Type == "category" {
+			continue
+		}
+		row++
+
+		question := QuizTransferQuestion{
+			Row:     row,
+			Answers: []models.AnswerData{},
+		}
+		if item.QuestionText != nil {
+			question.Question = moodlePlainText(item.QuestionText.Format, item.QuestionText.Text)
+		}
+
+		switch item.Type {
+		case "multichoice":
+			question.Type = models.QuestionTypeSingleChoice
+			if strings.EqualFold(strings.TrimSpace(item.Single), "false") {
+				question.Type = models.QuestionTypeMultipleChoice
+			}
+		case "truefalse":
+			question.Type = models.QuestionTypeSingleChoice
+		case "shortanswer":
+			question.Type = models.QuestionTypeTextInput
+		default:
+			return nil, fmt.Errorf("question %d: question type %q is not supported", row, item.Type)
+		}
+
+		for _, answer := range item.Answers {
+			fraction, err := strconv.ParseFloat(strings.TrimSpace(answer.Fraction), 64)
+			if err != nil {
+				return nil, fmt.Errorf("question %d: invalid answer fraction %q", row, answer.Fraction)
+			}
+
+			text := moodlePlainText(answer.Format, answer.Text)
+			if item.Type == "truefalse" {
+				text = strings.ToUpper(text[:min(1, len(text))]) + text[min(1, len(text)):]
+			}
+
+			question.Answers = append(question.Answers, models.AnswerData{
+				Text:      text,
+				IsCorrect: fraction > 0,
+			})
+		}
+
+		data.Questions = append(data.Questions, question)
+	}
+
+	return data, nil
+}
+
+func moodlePlainText(format, text string) string {
+	if format == "" || format == "html" || format == "moodle_auto_format" {
+		text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, " "))
+	}
+	return flattenLine(text)
+}
@@ -0,0 +1,156 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+	"github.com/samber/lo"
+)
+
+type quizVersionContent struct {
+	Title           string                       `json:"title"`
+	Description     *string                      `json:"description"`
+	Visibility      models.QuizVisibility        `json:"visibility"`
+	MaxParticipants *int32                       `json:"max_participants"`
+	Questions       []models.QuizVersionQuestion `json:"questions"`
+}
+
+func NewQuizVersionQuestions(questions []models.Question) []models.QuizVersionQuestion {
+	versionQuestions := make([]models.QuizVersionQuestion, len(questions))
+	for i, question := range questions {
+		versionQuestions[i] = models.QuizVersionQuestion{
+			QuestionID:       question.ID,
+			Index:            question.Index,
+			Question:         question.Question,
+			ContentFormat:    question.ContentFormat,
+			Type:             question.Type,
+			Answers:          transformers.StripAnswersHTML(question.Answers),
+			TimeLimit:        question.TimeLimit,
+			PointsMultiplier: question.PointsMultiplier,
+			Media:            question.Media,
+			Explanation:      question.Explanation,
+			ReferenceURL:     question.ReferenceURL,
+		}
+	}
+
+	return versionQuestions
+}
+
+// ComputeQuizContentHash fingerprints everything a version stores, so unchanged quizzes are not snapshotted twice
+func ComputeQuizContentHash(quiz *models.Quiz, questions []models.QuizVersionQuestion) (string, error) {
+	content, err := json.Marshal(quizVersionContent{
+		Title:           quiz.Title,
+		Description:     quiz.Description,
+		Visibility:      quiz.Visibility,
+		MaxParticipants: quiz.MaxParticipants,
+		Questions:       questions,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DiffQuizVersions compares quiz metadata field by field and questions by their position in the quiz
+func DiffQuizVersions(from, to *models.QuizVersion) *models.QuizVersionDiff {
+	diff := &models.QuizVersionDiff{
+		QuizID:      to.QuizID,
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Fields:      []models.QuizVersionFieldChange{},
+		Questions:   []models.QuizVersionQuestionChange{},
+	}
+
+	if from.Title != to.Title {
+		diff.Fields = append(diff.Fields, models.QuizVersionFieldChange{Field: "title", From: from.Title, To: to.Title})
+	}
+	if lo.FromPtr(from.Description) != lo.FromPtr(to.Description) {
+		diff.Fields = append(diff.Fields, models.QuizVersionFieldChange{Field: "description", From: from.Description, To: to.Description})
+	}
+	if from.Visibility != to.Visibility {
+		diff.Fields = append(diff.Fields, models.QuizVersionFieldChange{Field: "visibility", From: from.Visibility, To: to.Visibility})
+	}
+	if lo.FromPtr(from.MaxParticipants) != lo.FromPtr(to.MaxParticipants) {
+		diff.Fields = append(diff.Fields, models.QuizVersionFieldChange{Field: "max_participants", From: from.MaxParticipants, To: to.MaxParticipants})
+	}
+
+	for i := 0; i < max(len(from.Questions), len(to.Questions)); i++ {
+		switch {
+		case i >= len(from.Questions):
+			diff.Questions = append(diff.Questions, models.QuizVersionQuestionChange{
+				Index:  to.Questions[i].Index,
+				Change: models.QuizVersionQuestionAdded,
+				To:     &to.Questions[i],
+			})
+		case i >= len(to.Questions):
+			diff.Questions = append(diff.Questions, models.QuizVersionQuestionChange{
+				Index:  from.Questions[i].Index,
+				Change: models.QuizVersionQuestionRemoved,
+				From:   &from.Questions[i],
+			})
+		default:
+			if fields := diffQuizVersionQuestion(&from.Questions[i], &to.Questions[i]); len(fields) > 0 {
+				diff.Questions = append(diff.Questions, models.QuizVersionQuestionChange{
+					Index:  to.Questions[i].Index,
+					Change: models.QuizVersionQuestionModified,
+					Fields: fields,
+					From:   &from.Questions[i],
+					To:     &to.Questions[i],
+				})
+			}
+		}
+	}
+
+	return diff
+}
+
+func diffQuizVersionQuestion(from, to *models.QuizVersionQuestion) []string {
+	fields := []string{}
+
+	if from.Question != to.Question {
+		fields = append(fields, "question")
+	}
+	if from.ContentFormat != to.ContentFormat {
+		fields = append(fields, "content_format")
+	}
+	if from.Type != to.Type {
+		fields = append(fields, "type")
+	}
+	if !slices.EqualFunc(from.Answers, to.Answers, equalAnswer) {
+		fields = append(fields, "answers")
+	}
+	if from.TimeLimit != to.TimeLimit {
+		fields = append(fields, "time_limit")
+	}
+	if from.PointsMultiplier != to.PointsMultiplier {
+		fields = append(fields, "points_multiplier")
+	}
+	if mediaID(from.Media) != mediaID(to.Media) {
+		fields = append(fields, "media")
+	}
+	if lo.FromPtr(from.Explanation) != lo.FromPtr(to.Explanation) {
+		fields = append(fields, "explanation")
+	}
+	if lo.FromPtr(from.ReferenceURL) != lo.FromPtr(to.ReferenceURL) {
+		fields = append(fields, "reference_url")
+	}
+
+	return fields
+}
+
+func equalAnswer(a, b models.AnswerData) bool {
+	return a.Text == b.Text && a.IsCorrect == b.IsCorrect && mediaID(a.Media) == mediaID(b.Media)
+}
+
+func mediaID(media *models.MediaAttachment) int64 {
+	if media == nil {
+		return 0
+	}
+	return media.ID
+}
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE quizzes
+    ADD COLUMN forked_from_quiz_id BIGINT REFERENCES quizzes(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_quizzes_forked_from ON quizzes(forked_from_quiz_id) WHERE forked_from_quiz_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_quizzes_forked_from;
+
+ALTER TABLE quizzes DROP COLUMN IF EXISTS forked_from_quiz_id;
+-- +goose StatementEnd
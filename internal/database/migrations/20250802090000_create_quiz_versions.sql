@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Immutable snapshots of a quiz: metadata plus the ordered questions at the time of the snapshot
+CREATE TABLE IF NOT EXISTS quiz_versions (
+    id BIGSERIAL PRIMARY KEY,
+    quiz_id BIGINT NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    visibility quiz_visibility NOT NULL,
+    max_participants INTEGER,
+    questions JSONB NOT NULL DEFAULT '[]',
+    content_hash VARCHAR(64) NOT NULL,
+    reason VARCHAR(20) NOT NULL, -- save, publish, restore, session
+    created_by BIGINT, -- NULL for anonymous hosts (no foreign key constraint)
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (quiz_id, version)
+);
+
+ALTER TABLE quiz_sessions
+    ADD COLUMN quiz_version_id BIGINT REFERENCES quiz_versions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_quiz_sessions_quiz_version_id ON quiz_sessions(quiz_version_id) WHERE quiz_version_id IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_quiz_sessions_quiz_version_id;
+
+ALTER TABLE quiz_sessions DROP COLUMN IF EXISTS quiz_version_id;
+
+DROP TABLE IF EXISTS quiz_versions;
+
+-- +goose StatementEnd
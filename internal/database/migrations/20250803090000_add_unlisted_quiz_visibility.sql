@@ -0,0 +1,24 @@
+-- +goose NO TRANSACTION
+
+-- +goose Up
+-- ALTER TYPE ... ADD VALUE cannot be used in the same transaction that adds it, so this migration runs without one
+ALTER TYPE quiz_visibility ADD VALUE IF NOT EXISTS 'unlisted' BEFORE 'published';
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Postgres cannot drop an enum value, so unlisted quizzes fall back to private and the type is rebuilt
+UPDATE quizzes SET visibility = 'private' WHERE visibility = 'unlisted';
+UPDATE quiz_versions SET visibility = 'private' WHERE visibility = 'unlisted';
+
+ALTER TYPE quiz_visibility RENAME TO quiz_visibility_old;
+CREATE TYPE quiz_visibility AS ENUM ('private', 'published');
+
+ALTER TABLE quizzes ALTER COLUMN visibility DROP DEFAULT;
+ALTER TABLE quizzes ALTER COLUMN visibility TYPE quiz_visibility USING visibility::text::quiz_visibility;
+ALTER TABLE quizzes ALTER COLUMN visibility SET DEFAULT 'private';
+ALTER TABLE quiz_versions ALTER COLUMN visibility TYPE quiz_visibility USING visibility::text::quiz_visibility;
+
+DROP TYPE quiz_visibility_old;
+
+-- +goose StatementEnd
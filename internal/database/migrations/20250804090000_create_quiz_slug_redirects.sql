@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Previous slugs of a quiz, kept so that old links keep resolving after the slug changes
+CREATE TABLE IF NOT EXISTS quiz_slug_redirects (
+    slug VARCHAR(100) PRIMARY KEY,
+    quiz_id BIGINT NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_quiz_slug_redirects_quiz_id ON quiz_slug_redirects(quiz_id);
+
+-- Backfill quizzes created before slugs were generated, the id suffix keeps them unique
+UPDATE quizzes
+SET slug = COALESCE(NULLIF(TRIM(BOTH '-' FROM LEFT(REGEXP_REPLACE(LOWER(title), '[^a-z0-9]+', '-', 'g'), 80)), ''), 'quiz') || '-' || id
+WHERE slug IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_quiz_slug_redirects_quiz_id;
+
+DROP TABLE IF EXISTS quiz_slug_redirects;
+
+-- +goose StatementEnd
@@ -0,0 +1,84 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Full-text search document per quiz, kept in its own table so quiz rows stay lean.
+-- Title ranks above description, which ranks above question text.
+CREATE TABLE IF NOT EXISTS quiz_search_documents (
+    quiz_id BIGINT PRIMARY KEY REFERENCES quizzes(id) ON DELETE CASCADE,
+    document TSVECTOR NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_quiz_search_documents_document ON quiz_search_documents USING GIN (document);
+
+-- Supports the question count filter of the public catalogue
+CREATE INDEX idx_quizzes_total_questions ON quizzes(total_questions);
+
+CREATE OR REPLACE FUNCTION refresh_quiz_search_document(target_quiz_id BIGINT)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO quiz_search_documents (quiz_id, document, updated_at)
+    SELECT
+        q.id,
+        setweight(to_tsvector('english', COALESCE(q.title, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(q.description, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(
+            (SELECT string_agg(qs.question, ' ') FROM questions qs WHERE qs.quiz_id = q.id), ''
+        )), 'C'),
+        NOW()
+    FROM quizzes q
+    WHERE q.id = target_quiz_id
+    ON CONFLICT (quiz_id) DO UPDATE
+    SET document = EXCLUDED.document, updated_at = EXCLUDED.updated_at;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION quizzes_search_document_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_quiz_search_document(NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION questions_search_document_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_quiz_search_document(OLD.quiz_id);
+        RETURN OLD;
+    END IF;
+    PERFORM refresh_quiz_search_document(NEW.quiz_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_quizzes_search_document
+    AFTER INSERT OR UPDATE OF title, description ON quizzes
+    FOR EACH ROW EXECUTE FUNCTION quizzes_search_document_trigger();
+
+CREATE TRIGGER trigger_questions_search_document
+    AFTER INSERT OR DELETE OR UPDATE OF question ON questions
+    FOR EACH ROW EXECUTE FUNCTION questions_search_document_trigger();
+
+-- Index existing quizzes
+SELECT refresh_quiz_search_document(id) FROM quizzes;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS trigger_questions_search_document ON questions;
+DROP TRIGGER IF EXISTS trigger_quizzes_search_document ON quizzes;
+
+DROP FUNCTION IF EXISTS questions_search_document_trigger();
+DROP FUNCTION IF EXISTS quizzes_search_document_trigger();
+DROP FUNCTION IF EXISTS refresh_quiz_search_document(BIGINT);
+
+DROP INDEX IF EXISTS idx_quizzes_total_questions;
+DROP INDEX IF EXISTS idx_quiz_search_documents_document;
+
+DROP TABLE IF EXISTS quiz_search_documents;
+
+-- +goose StatementEnd
@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Tags are free-form labels owners put on their quizzes.
+-- Categories are curated tags (is_category = TRUE) that can be browsed publicly; owners can assign but not create them.
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    slug VARCHAR(60) UNIQUE NOT NULL,
+    is_category BOOLEAN NOT NULL DEFAULT FALSE,
+    description TEXT,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS quiz_tags (
+    quiz_id BIGINT NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (quiz_id, tag_id)
+);
+
+CREATE INDEX idx_quiz_tags_tag_id ON quiz_tags(tag_id);
+CREATE INDEX idx_tags_is_category ON tags(sort_order) WHERE is_category;
+
+-- Curated categories
+INSERT INTO tags (name, slug, is_category, description, sort_order) VALUES
+('General Knowledge', 'general-knowledge', TRUE, 'A bit of everything', 1),
+('Science & Technology', 'science-technology', TRUE, 'Physics, chemistry, biology and modern tech', 2),
+('History & Geography', 'history-geography', TRUE, 'Historical events and the world around us', 3),
+('Mathematics', 'mathematics', TRUE, 'Numbers, logic and puzzles', 4),
+('Languages & Literature', 'languages-literature', TRUE, 'Words, grammar and great books', 5),
+('Arts & Entertainment', 'arts-entertainment', TRUE, 'Music, film, art and pop culture', 6),
+('Sports', 'sports', TRUE, 'Games, athletes and competitions', 7)
+ON CONFLICT (slug) DO NOTHING;
+
+-- Categorise the seed quizzes
+INSERT INTO quiz_tags (quiz_id, tag_id)
+SELECT q.id, t.id
+FROM quizzes q
+JOIN tags t ON t.slug = CASE q.slug
+    WHEN 'general-knowledge-quiz' THEN 'general-knowledge'
+    WHEN 'science-technology-quiz' THEN 'science-technology'
+    WHEN 'history-geography-quiz' THEN 'history-geography'
+END
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_tags_is_category;
+DROP INDEX IF EXISTS idx_quiz_tags_tag_id;
+
+DROP TABLE IF EXISTS quiz_tags;
+DROP TABLE IF EXISTS tags;
+
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Files uploaded by users. The bytes live in object storage under storage_key; rows only describe them.
+CREATE TABLE IF NOT EXISTS media (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key VARCHAR(255) UNIQUE NOT NULL,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('image', 'audio')),
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    original_name VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_media_owner_id ON media(owner_id);
+
+-- Attachment shown with the question: {"id", "kind", "content_type", "url"}.
+-- Answer options carry the same object under "media" inside questions.answers.
+ALTER TABLE questions ADD COLUMN media JSONB;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE questions DROP COLUMN IF EXISTS media;
+
+DROP INDEX IF EXISTS idx_media_owner_id;
+
+DROP TABLE IF EXISTS media;
+
+-- +goose StatementEnd
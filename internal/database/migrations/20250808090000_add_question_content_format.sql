@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Room for real technical questions: code blocks, formulas and markdown markup all count towards the length
+ALTER TABLE questions ALTER COLUMN question TYPE VARCHAR(2000);
+
+-- How question and answer text is written. Rendered HTML is derived on read and never stored.
+ALTER TABLE questions ADD COLUMN content_format VARCHAR(10) NOT NULL DEFAULT 'plain'
+    CHECK (content_format IN ('plain', 'markdown'));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE questions DROP COLUMN IF EXISTS content_format;
+
+ALTER TABLE questions ALTER COLUMN question TYPE VARCHAR(100) USING LEFT(question, 100);
+
+-- +goose StatementEnd
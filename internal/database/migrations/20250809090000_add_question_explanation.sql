@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Why the correct answer is correct, in markdown, plus an optional link to read more.
+-- Only revealed to players once the question has closed.
+ALTER TABLE questions ADD COLUMN explanation VARCHAR(2000);
+ALTER TABLE questions ADD COLUMN reference_url VARCHAR(2048);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE questions DROP COLUMN IF EXISTS reference_url;
+ALTER TABLE questions DROP COLUMN IF EXISTS explanation;
+
+-- +goose StatementEnd
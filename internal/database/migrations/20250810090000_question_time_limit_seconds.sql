@@ -0,0 +1,73 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Time limits become plain seconds. The allowed range is configurable, so only positivity is enforced here.
+DROP INDEX IF EXISTS idx_questions_type_time;
+
+ALTER TABLE questions ALTER COLUMN time_limit DROP DEFAULT;
+ALTER TABLE questions ALTER COLUMN time_limit TYPE INTEGER USING time_limit::text::integer;
+ALTER TABLE questions ALTER COLUMN time_limit SET DEFAULT 20;
+ALTER TABLE questions ADD CONSTRAINT questions_time_limit_positive CHECK (time_limit > 0);
+
+CREATE INDEX idx_questions_type_time ON questions(type, time_limit);
+
+DROP TYPE IF EXISTS time_limit_type;
+
+-- 0 for an unscored warm-up question, 2 for double points rounds
+ALTER TABLE questions ADD COLUMN points_multiplier INTEGER NOT NULL DEFAULT 1
+    CHECK (points_multiplier IN (0, 1, 2));
+
+-- Version snapshots store questions as JSON with the old string time limits
+UPDATE quiz_versions
+SET questions = (
+    SELECT COALESCE(jsonb_agg(
+        question
+            || jsonb_build_object('time_limit', (question->>'time_limit')::integer)
+            || jsonb_build_object('points_multiplier', 1)
+        ORDER BY position
+    ), '[]'::jsonb)
+    FROM jsonb_array_elements(questions) WITH ORDINALITY AS elements(question, position)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+UPDATE quiz_versions
+SET questions = (
+    SELECT COALESCE(jsonb_agg(
+        (question - 'points_multiplier')
+            || jsonb_build_object('time_limit', CASE
+                WHEN (question->>'time_limit')::integer <= 5 THEN '5'
+                WHEN (question->>'time_limit')::integer <= 10 THEN '10'
+                WHEN (question->>'time_limit')::integer <= 20 THEN '20'
+                WHEN (question->>'time_limit')::integer <= 45 THEN '45'
+                ELSE '80'
+            END)
+        ORDER BY position
+    ), '[]'::jsonb)
+    FROM jsonb_array_elements(questions) WITH ORDINALITY AS elements(question, position)
+);
+
+ALTER TABLE questions DROP COLUMN IF EXISTS points_multiplier;
+
+CREATE TYPE time_limit_type AS ENUM ('5', '10', '20', '45', '80');
+
+DROP INDEX IF EXISTS idx_questions_type_time;
+
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_time_limit_positive;
+ALTER TABLE questions ALTER COLUMN time_limit DROP DEFAULT;
+-- Limits outside the old set are rounded up to the nearest one
+ALTER TABLE questions ALTER COLUMN time_limit TYPE time_limit_type USING (CASE
+    WHEN time_limit <= 5 THEN '5'
+    WHEN time_limit <= 10 THEN '10'
+    WHEN time_limit <= 20 THEN '20'
+    WHEN time_limit <= 45 THEN '45'
+    ELSE '80'
+END)::time_limit_type;
+ALTER TABLE questions ALTER COLUMN time_limit SET DEFAULT '20';
+
+CREATE INDEX idx_questions_type_time ON questions(type, time_limit);
+
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Users the owner shares a quiz with. Editors change the quiz and its questions, viewers only see it.
+-- The owner stays on quizzes.owner_id and never has a row here.
+CREATE TABLE IF NOT EXISTS quiz_collaborators (
+    quiz_id BIGINT NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    invited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (quiz_id, user_id)
+);
+
+CREATE INDEX idx_quiz_collaborators_user_id ON quiz_collaborators(user_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_quiz_collaborators_user_id;
+
+DROP TABLE IF EXISTS quiz_collaborators;
+
+-- +goose StatementEnd
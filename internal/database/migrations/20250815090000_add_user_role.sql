@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TYPE user_role AS ENUM ('player', 'admin');
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role user_role NOT NULL DEFAULT 'player';
+
+CREATE INDEX idx_users_role ON users(role) WHERE role <> 'player';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_users_role;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS role;
+
+DROP TYPE IF EXISTS user_role;
+
+-- +goose StatementEnd
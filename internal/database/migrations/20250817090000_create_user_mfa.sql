@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- TOTP second factor. A row with no enabled_at is an enrolment waiting for its first code.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT, -- Time step of the last accepted code, so a code cannot be replayed
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Single-use codes for when the authenticator is lost. Only SHA-256 hashes are stored.
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS user_recovery_codes;
+
+DROP TABLE IF EXISTS user_totp;
+
+-- +goose StatementEnd
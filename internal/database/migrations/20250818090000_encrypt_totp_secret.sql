@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- TOTP secrets are now stored sealed with AES-GCM ("v1:" followed by base64), which does not fit
+-- in 64 characters. Rows written before this are sealed by the application the next time they are read.
+ALTER TABLE user_totp
+    ALTER COLUMN secret TYPE TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Sealed secrets are longer than the old column and cannot be opened in SQL, so the type stays TEXT
+SELECT 1;
+
+-- +goose StatementEnd
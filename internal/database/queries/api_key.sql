@@ -0,0 +1,35 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at;
+
+-- name: GetAPIKeyByHash :one
+SELECT
+    k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scopes, k.expires_at, k.last_used_at, k.created_at,
+    u.username, u.email, u.is_active, u.role
+FROM api_keys k
+JOIN users u ON u.id = k.user_id
+WHERE k.key_hash = $1;
+
+-- name: ListAPIKeysByUser :many
+SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+FROM api_keys
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: CountAPIKeysByUser :one
+SELECT COUNT(*) FROM api_keys
+WHERE user_id = $1;
+
+-- name: DeleteAPIKey :execrows
+DELETE FROM api_keys
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteAPIKeysByUser :execrows
+DELETE FROM api_keys
+WHERE user_id = $1;
+
+-- name: TouchAPIKeyLastUsed :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
@@ -0,0 +1,4 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_logs (actor_id, action, target_type, target_id, metadata)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, actor_id, action, target_type, target_id, metadata, created_at;
@@ -30,10 +30,26 @@ INSERT INTO users (
 ) VALUES (
   $1, $2, $3, true
 )
-RETURNING id, username, email, is_active, created_at, updated_at, last_login_at;
+RETURNING id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role;
 
 -- name: GetUserByEmailIncludePassword :one
 SELECT *
 FROM users
 WHERE email = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetUserByIDIncludePassword :one
+SELECT *
+FROM users
+WHERE id = $1
+LIMIT 1;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET password = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkUserEmailVerified :execrows
+UPDATE users
+SET email_verified = TRUE, email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND email = $2 AND NOT email_verified;
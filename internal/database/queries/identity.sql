@@ -0,0 +1,14 @@
+-- name: GetIdentityByProviderSubject :one
+SELECT id, user_id, provider, subject, email, created_at, last_login_at
+FROM identities
+WHERE provider = $1 AND subject = $2;
+
+-- name: CreateIdentity :one
+INSERT INTO identities (user_id, provider, subject, email, last_login_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING id, user_id, provider, subject, email, created_at, last_login_at;
+
+-- name: UpdateIdentityLogin :exec
+UPDATE identities
+SET email = $2, last_login_at = NOW()
+WHERE id = $1;
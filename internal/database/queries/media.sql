@@ -0,0 +1,7 @@
+-- name: CreateMedia :one
+INSERT INTO media (owner_id, storage_key, kind, content_type, size_bytes, original_name)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetMediaByIDs :many
+SELECT * FROM media WHERE id = ANY(@ids::bigint[]);
@@ -0,0 +1,48 @@
+-- name: GetUserTOTP :one
+SELECT user_id, secret, enabled_at, last_used_step, created_at
+FROM user_totp
+WHERE user_id = $1;
+
+-- name: UpsertPendingUserTOTP :one
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, last_used_step = NULL, created_at = NOW()
+WHERE user_totp.enabled_at IS NULL
+RETURNING user_id, secret, enabled_at, last_used_step, created_at;
+
+-- name: UpdateUserTOTPSecret :exec
+UPDATE user_totp
+SET secret = $2
+WHERE user_id = $1;
+
+-- name: EnableUserTOTP :execrows
+UPDATE user_totp
+SET enabled_at = NOW(), last_used_step = $2
+WHERE user_id = $1 AND enabled_at IS NULL;
+
+-- name: UseUserTOTPStep :execrows
+UPDATE user_totp
+SET last_used_step = $2
+WHERE user_id = $1 AND enabled_at IS NOT NULL AND (last_used_step IS NULL OR last_used_step < $2);
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp
+WHERE user_id = $1;
+
+-- name: CreateRecoveryCodes :exec
+INSERT INTO user_recovery_codes (user_id, code_hash)
+SELECT @user_id::bigint, UNNEST(@code_hashes::text[]);
+
+-- name: UseRecoveryCode :execrows
+UPDATE user_recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*) FROM user_recovery_codes
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM user_recovery_codes
+WHERE user_id = $1;
@@ -1,6 +1,6 @@
 -- name: CreateQuestion :one
-INSERT INTO questions (quiz_id, question, type, answers, index, time_limit)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO questions (quiz_id, question, type, answers, index, time_limit, media, content_format, explanation, reference_url, points_multiplier)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *;
 
 -- name: GetQuestionByID :one
@@ -25,13 +25,17 @@ WHERE qz.id = $1 AND q.index = qz.current_question_index;
 
 -- name: UpdateQuestion :one
 UPDATE questions 
-SET question = $2, type = $3, answers = $4, time_limit = $5, updated_at = NOW()
+SET question = $2, type = $3, answers = $4, time_limit = $5, media = $6, content_format = $7, explanation = $8, reference_url = $9, points_multiplier = $10, updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
 -- name: DeleteQuestion :exec
 DELETE FROM questions WHERE id = $1;
 
+-- name: DeleteQuestionsByQuizExcept :exec
+DELETE FROM questions
+WHERE quiz_id = $1 AND NOT (id = ANY(@keep_ids::bigint[]));
+
 -- name: CountQuestionsByQuiz :one
 SELECT COUNT(*) FROM questions WHERE quiz_id = $1;
 
@@ -1,13 +1,20 @@
 -- name: CreateQuiz :one
-INSERT INTO quizzes (title, description, visibility, owner_id, max_participants)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at;
+INSERT INTO quizzes (title, description, visibility, owner_id, max_participants, forked_from_quiz_id, slug)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (slug) DO NOTHING
+RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at, forked_from_quiz_id;
 
 -- name: UpdateQuiz :one
 UPDATE quizzes 
 SET title = $2, description = $3, visibility = $4, max_participants = $5, published_at = $6, updated_at = NOW()
 WHERE id = $1
-RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at;
+RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at, forked_from_quiz_id;
+
+-- name: MakeQuizPrivate :one
+UPDATE quizzes
+SET visibility = 'private', published_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at, forked_from_quiz_id;
 
 -- name: GetQuizWithOwner :one
 SELECT 
@@ -15,39 +22,67 @@ SELECT
     u.id as owner_user_id,
     u.username as owner_username,
     u.email as owner_email,
-    u.avatar_url as owner_avatar_url
+    u.avatar_url as owner_avatar_url,
+    fq.title as forked_from_title,
+    fq.visibility as forked_from_visibility,
+    fu.username as forked_from_owner_username
 FROM quizzes q
 LEFT JOIN users u ON q.owner_id = u.id
+LEFT JOIN quizzes fq ON q.forked_from_quiz_id = fq.id
+LEFT JOIN users fu ON fq.owner_id = fu.id
 WHERE q.id = $1;
 
 -- name: GetQuizListByOwner :many
-SELECT id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at FROM quizzes 
-WHERE owner_id = $1
+SELECT id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at, forked_from_quiz_id FROM quizzes 
+WHERE (owner_id = $1 OR EXISTS (
+    SELECT 1 FROM quiz_collaborators qc WHERE qc.quiz_id = quizzes.id AND qc.user_id = $1
+  ))
   AND (sqlc.narg('query')::text IS NULL OR (title ILIKE '%' || sqlc.narg('query') || '%' OR description ILIKE '%' || sqlc.narg('query') || '%'))
   AND (sqlc.narg('visibility')::quiz_visibility IS NULL OR visibility = sqlc.narg('visibility'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM quiz_tags qt JOIN tags t ON t.id = qt.tag_id
+    WHERE qt.quiz_id = quizzes.id AND t.slug = sqlc.narg('tag')::text
+  ))
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: CountQuizListByOwner :one
 SELECT COUNT(*) FROM quizzes
-WHERE owner_id = $1
+WHERE (owner_id = $1 OR EXISTS (
+    SELECT 1 FROM quiz_collaborators qc WHERE qc.quiz_id = quizzes.id AND qc.user_id = $1
+  ))
   AND (sqlc.narg('query')::text IS NULL OR (title ILIKE '%' || sqlc.narg('query') || '%' OR description ILIKE '%' || sqlc.narg('query') || '%'))
-  AND (sqlc.narg('visibility')::quiz_visibility IS NULL OR visibility = sqlc.narg('visibility')); 
+  AND (sqlc.narg('visibility')::quiz_visibility IS NULL OR visibility = sqlc.narg('visibility'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM quiz_tags qt JOIN tags t ON t.id = qt.tag_id
+    WHERE qt.quiz_id = quizzes.id AND t.slug = sqlc.narg('tag')::text
+  )); 
 
 -- name: DeleteQuiz :exec
 DELETE FROM quizzes WHERE id = $1;
 
 -- name: GetPublicQuizzes :many
 SELECT 
-    q.id, q.title, q.description, q.owner_id, q.visibility, q.slug, q.view_count, q.play_count, q.max_participants, q.current_question_index, q.total_questions, q.created_at, q.updated_at, q.published_at,
+    q.id, q.title, q.description, q.owner_id, q.visibility, q.slug, q.view_count, q.play_count, q.max_participants, q.current_question_index, q.total_questions, q.created_at, q.updated_at, q.published_at, q.forked_from_quiz_id,
     u.id as owner_user_id,
     u.username as owner_username,
     u.email as owner_email,
-    u.avatar_url as owner_avatar_url
+    u.avatar_url as owner_avatar_url,
+    COALESCE(ts_rank(sd.document, websearch_to_tsquery('english', sqlc.narg('query')::text)), 0)::real as relevance
 FROM quizzes q
 LEFT JOIN users u ON q.owner_id = u.id
+LEFT JOIN quiz_search_documents sd ON sd.quiz_id = q.id
 WHERE q.visibility = 'published'
-  AND (sqlc.narg('query')::text IS NULL OR (q.title ILIKE '%' || sqlc.narg('query') || '%' OR q.description ILIKE '%' || sqlc.narg('query') || '%'))
+  AND (sqlc.narg('query')::text IS NULL OR sd.document @@ websearch_to_tsquery('english', sqlc.narg('query')::text))
+  AND (sqlc.narg('owner_id')::bigint IS NULL OR q.owner_id = sqlc.narg('owner_id')::bigint)
+  AND (sqlc.narg('min_questions')::integer IS NULL OR COALESCE(q.total_questions, 0) >= sqlc.narg('min_questions')::integer)
+  AND (sqlc.narg('max_questions')::integer IS NULL OR COALESCE(q.total_questions, 0) <= sqlc.narg('max_questions')::integer)
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR q.created_at >= sqlc.narg('created_from')::timestamptz)
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR q.created_at < sqlc.narg('created_to')::timestamptz)
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM quiz_tags qt JOIN tags t ON t.id = qt.tag_id
+    WHERE qt.quiz_id = q.id AND t.slug = sqlc.narg('tag')::text
+  ))
   AND (
     sqlc.narg('cursor_id')::bigint IS NULL OR 
     CASE sqlc.narg('sort_by')::text
@@ -57,6 +92,8 @@ WHERE q.visibility = 'published'
       WHEN 'time_oldest' THEN q.created_at > sqlc.narg('cursor_created_at')::timestamptz OR (q.created_at = sqlc.narg('cursor_created_at')::timestamptz AND q.id > sqlc.narg('cursor_id')::bigint)
       WHEN 'view_count' THEN q.view_count < sqlc.narg('cursor_view_count')::integer OR (q.view_count = sqlc.narg('cursor_view_count')::integer AND q.id > sqlc.narg('cursor_id')::bigint)
       WHEN 'play_count' THEN q.play_count < sqlc.narg('cursor_play_count')::integer OR (q.play_count = sqlc.narg('cursor_play_count')::integer AND q.id > sqlc.narg('cursor_id')::bigint)
+      WHEN 'relevance' THEN COALESCE(ts_rank(sd.document, websearch_to_tsquery('english', sqlc.narg('query')::text)), 0)::real < sqlc.narg('cursor_relevance')::real
+        OR (COALESCE(ts_rank(sd.document, websearch_to_tsquery('english', sqlc.narg('query')::text)), 0)::real = sqlc.narg('cursor_relevance')::real AND q.id > sqlc.narg('cursor_id')::bigint)
       ELSE q.created_at < sqlc.narg('cursor_created_at')::timestamptz OR (q.created_at = sqlc.narg('cursor_created_at')::timestamptz AND q.id > sqlc.narg('cursor_id')::bigint)
     END
   )
@@ -79,6 +116,9 @@ ORDER BY
   CASE sqlc.narg('sort_by')::text
     WHEN 'play_count' THEN q.play_count
   END DESC,
+  CASE sqlc.narg('sort_by')::text
+    WHEN 'relevance' THEN COALESCE(ts_rank(sd.document, websearch_to_tsquery('english', sqlc.narg('query')::text)), 0)
+  END DESC,
   CASE 
     WHEN sqlc.narg('sort_by')::text IS NULL OR sqlc.narg('sort_by')::text = '' THEN q.created_at
   END DESC,
@@ -98,4 +138,37 @@ WHERE id = $1;
 -- name: UpdateQuizTotalQuestions :exec
 UPDATE quizzes 
 SET total_questions = $2, updated_at = NOW()
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+-- name: UpdateQuizSlug :exec
+UPDATE quizzes
+SET slug = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CheckQuizSlugExists :one
+SELECT EXISTS(
+    SELECT 1 FROM quizzes WHERE slug = $1
+    UNION ALL
+    SELECT 1 FROM quiz_slug_redirects WHERE slug = $1
+);
+
+-- name: GetQuizIDBySlug :one
+SELECT id FROM quizzes WHERE slug = $1;
+
+-- name: GetQuizIDBySlugRedirect :one
+SELECT quiz_id FROM quiz_slug_redirects WHERE slug = $1;
+
+-- name: CreateQuizSlugRedirect :exec
+INSERT INTO quiz_slug_redirects (slug, quiz_id)
+VALUES ($1, $2)
+ON CONFLICT (slug) DO UPDATE SET quiz_id = EXCLUDED.quiz_id, created_at = NOW();
+
+-- name: DeleteQuizSlugRedirect :exec
+DELETE FROM quiz_slug_redirects WHERE slug = $1;
+
+-- name: UpdateQuizOwner :exec
+UPDATE quizzes
+SET owner_id = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: LockQuiz :exec
+SELECT id FROM quizzes WHERE id = $1 FOR UPDATE;
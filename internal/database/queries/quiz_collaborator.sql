@@ -0,0 +1,29 @@
+-- name: UpsertQuizCollaborator :one
+INSERT INTO quiz_collaborators (quiz_id, user_id, role, invited_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (quiz_id, user_id) DO UPDATE
+SET role = EXCLUDED.role, updated_at = NOW()
+RETURNING quiz_id, user_id, role, invited_by, created_at, updated_at;
+
+-- name: GetQuizCollaboratorRole :one
+SELECT role FROM quiz_collaborators
+WHERE quiz_id = $1 AND user_id = $2;
+
+-- name: GetQuizCollaboratorRolesByUser :many
+SELECT quiz_id, role FROM quiz_collaborators
+WHERE user_id = $1 AND quiz_id = ANY(sqlc.arg('quiz_ids')::bigint[]);
+
+-- name: ListQuizCollaborators :many
+SELECT
+    qc.quiz_id, qc.user_id, qc.role, qc.invited_by, qc.created_at, qc.updated_at,
+    u.username,
+    u.email,
+    u.avatar_url
+FROM quiz_collaborators qc
+JOIN users u ON u.id = qc.user_id
+WHERE qc.quiz_id = $1
+ORDER BY qc.created_at ASC, qc.user_id ASC;
+
+-- name: DeleteQuizCollaborator :execrows
+DELETE FROM quiz_collaborators
+WHERE quiz_id = $1 AND user_id = $2;
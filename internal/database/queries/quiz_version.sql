@@ -0,0 +1,31 @@
+-- name: CreateQuizVersion :one
+INSERT INTO quiz_versions (
+    quiz_id, version, title, description, visibility, max_participants,
+    questions, content_hash, reason, created_by
+) VALUES (
+    $1, COALESCE((SELECT MAX(version) FROM quiz_versions WHERE quiz_id = $1), 0) + 1,
+    $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetLatestQuizVersion :one
+SELECT * FROM quiz_versions
+WHERE quiz_id = $1
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: GetQuizVersion :one
+SELECT * FROM quiz_versions
+WHERE quiz_id = $1 AND version = $2;
+
+-- name: GetQuizVersionByID :one
+SELECT * FROM quiz_versions
+WHERE id = $1;
+
+-- name: ListQuizVersions :many
+SELECT
+    id, quiz_id, version, title, description, visibility, max_participants,
+    content_hash, reason, created_by, created_at,
+    jsonb_array_length(questions)::int AS question_count
+FROM quiz_versions
+WHERE quiz_id = $1
+ORDER BY version DESC;
@@ -1,9 +1,9 @@
 -- name: CreateSession :one
 INSERT INTO quiz_sessions (
     quiz_id, host_id, join_code, status, max_participants,
-    current_question_index, participant_count
+    current_question_index, participant_count, quiz_version_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetSessionByID :one
@@ -94,3 +94,27 @@ WHERE id = $1;
 
 -- name: CheckJoinCodeExists :one
 SELECT EXISTS(SELECT 1 FROM quiz_sessions WHERE join_code = $1);
+
+-- name: ListLiveSessions :many
+SELECT
+    s.*,
+    q.title as quiz_title,
+    u.username as host_username
+FROM quiz_sessions s
+JOIN quizzes q ON s.quiz_id = q.id
+LEFT JOIN users u ON s.host_id = u.id
+WHERE s.status IN ('waiting', 'active')
+ORDER BY s.created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountLiveSessions :one
+SELECT COUNT(*) FROM quiz_sessions
+WHERE status IN ('waiting', 'active');
+
+-- name: CancelSession :execrows
+UPDATE quiz_sessions
+SET
+    status = 'cancelled',
+    ended_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('waiting', 'active');
@@ -0,0 +1,45 @@
+-- name: UpsertTag :one
+INSERT INTO tags (name, slug)
+VALUES ($1, $2)
+ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug
+RETURNING *;
+
+-- name: DeleteQuizTags :exec
+DELETE FROM quiz_tags WHERE quiz_id = $1;
+
+-- name: AddQuizTag :exec
+INSERT INTO quiz_tags (quiz_id, tag_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: GetTagsByQuizIDs :many
+SELECT qt.quiz_id, t.id, t.name, t.slug, t.is_category
+FROM quiz_tags qt
+JOIN tags t ON t.id = qt.tag_id
+WHERE qt.quiz_id = ANY(@quiz_ids::bigint[])
+ORDER BY t.is_category DESC, t.name ASC;
+
+-- name: GetCategoryBySlug :one
+SELECT * FROM tags
+WHERE slug = $1 AND is_category;
+
+-- name: ListCategories :many
+SELECT
+    t.id, t.name, t.slug, t.is_category, t.description, t.sort_order, t.created_at,
+    COUNT(q.id) AS quiz_count
+FROM tags t
+LEFT JOIN quiz_tags qt ON qt.tag_id = t.id
+LEFT JOIN quizzes q ON q.id = qt.quiz_id AND q.visibility = 'published'
+WHERE t.is_category
+GROUP BY t.id
+ORDER BY t.sort_order ASC, t.name ASC;
+
+-- name: GetPopularTags :many
+SELECT t.id, t.name, t.slug, t.is_category, COUNT(*) AS quiz_count
+FROM tags t
+JOIN quiz_tags qt ON qt.tag_id = t.id
+JOIN quizzes q ON q.id = qt.quiz_id
+WHERE q.visibility = 'published'
+GROUP BY t.id
+ORDER BY quiz_count DESC, t.name ASC
+LIMIT $1;
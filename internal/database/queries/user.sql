@@ -1,5 +1,5 @@
 -- name: GetUserDetail :one
-SELECT id, username, email, is_active, created_at, updated_at, last_login_at
+SELECT id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role
 FROM users
 WHERE id = $1;
 
@@ -14,7 +14,7 @@ LIMIT 1;
 UPDATE users 
 SET username = $2, updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, email, is_active, created_at, updated_at, last_login_at;
+RETURNING id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role;
 
 -- name: DeleteUser :exec
 DELETE FROM users WHERE id = $1;
@@ -23,4 +23,31 @@ DELETE FROM users WHERE id = $1;
 UPDATE users 
 SET last_login_at = NOW(), updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, email, is_active, created_at, updated_at, last_login_at;
+RETURNING id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role;
+
+-- name: FindUserByUsernameOrEmail :one
+SELECT id, username, email, avatar_url, is_active
+FROM users
+WHERE username = $1 OR LOWER(email) = LOWER($1)
+LIMIT 1;
+
+-- name: SetUserActive :one
+UPDATE users
+SET is_active = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role;
+
+-- name: ListUsers :many
+SELECT id, username, email, avatar_url, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role
+FROM users
+WHERE (sqlc.narg('query')::text IS NULL OR (username ILIKE '%' || sqlc.narg('query') || '%' OR email ILIKE '%' || sqlc.narg('query') || '%'))
+  AND (sqlc.narg('is_active')::boolean IS NULL OR is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('role')::user_role IS NULL OR role = sqlc.narg('role'))
+ORDER BY created_at DESC, id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE (sqlc.narg('query')::text IS NULL OR (username ILIKE '%' || sqlc.narg('query') || '%' OR email ILIKE '%' || sqlc.narg('query') || '%'))
+  AND (sqlc.narg('is_active')::boolean IS NULL OR is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('role')::user_role IS NULL OR role = sqlc.narg('role'));
@@ -79,7 +79,7 @@ func (q *Queries) FindUsernameSignUp(ctx context.Context, username string) (Find
 }
 
 const getUserByEmailIncludePassword = `-- name: GetUserByEmailIncludePassword :one
-SELECT id, username, email, password, avatar_url, is_active, created_at, updated_at, last_login_at
+SELECT id, username, email, password, avatar_url, is_active, created_at, updated_at, last_login_at, email_verified, email_verified_at, role
 FROM users
 WHERE email = $1
 LIMIT 1
@@ -98,10 +98,59 @@ func (q *Queries) GetUserByEmailIncludePassword(ctx context.Context, email strin
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastLoginAt,
+		&i.EmailVerified,
+		&i.EmailVerifiedAt,
+		&i.Role,
 	)
 	return i, err
 }
 
+const getUserByIDIncludePassword = `-- name: GetUserByIDIncludePassword :one
+SELECT id, username, email, password, avatar_url, is_active, created_at, updated_at, last_login_at, email_verified, email_verified_at, role
+FROM users
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetUserByIDIncludePassword(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByIDIncludePassword, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.AvatarUrl,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+		&i.EmailVerifiedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const markUserEmailVerified = `-- name: MarkUserEmailVerified :execrows
+UPDATE users
+SET email_verified = TRUE, email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND email = $2 AND NOT email_verified
+`
+
+type MarkUserEmailVerifiedParams struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
+
+func (q *Queries) MarkUserEmailVerified(ctx context.Context, arg MarkUserEmailVerifiedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markUserEmailVerified, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const registerAccount = `-- name: RegisterAccount :one
 INSERT INTO users (
   username,
@@ -111,7 +160,7 @@ INSERT INTO users (
 ) VALUES (
   $1, $2, $3, true
 )
-RETURNING id, username, email, is_active, created_at, updated_at, last_login_at
+RETURNING id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role
 `
 
 type RegisterAccountParams struct {
@@ -121,13 +170,16 @@ type RegisterAccountParams struct {
 }
 
 type RegisterAccountRow struct {
-	ID          int64              `json:"id"`
-	Username    string             `json:"username"`
-	Email       string             `json:"email"`
-	IsActive    bool               `json:"is_active"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+	ID              int64              `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	IsActive        bool               `json:"is_active"`
+	EmailVerified   bool               `json:"email_verified"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
+	Role            UserRole           `json:"role"`
 }
 
 func (q *Queries) RegisterAccount(ctx context.Context, arg RegisterAccountParams) (RegisterAccountRow, error) {
@@ -138,9 +190,28 @@ func (q *Queries) RegisterAccount(ctx context.Context, arg RegisterAccountParams
 		&i.Username,
 		&i.Email,
 		&i.IsActive,
+		&i.EmailVerified,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastLoginAt,
+		&i.Role,
 	)
 	return i, err
 }
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET password = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateUserPasswordParams struct {
+	ID       int64  `json:"id"`
+	Password string `json:"password"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, updateUserPassword, arg.ID, arg.Password)
+	return err
+}
@@ -68,6 +68,7 @@ type QuizVisibility string
 
 const (
 	QuizVisibilityPrivate   QuizVisibility = "private"
+	QuizVisibilityUnlisted  QuizVisibility = "unlisted"
 	QuizVisibilityPublished QuizVisibility = "published"
 )
 
@@ -109,6 +110,7 @@ func (ns NullQuizVisibility) Value() (driver.Value, error) {
 func (e QuizVisibility) Valid() bool {
 	switch e {
 	case QuizVisibilityPrivate,
+		QuizVisibilityUnlisted,
 		QuizVisibilityPublished:
 		return true
 	}
@@ -170,73 +172,115 @@ func (e SessionStatus) Valid() bool {
 	return false
 }
 
-type TimeLimitType string
+type UserRole string
 
 const (
-	TimeLimitType5  TimeLimitType = "5"
-	TimeLimitType10 TimeLimitType = "10"
-	TimeLimitType20 TimeLimitType = "20"
-	TimeLimitType45 TimeLimitType = "45"
-	TimeLimitType80 TimeLimitType = "80"
+	UserRolePlayer UserRole = "player"
+	UserRoleAdmin  UserRole = "admin"
 )
 
-func (e *TimeLimitType) Scan(src interface{}) error {
+func (e *UserRole) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = TimeLimitType(s)
+		*e = UserRole(s)
 	case string:
-		*e = TimeLimitType(s)
+		*e = UserRole(s)
 	default:
-		return fmt.Errorf("unsupported scan type for TimeLimitType: %T", src)
+		return fmt.Errorf("unsupported scan type for UserRole: %T", src)
 	}
 	return nil
 }
 
-type NullTimeLimitType struct {
-	TimeLimitType TimeLimitType `json:"time_limit_type"`
-	Valid         bool          `json:"valid"` // Valid is true if TimeLimitType is not NULL
+type NullUserRole struct {
+	UserRole UserRole `json:"user_role"`
+	Valid    bool     `json:"valid"` // Valid is true if UserRole is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullTimeLimitType) Scan(value interface{}) error {
+func (ns *NullUserRole) Scan(value interface{}) error {
 	if value == nil {
-		ns.TimeLimitType, ns.Valid = "", false
+		ns.UserRole, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.TimeLimitType.Scan(value)
+	return ns.UserRole.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullTimeLimitType) Value() (driver.Value, error) {
+func (ns NullUserRole) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.TimeLimitType), nil
+	return string(ns.UserRole), nil
 }
 
-func (e TimeLimitType) Valid() bool {
+func (e UserRole) Valid() bool {
 	switch e {
-	case TimeLimitType5,
-		TimeLimitType10,
-		TimeLimitType20,
-		TimeLimitType45,
-		TimeLimitType80:
+	case UserRolePlayer,
+		UserRoleAdmin:
 		return true
 	}
 	return false
 }
 
+type ApiKey struct {
+	ID         int64              `json:"id"`
+	UserID     int64              `json:"user_id"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	KeyHash    string             `json:"key_hash"`
+	Scopes     []string           `json:"scopes"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID         int64              `json:"id"`
+	ActorID    *int64             `json:"actor_id"`
+	Action     string             `json:"action"`
+	TargetType *string            `json:"target_type"`
+	TargetID   *int64             `json:"target_id"`
+	Metadata   []byte             `json:"metadata"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type Identity struct {
+	ID          int64              `json:"id"`
+	UserID      int64              `json:"user_id"`
+	Provider    string             `json:"provider"`
+	Subject     string             `json:"subject"`
+	Email       *string            `json:"email"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+}
+
+type Medium struct {
+	ID           int64              `json:"id"`
+	OwnerID      int64              `json:"owner_id"`
+	StorageKey   string             `json:"storage_key"`
+	Kind         string             `json:"kind"`
+	ContentType  string             `json:"content_type"`
+	SizeBytes    int64              `json:"size_bytes"`
+	OriginalName *string            `json:"original_name"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
 type Question struct {
-	ID        int64              `json:"id"`
-	QuizID    int64              `json:"quiz_id"`
-	Question  string             `json:"question"`
-	Type      QuestionType       `json:"type"`
-	Answers   []byte             `json:"answers"`
-	TimeLimit TimeLimitType      `json:"time_limit"`
-	Index     int32              `json:"index"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID               int64              `json:"id"`
+	QuizID           int64              `json:"quiz_id"`
+	Question         string             `json:"question"`
+	Type             QuestionType       `json:"type"`
+	Answers          []byte             `json:"answers"`
+	TimeLimit        int32              `json:"time_limit"`
+	Index            int32              `json:"index"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	Media            []byte             `json:"media"`
+	ContentFormat    string             `json:"content_format"`
+	Explanation      *string            `json:"explanation"`
+	ReferenceUrl     *string            `json:"reference_url"`
+	PointsMultiplier int32              `json:"points_multiplier"`
 }
 
 type Quiz struct {
@@ -254,6 +298,22 @@ type Quiz struct {
 	CreatedAt            pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
 	PublishedAt          pgtype.Timestamptz `json:"published_at"`
+	ForkedFromQuizID     *int64             `json:"forked_from_quiz_id"`
+}
+
+type QuizCollaborator struct {
+	QuizID    int64              `json:"quiz_id"`
+	UserID    int64              `json:"user_id"`
+	Role      string             `json:"role"`
+	InvitedBy *int64             `json:"invited_by"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type QuizSearchDocument struct {
+	QuizID    int64              `json:"quiz_id"`
+	Document  interface{}        `json:"document"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type QuizSession struct {
@@ -269,6 +329,34 @@ type QuizSession struct {
 	EndedAt              pgtype.Timestamptz `json:"ended_at"`
 	CreatedAt            pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
+	QuizVersionID        *int64             `json:"quiz_version_id"`
+}
+
+type QuizSlugRedirect struct {
+	Slug      string             `json:"slug"`
+	QuizID    int64              `json:"quiz_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type QuizTag struct {
+	QuizID    int64              `json:"quiz_id"`
+	TagID     int64              `json:"tag_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type QuizVersion struct {
+	ID              int64              `json:"id"`
+	QuizID          int64              `json:"quiz_id"`
+	Version         int32              `json:"version"`
+	Title           string             `json:"title"`
+	Description     *string            `json:"description"`
+	Visibility      QuizVisibility     `json:"visibility"`
+	MaxParticipants *int32             `json:"max_participants"`
+	Questions       []byte             `json:"questions"`
+	ContentHash     string             `json:"content_hash"`
+	Reason          string             `json:"reason"`
+	CreatedBy       *int64             `json:"created_by"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
 }
 
 type SessionParticipant struct {
@@ -282,14 +370,43 @@ type SessionParticipant struct {
 	LastActivity pgtype.Timestamptz `json:"last_activity"`
 }
 
-type User struct {
+type Tag struct {
 	ID          int64              `json:"id"`
-	Username    string             `json:"username"`
-	Email       string             `json:"email"`
-	Password    string             `json:"password"`
-	AvatarUrl   *string            `json:"avatar_url"`
-	IsActive    bool               `json:"is_active"`
+	Name        string             `json:"name"`
+	Slug        string             `json:"slug"`
+	IsCategory  bool               `json:"is_category"`
+	Description *string            `json:"description"`
+	SortOrder   int32              `json:"sort_order"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+}
+
+type User struct {
+	ID              int64              `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	Password        string             `json:"password"`
+	AvatarUrl       *string            `json:"avatar_url"`
+	IsActive        bool               `json:"is_active"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
+	EmailVerified   bool               `json:"email_verified"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	Role            UserRole           `json:"role"`
+}
+
+type UserRecoveryCode struct {
+	ID        int64              `json:"id"`
+	UserID    int64              `json:"user_id"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserID       int64              `json:"user_id"`
+	Secret       string             `json:"secret"`
+	EnabledAt    pgtype.Timestamptz `json:"enabled_at"`
+	LastUsedStep *int64             `json:"last_used_step"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 }
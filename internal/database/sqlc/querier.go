@@ -10,47 +10,107 @@ import (
 
 type Querier interface {
 	AddParticipant(ctx context.Context, arg AddParticipantParams) (SessionParticipant, error)
+	AddQuizTag(ctx context.Context, arg AddQuizTagParams) error
+	CancelSession(ctx context.Context, id int64) (int64, error)
 	CheckEmailOrUsernameExists(ctx context.Context, arg CheckEmailOrUsernameExistsParams) (CheckEmailOrUsernameExistsRow, error)
 	CheckJoinCodeExists(ctx context.Context, joinCode string) (bool, error)
+	CheckQuizSlugExists(ctx context.Context, slug *string) (bool, error)
+	CountAPIKeysByUser(ctx context.Context, userID int64) (int64, error)
+	CountLiveSessions(ctx context.Context) (int64, error)
 	CountQuestionsByQuiz(ctx context.Context, quizID int64) (int64, error)
 	CountQuizListByOwner(ctx context.Context, arg CountQuizListByOwnerParams) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+	CountUsers(ctx context.Context, arg CountUsersParams) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateIdentity(ctx context.Context, arg CreateIdentityParams) (Identity, error)
+	CreateMedia(ctx context.Context, arg CreateMediaParams) (Medium, error)
 	CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error)
 	CreateQuiz(ctx context.Context, arg CreateQuizParams) (Quiz, error)
+	CreateQuizSlugRedirect(ctx context.Context, arg CreateQuizSlugRedirectParams) error
+	CreateQuizVersion(ctx context.Context, arg CreateQuizVersionParams) (QuizVersion, error)
+	CreateRecoveryCodes(ctx context.Context, arg CreateRecoveryCodesParams) error
 	CreateSession(ctx context.Context, arg CreateSessionParams) (QuizSession, error)
+	DeleteAPIKey(ctx context.Context, arg DeleteAPIKeyParams) (int64, error)
+	DeleteAPIKeysByUser(ctx context.Context, userID int64) (int64, error)
 	DeleteQuestion(ctx context.Context, id int64) error
+	DeleteQuestionsByQuizExcept(ctx context.Context, arg DeleteQuestionsByQuizExceptParams) error
 	DeleteQuiz(ctx context.Context, id int64) error
+	DeleteQuizCollaborator(ctx context.Context, arg DeleteQuizCollaboratorParams) (int64, error)
+	DeleteQuizSlugRedirect(ctx context.Context, slug string) error
+	DeleteQuizTags(ctx context.Context, quizID int64) error
+	DeleteRecoveryCodes(ctx context.Context, userID int64) error
 	DeleteUser(ctx context.Context, id int64) error
+	DeleteUserTOTP(ctx context.Context, userID int64) error
+	EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (int64, error)
 	EndSession(ctx context.Context, id int64) error
 	FindEmailSignUp(ctx context.Context, email string) (FindEmailSignUpRow, error)
+	FindUserByUsernameOrEmail(ctx context.Context, username string) (FindUserByUsernameOrEmailRow, error)
 	FindUsernameSignUp(ctx context.Context, username string) (FindUsernameSignUpRow, error)
 	FindValidateName(ctx context.Context, arg FindValidateNameParams) (FindValidateNameRow, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (GetAPIKeyByHashRow, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (Tag, error)
 	GetCurrentQuestion(ctx context.Context, id int64) (Question, error)
+	GetIdentityByProviderSubject(ctx context.Context, arg GetIdentityByProviderSubjectParams) (Identity, error)
+	GetLatestQuizVersion(ctx context.Context, quizID int64) (QuizVersion, error)
 	GetMaxQuestionIndexByQuiz(ctx context.Context, quizID int64) (int32, error)
+	GetMediaByIDs(ctx context.Context, ids []int64) ([]Medium, error)
+	GetPopularTags(ctx context.Context, limit int32) ([]GetPopularTagsRow, error)
 	GetPublicQuizzes(ctx context.Context, arg GetPublicQuizzesParams) ([]GetPublicQuizzesRow, error)
 	GetQuestionByID(ctx context.Context, id int64) (Question, error)
 	GetQuestionByQuizAndIndex(ctx context.Context, arg GetQuestionByQuizAndIndexParams) (Question, error)
 	GetQuestionListByQuiz(ctx context.Context, quizID int64) ([]Question, error)
+	GetQuizCollaboratorRole(ctx context.Context, arg GetQuizCollaboratorRoleParams) (string, error)
+	GetQuizCollaboratorRolesByUser(ctx context.Context, arg GetQuizCollaboratorRolesByUserParams) ([]GetQuizCollaboratorRolesByUserRow, error)
+	GetQuizIDBySlug(ctx context.Context, slug *string) (int64, error)
+	GetQuizIDBySlugRedirect(ctx context.Context, slug string) (int64, error)
 	GetQuizListByOwner(ctx context.Context, arg GetQuizListByOwnerParams) ([]Quiz, error)
+	GetQuizVersion(ctx context.Context, arg GetQuizVersionParams) (QuizVersion, error)
+	GetQuizVersionByID(ctx context.Context, id int64) (QuizVersion, error)
 	GetQuizWithOwner(ctx context.Context, id int64) (GetQuizWithOwnerRow, error)
 	GetSessionByID(ctx context.Context, id int64) (GetSessionByIDRow, error)
 	GetSessionByJoinCode(ctx context.Context, joinCode string) (GetSessionByJoinCodeRow, error)
 	GetSessionLeaderboard(ctx context.Context, sessionID int64) ([]GetSessionLeaderboardRow, error)
 	GetSessionParticipants(ctx context.Context, sessionID int64) ([]SessionParticipant, error)
+	GetTagsByQuizIDs(ctx context.Context, quizIds []int64) ([]GetTagsByQuizIDsRow, error)
 	GetUserByEmailIncludePassword(ctx context.Context, email string) (User, error)
+	GetUserByIDIncludePassword(ctx context.Context, id int64) (User, error)
 	GetUserDetail(ctx context.Context, id int64) (GetUserDetailRow, error)
+	GetUserTOTP(ctx context.Context, userID int64) (UserTotp, error)
 	IncrementQuizPlayCount(ctx context.Context, id int64) error
 	IncrementQuizViewCount(ctx context.Context, id int64) error
+	ListAPIKeysByUser(ctx context.Context, userID int64) ([]ApiKey, error)
+	ListCategories(ctx context.Context) ([]ListCategoriesRow, error)
+	ListLiveSessions(ctx context.Context, arg ListLiveSessionsParams) ([]ListLiveSessionsRow, error)
+	ListQuizCollaborators(ctx context.Context, quizID int64) ([]ListQuizCollaboratorsRow, error)
+	ListQuizVersions(ctx context.Context, quizID int64) ([]ListQuizVersionsRow, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
+	LockQuiz(ctx context.Context, id int64) error
+	MakeQuizPrivate(ctx context.Context, id int64) (Quiz, error)
+	MarkUserEmailVerified(ctx context.Context, arg MarkUserEmailVerifiedParams) (int64, error)
 	RegisterAccount(ctx context.Context, arg RegisterAccountParams) (RegisterAccountRow, error)
+	SetUserActive(ctx context.Context, arg SetUserActiveParams) (SetUserActiveRow, error)
 	StartSession(ctx context.Context, id int64) error
+	TouchAPIKeyLastUsed(ctx context.Context, id int64) error
+	UpdateIdentityLogin(ctx context.Context, arg UpdateIdentityLoginParams) error
 	UpdateParticipantScore(ctx context.Context, arg UpdateParticipantScoreParams) error
 	UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error)
 	UpdateQuestionIndex(ctx context.Context, arg UpdateQuestionIndexParams) error
 	UpdateQuiz(ctx context.Context, arg UpdateQuizParams) (Quiz, error)
+	UpdateQuizOwner(ctx context.Context, arg UpdateQuizOwnerParams) error
+	UpdateQuizSlug(ctx context.Context, arg UpdateQuizSlugParams) error
 	UpdateQuizTotalQuestions(ctx context.Context, arg UpdateQuizTotalQuestionsParams) error
 	UpdateSession(ctx context.Context, arg UpdateSessionParams) (QuizSession, error)
 	UpdateSessionQuestion(ctx context.Context, arg UpdateSessionQuestionParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error)
 	UpdateUserLastLogin(ctx context.Context, id int64) (UpdateUserLastLoginRow, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) error
+	UpsertPendingUserTOTP(ctx context.Context, arg UpsertPendingUserTOTPParams) (UserTotp, error)
+	UpsertQuizCollaborator(ctx context.Context, arg UpsertQuizCollaboratorParams) (QuizCollaborator, error)
+	UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -21,18 +21,23 @@ func (q *Queries) CountQuestionsByQuiz(ctx context.Context, quizID int64) (int64
 }
 
 const createQuestion = `-- name: CreateQuestion :one
-INSERT INTO questions (quiz_id, question, type, answers, index, time_limit)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at
+INSERT INTO questions (quiz_id, question, type, answers, index, time_limit, media, content_format, explanation, reference_url, points_multiplier)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at, media, content_format, explanation, reference_url, points_multiplier
 `
 
 type CreateQuestionParams struct {
-	QuizID    int64         `json:"quiz_id"`
-	Question  string        `json:"question"`
-	Type      QuestionType  `json:"type"`
-	Answers   []byte        `json:"answers"`
-	Index     int32         `json:"index"`
-	TimeLimit TimeLimitType `json:"time_limit"`
+	QuizID           int64        `json:"quiz_id"`
+	Question         string       `json:"question"`
+	Type             QuestionType `json:"type"`
+	Answers          []byte       `json:"answers"`
+	Index            int32        `json:"index"`
+	TimeLimit        int32        `json:"time_limit"`
+	Media            []byte       `json:"media"`
+	ContentFormat    string       `json:"content_format"`
+	Explanation      *string      `json:"explanation"`
+	ReferenceUrl     *string      `json:"reference_url"`
+	PointsMultiplier int32        `json:"points_multiplier"`
 }
 
 func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error) {
@@ -43,6 +48,11 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		arg.Answers,
 		arg.Index,
 		arg.TimeLimit,
+		arg.Media,
+		arg.ContentFormat,
+		arg.Explanation,
+		arg.ReferenceUrl,
+		arg.PointsMultiplier,
 	)
 	var i Question
 	err := row.Scan(
@@ -55,6 +65,11 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		&i.Index,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Media,
+		&i.ContentFormat,
+		&i.Explanation,
+		&i.ReferenceUrl,
+		&i.PointsMultiplier,
 	)
 	return i, err
 }
@@ -68,8 +83,23 @@ func (q *Queries) DeleteQuestion(ctx context.Context, id int64) error {
 	return err
 }
 
+const deleteQuestionsByQuizExcept = `-- name: DeleteQuestionsByQuizExcept :exec
+DELETE FROM questions
+WHERE quiz_id = $1 AND NOT (id = ANY($2::bigint[]))
+`
+
+type DeleteQuestionsByQuizExceptParams struct {
+	QuizID  int64   `json:"quiz_id"`
+	KeepIds []int64 `json:"keep_ids"`
+}
+
+func (q *Queries) DeleteQuestionsByQuizExcept(ctx context.Context, arg DeleteQuestionsByQuizExceptParams) error {
+	_, err := q.db.Exec(ctx, deleteQuestionsByQuizExcept, arg.QuizID, arg.KeepIds)
+	return err
+}
+
 const getCurrentQuestion = `-- name: GetCurrentQuestion :one
-SELECT q.id, q.quiz_id, q.question, q.type, q.answers, q.time_limit, q.index, q.created_at, q.updated_at FROM questions q
+SELECT q.id, q.quiz_id, q.question, q.type, q.answers, q.time_limit, q.index, q.created_at, q.updated_at, q.media, q.content_format, q.explanation, q.reference_url, q.points_multiplier FROM questions q
 JOIN quizzes qz ON q.quiz_id = qz.id
 WHERE qz.id = $1 AND q.index = qz.current_question_index
 `
@@ -87,6 +117,11 @@ func (q *Queries) GetCurrentQuestion(ctx context.Context, id int64) (Question, e
 		&i.Index,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Media,
+		&i.ContentFormat,
+		&i.Explanation,
+		&i.ReferenceUrl,
+		&i.PointsMultiplier,
 	)
 	return i, err
 }
@@ -103,7 +138,7 @@ func (q *Queries) GetMaxQuestionIndexByQuiz(ctx context.Context, quizID int64) (
 }
 
 const getQuestionByID = `-- name: GetQuestionByID :one
-SELECT id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at FROM questions WHERE id = $1
+SELECT id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at, media, content_format, explanation, reference_url, points_multiplier FROM questions WHERE id = $1
 `
 
 func (q *Queries) GetQuestionByID(ctx context.Context, id int64) (Question, error) {
@@ -119,12 +154,17 @@ func (q *Queries) GetQuestionByID(ctx context.Context, id int64) (Question, erro
 		&i.Index,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Media,
+		&i.ContentFormat,
+		&i.Explanation,
+		&i.ReferenceUrl,
+		&i.PointsMultiplier,
 	)
 	return i, err
 }
 
 const getQuestionByQuizAndIndex = `-- name: GetQuestionByQuizAndIndex :one
-SELECT id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at FROM questions 
+SELECT id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at, media, content_format, explanation, reference_url, points_multiplier FROM questions 
 WHERE quiz_id = $1 AND index = $2
 `
 
@@ -146,12 +186,17 @@ func (q *Queries) GetQuestionByQuizAndIndex(ctx context.Context, arg GetQuestion
 		&i.Index,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Media,
+		&i.ContentFormat,
+		&i.Explanation,
+		&i.ReferenceUrl,
+		&i.PointsMultiplier,
 	)
 	return i, err
 }
 
 const getQuestionListByQuiz = `-- name: GetQuestionListByQuiz :many
-SELECT id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at FROM questions 
+SELECT id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at, media, content_format, explanation, reference_url, points_multiplier FROM questions 
 WHERE quiz_id = $1 
 ORDER BY index ASC
 `
@@ -175,6 +220,11 @@ func (q *Queries) GetQuestionListByQuiz(ctx context.Context, quizID int64) ([]Qu
 			&i.Index,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Media,
+			&i.ContentFormat,
+			&i.Explanation,
+			&i.ReferenceUrl,
+			&i.PointsMultiplier,
 		); err != nil {
 			return nil, err
 		}
@@ -188,17 +238,22 @@ func (q *Queries) GetQuestionListByQuiz(ctx context.Context, quizID int64) ([]Qu
 
 const updateQuestion = `-- name: UpdateQuestion :one
 UPDATE questions 
-SET question = $2, type = $3, answers = $4, time_limit = $5, updated_at = NOW()
+SET question = $2, type = $3, answers = $4, time_limit = $5, media = $6, content_format = $7, explanation = $8, reference_url = $9, points_multiplier = $10, updated_at = NOW()
 WHERE id = $1
-RETURNING id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at
+RETURNING id, quiz_id, question, type, answers, time_limit, index, created_at, updated_at, media, content_format, explanation, reference_url, points_multiplier
 `
 
 type UpdateQuestionParams struct {
-	ID        int64         `json:"id"`
-	Question  string        `json:"question"`
-	Type      QuestionType  `json:"type"`
-	Answers   []byte        `json:"answers"`
-	TimeLimit TimeLimitType `json:"time_limit"`
+	ID               int64        `json:"id"`
+	Question         string       `json:"question"`
+	Type             QuestionType `json:"type"`
+	Answers          []byte       `json:"answers"`
+	TimeLimit        int32        `json:"time_limit"`
+	Media            []byte       `json:"media"`
+	ContentFormat    string       `json:"content_format"`
+	Explanation      *string      `json:"explanation"`
+	ReferenceUrl     *string      `json:"reference_url"`
+	PointsMultiplier int32        `json:"points_multiplier"`
 }
 
 func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error) {
@@ -208,6 +263,11 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		arg.Type,
 		arg.Answers,
 		arg.TimeLimit,
+		arg.Media,
+		arg.ContentFormat,
+		arg.Explanation,
+		arg.ReferenceUrl,
+		arg.PointsMultiplier,
 	)
 	var i Question
 	err := row.Scan(
@@ -220,6 +280,11 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		&i.Index,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Media,
+		&i.ContentFormat,
+		&i.Explanation,
+		&i.ReferenceUrl,
+		&i.PointsMultiplier,
 	)
 	return i, err
 }
@@ -11,38 +11,63 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const checkQuizSlugExists = `-- name: CheckQuizSlugExists :one
+SELECT EXISTS(
+    SELECT 1 FROM quizzes WHERE slug = $1
+    UNION ALL
+    SELECT 1 FROM quiz_slug_redirects WHERE slug = $1
+)
+`
+
+func (q *Queries) CheckQuizSlugExists(ctx context.Context, slug *string) (bool, error) {
+	row := q.db.QueryRow(ctx, checkQuizSlugExists, slug)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const countQuizListByOwner = `-- name: CountQuizListByOwner :one
 SELECT COUNT(*) FROM quizzes
-WHERE owner_id = $1
+WHERE (owner_id = $1 OR EXISTS (
+    SELECT 1 FROM quiz_collaborators qc WHERE qc.quiz_id = quizzes.id AND qc.user_id = $1
+  ))
   AND ($2::text IS NULL OR (title ILIKE '%' || $2 || '%' OR description ILIKE '%' || $2 || '%'))
   AND ($3::quiz_visibility IS NULL OR visibility = $3)
+  AND ($4::text IS NULL OR EXISTS (
+    SELECT 1 FROM quiz_tags qt JOIN tags t ON t.id = qt.tag_id
+    WHERE qt.quiz_id = quizzes.id AND t.slug = $4::text
+  ))
 `
 
 type CountQuizListByOwnerParams struct {
 	OwnerID    int64              `json:"owner_id"`
 	Query      *string            `json:"query"`
 	Visibility NullQuizVisibility `json:"visibility"`
+	Tag        *string            `json:"tag"`
 }
 
 func (q *Queries) CountQuizListByOwner(ctx context.Context, arg CountQuizListByOwnerParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countQuizListByOwner, arg.OwnerID, arg.Query, arg.Visibility)
+	row := q.db.QueryRow(ctx, countQuizListByOwner, arg.OwnerID, arg.Query, arg.Visibility, arg.Tag)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
 const createQuiz = `-- name: CreateQuiz :one
-INSERT INTO quizzes (title, description, visibility, owner_id, max_participants)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at
+INSERT INTO quizzes (title, description, visibility, owner_id, max_participants, forked_from_quiz_id, slug)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (slug) DO NOTHING
+RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at, forked_from_quiz_id
 `
 
 type CreateQuizParams struct {
-	Title           string         `json:"title"`
-	Description     *string        `json:"description"`
-	Visibility      QuizVisibility `json:"visibility"`
-	OwnerID         int64          `json:"owner_id"`
-	MaxParticipants *int32         `json:"max_participants"`
+	Title            string         `json:"title"`
+	Description      *string        `json:"description"`
+	Visibility       QuizVisibility `json:"visibility"`
+	OwnerID          int64          `json:"owner_id"`
+	MaxParticipants  *int32         `json:"max_participants"`
+	ForkedFromQuizID *int64         `json:"forked_from_quiz_id"`
+	Slug             *string        `json:"slug"`
 }
 
 func (q *Queries) CreateQuiz(ctx context.Context, arg CreateQuizParams) (Quiz, error) {
@@ -52,6 +77,8 @@ func (q *Queries) CreateQuiz(ctx context.Context, arg CreateQuizParams) (Quiz, e
 		arg.Visibility,
 		arg.OwnerID,
 		arg.MaxParticipants,
+		arg.ForkedFromQuizID,
+		arg.Slug,
 	)
 	var i Quiz
 	err := row.Scan(
@@ -69,10 +96,27 @@ func (q *Queries) CreateQuiz(ctx context.Context, arg CreateQuizParams) (Quiz, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.PublishedAt,
+		&i.ForkedFromQuizID,
 	)
 	return i, err
 }
 
+const createQuizSlugRedirect = `-- name: CreateQuizSlugRedirect :exec
+INSERT INTO quiz_slug_redirects (slug, quiz_id)
+VALUES ($1, $2)
+ON CONFLICT (slug) DO UPDATE SET quiz_id = EXCLUDED.quiz_id, created_at = NOW()
+`
+
+type CreateQuizSlugRedirectParams struct {
+	Slug   string `json:"slug"`
+	QuizID int64  `json:"quiz_id"`
+}
+
+func (q *Queries) CreateQuizSlugRedirect(ctx context.Context, arg CreateQuizSlugRedirectParams) error {
+	_, err := q.db.Exec(ctx, createQuizSlugRedirect, arg.Slug, arg.QuizID)
+	return err
+}
+
 const deleteQuiz = `-- name: DeleteQuiz :exec
 DELETE FROM quizzes WHERE id = $1
 `
@@ -82,63 +126,95 @@ func (q *Queries) DeleteQuiz(ctx context.Context, id int64) error {
 	return err
 }
 
+const deleteQuizSlugRedirect = `-- name: DeleteQuizSlugRedirect :exec
+DELETE FROM quiz_slug_redirects WHERE slug = $1
+`
+
+func (q *Queries) DeleteQuizSlugRedirect(ctx context.Context, slug string) error {
+	_, err := q.db.Exec(ctx, deleteQuizSlugRedirect, slug)
+	return err
+}
+
 const getPublicQuizzes = `-- name: GetPublicQuizzes :many
 SELECT 
-    q.id, q.title, q.description, q.owner_id, q.visibility, q.slug, q.view_count, q.play_count, q.max_participants, q.current_question_index, q.total_questions, q.created_at, q.updated_at, q.published_at,
+    q.id, q.title, q.description, q.owner_id, q.visibility, q.slug, q.view_count, q.play_count, q.max_participants, q.current_question_index, q.total_questions, q.created_at, q.updated_at, q.published_at, q.forked_from_quiz_id,
     u.id as owner_user_id,
     u.username as owner_username,
     u.email as owner_email,
-    u.avatar_url as owner_avatar_url
+    u.avatar_url as owner_avatar_url,
+    COALESCE(ts_rank(sd.document, websearch_to_tsquery('english', $1::text)), 0)::real as relevance
 FROM quizzes q
 LEFT JOIN users u ON q.owner_id = u.id
+LEFT JOIN quiz_search_documents sd ON sd.quiz_id = q.id
 WHERE q.visibility = 'published'
-  AND ($1::text IS NULL OR (q.title ILIKE '%' || $1 || '%' OR q.description ILIKE '%' || $1 || '%'))
+  AND ($1::text IS NULL OR sd.document @@ websearch_to_tsquery('english', $1::text))
+  AND ($2::bigint IS NULL OR q.owner_id = $2::bigint)
+  AND ($3::integer IS NULL OR COALESCE(q.total_questions, 0) >= $3::integer)
+  AND ($4::integer IS NULL OR COALESCE(q.total_questions, 0) <= $4::integer)
+  AND ($5::timestamptz IS NULL OR q.created_at >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR q.created_at < $6::timestamptz)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM quiz_tags qt JOIN tags t ON t.id = qt.tag_id
+    WHERE qt.quiz_id = q.id AND t.slug = $7::text
+  ))
   AND (
-    $2::bigint IS NULL OR 
-    CASE $3::text
-      WHEN 'name_asc' THEN q.title > $4::text OR (q.title = $4::text AND q.id > $2::bigint)
-      WHEN 'name_desc' THEN q.title < $4::text OR (q.title = $4::text AND q.id > $2::bigint)
-      WHEN 'time_newest' THEN q.created_at < $5::timestamptz OR (q.created_at = $5::timestamptz AND q.id > $2::bigint)
-      WHEN 'time_oldest' THEN q.created_at > $5::timestamptz OR (q.created_at = $5::timestamptz AND q.id > $2::bigint)
-      WHEN 'view_count' THEN q.view_count < $6::integer OR (q.view_count = $6::integer AND q.id > $2::bigint)
-      WHEN 'play_count' THEN q.play_count < $7::integer OR (q.play_count = $7::integer AND q.id > $2::bigint)
-      ELSE q.created_at < $5::timestamptz OR (q.created_at = $5::timestamptz AND q.id > $2::bigint)
+    $8::bigint IS NULL OR 
+    CASE $9::text
+      WHEN 'name_asc' THEN q.title > $10::text OR (q.title = $10::text AND q.id > $8::bigint)
+      WHEN 'name_desc' THEN q.title < $10::text OR (q.title = $10::text AND q.id > $8::bigint)
+      WHEN 'time_newest' THEN q.created_at < $11::timestamptz OR (q.created_at = $11::timestamptz AND q.id > $8::bigint)
+      WHEN 'time_oldest' THEN q.created_at > $11::timestamptz OR (q.created_at = $11::timestamptz AND q.id > $8::bigint)
+      WHEN 'view_count' THEN q.view_count < $12::integer OR (q.view_count = $12::integer AND q.id > $8::bigint)
+      WHEN 'play_count' THEN q.play_count < $13::integer OR (q.play_count = $13::integer AND q.id > $8::bigint)
+      WHEN 'relevance' THEN COALESCE(ts_rank(sd.document, websearch_to_tsquery('english', $1::text)), 0)::real < $14::real
+        OR (COALESCE(ts_rank(sd.document, websearch_to_tsquery('english', $1::text)), 0)::real = $14::real AND q.id > $8::bigint)
+      ELSE q.created_at < $11::timestamptz OR (q.created_at = $11::timestamptz AND q.id > $8::bigint)
     END
   )
 ORDER BY 
-  CASE $3::text
+  CASE $9::text
     WHEN 'name_asc' THEN q.title
   END ASC,
-  CASE $3::text
+  CASE $9::text
     WHEN 'name_desc' THEN q.title
   END DESC,
-  CASE $3::text
+  CASE $9::text
     WHEN 'time_newest' THEN q.created_at
   END DESC,
-  CASE $3::text
+  CASE $9::text
     WHEN 'time_oldest' THEN q.created_at
   END ASC,
-  CASE $3::text
+  CASE $9::text
     WHEN 'view_count' THEN q.view_count
   END DESC,
-  CASE $3::text
+  CASE $9::text
     WHEN 'play_count' THEN q.play_count
   END DESC,
+  CASE $9::text
+    WHEN 'relevance' THEN COALESCE(ts_rank(sd.document, websearch_to_tsquery('english', $1::text)), 0)
+  END DESC,
   CASE 
-    WHEN $3::text IS NULL OR $3::text = '' THEN q.created_at
+    WHEN $9::text IS NULL OR $9::text = '' THEN q.created_at
   END DESC,
   q.id ASC
-LIMIT $8::integer
+LIMIT $15::integer
 `
 
 type GetPublicQuizzesParams struct {
 	Query           *string            `json:"query"`
+	OwnerID         *int64             `json:"owner_id"`
+	MinQuestions    *int32             `json:"min_questions"`
+	MaxQuestions    *int32             `json:"max_questions"`
+	CreatedFrom     pgtype.Timestamptz `json:"created_from"`
+	CreatedTo       pgtype.Timestamptz `json:"created_to"`
+	Tag             *string            `json:"tag"`
 	CursorID        *int64             `json:"cursor_id"`
 	SortBy          *string            `json:"sort_by"`
 	CursorTitle     *string            `json:"cursor_title"`
 	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
 	CursorViewCount *int32             `json:"cursor_view_count"`
 	CursorPlayCount *int32             `json:"cursor_play_count"`
+	CursorRelevance *float32           `json:"cursor_relevance"`
 	Limit           int32              `json:"limit"`
 }
 
@@ -157,21 +233,30 @@ type GetPublicQuizzesRow struct {
 	CreatedAt            pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
 	PublishedAt          pgtype.Timestamptz `json:"published_at"`
+	ForkedFromQuizID     *int64             `json:"forked_from_quiz_id"`
 	OwnerUserID          *int64             `json:"owner_user_id"`
 	OwnerUsername        *string            `json:"owner_username"`
 	OwnerEmail           *string            `json:"owner_email"`
 	OwnerAvatarUrl       *string            `json:"owner_avatar_url"`
+	Relevance            float32            `json:"relevance"`
 }
 
 func (q *Queries) GetPublicQuizzes(ctx context.Context, arg GetPublicQuizzesParams) ([]GetPublicQuizzesRow, error) {
 	rows, err := q.db.Query(ctx, getPublicQuizzes,
 		arg.Query,
+		arg.OwnerID,
+		arg.MinQuestions,
+		arg.MaxQuestions,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Tag,
 		arg.CursorID,
 		arg.SortBy,
 		arg.CursorTitle,
 		arg.CursorCreatedAt,
 		arg.CursorViewCount,
 		arg.CursorPlayCount,
+		arg.CursorRelevance,
 		arg.Limit,
 	)
 	if err != nil {
@@ -196,10 +281,12 @@ func (q *Queries) GetPublicQuizzes(ctx context.Context, arg GetPublicQuizzesPara
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.PublishedAt,
+			&i.ForkedFromQuizID,
 			&i.OwnerUserID,
 			&i.OwnerUsername,
 			&i.OwnerEmail,
 			&i.OwnerAvatarUrl,
+			&i.Relevance,
 		); err != nil {
 			return nil, err
 		}
@@ -211,11 +298,39 @@ func (q *Queries) GetPublicQuizzes(ctx context.Context, arg GetPublicQuizzesPara
 	return items, nil
 }
 
+const getQuizIDBySlug = `-- name: GetQuizIDBySlug :one
+SELECT id FROM quizzes WHERE slug = $1
+`
+
+func (q *Queries) GetQuizIDBySlug(ctx context.Context, slug *string) (int64, error) {
+	row := q.db.QueryRow(ctx, getQuizIDBySlug, slug)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getQuizIDBySlugRedirect = `-- name: GetQuizIDBySlugRedirect :one
+SELECT quiz_id FROM quiz_slug_redirects WHERE slug = $1
+`
+
+func (q *Queries) GetQuizIDBySlugRedirect(ctx context.Context, slug string) (int64, error) {
+	row := q.db.QueryRow(ctx, getQuizIDBySlugRedirect, slug)
+	var quiz_id int64
+	err := row.Scan(&quiz_id)
+	return quiz_id, err
+}
+
 const getQuizListByOwner = `-- name: GetQuizListByOwner :many
-SELECT id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at FROM quizzes 
-WHERE owner_id = $1
+SELECT id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at, forked_from_quiz_id FROM quizzes 
+WHERE (owner_id = $1 OR EXISTS (
+    SELECT 1 FROM quiz_collaborators qc WHERE qc.quiz_id = quizzes.id AND qc.user_id = $1
+  ))
   AND ($4::text IS NULL OR (title ILIKE '%' || $4 || '%' OR description ILIKE '%' || $4 || '%'))
   AND ($5::quiz_visibility IS NULL OR visibility = $5)
+  AND ($6::text IS NULL OR EXISTS (
+    SELECT 1 FROM quiz_tags qt JOIN tags t ON t.id = qt.tag_id
+    WHERE qt.quiz_id = quizzes.id AND t.slug = $6::text
+  ))
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
@@ -226,6 +341,7 @@ type GetQuizListByOwnerParams struct {
 	Offset     int32              `json:"offset"`
 	Query      *string            `json:"query"`
 	Visibility NullQuizVisibility `json:"visibility"`
+	Tag        *string            `json:"tag"`
 }
 
 func (q *Queries) GetQuizListByOwner(ctx context.Context, arg GetQuizListByOwnerParams) ([]Quiz, error) {
@@ -235,6 +351,7 @@ func (q *Queries) GetQuizListByOwner(ctx context.Context, arg GetQuizListByOwner
 		arg.Offset,
 		arg.Query,
 		arg.Visibility,
+		arg.Tag,
 	)
 	if err != nil {
 		return nil, err
@@ -258,6 +375,7 @@ func (q *Queries) GetQuizListByOwner(ctx context.Context, arg GetQuizListByOwner
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.PublishedAt,
+			&i.ForkedFromQuizID,
 		); err != nil {
 			return nil, err
 		}
@@ -271,35 +389,44 @@ func (q *Queries) GetQuizListByOwner(ctx context.Context, arg GetQuizListByOwner
 
 const getQuizWithOwner = `-- name: GetQuizWithOwner :one
 SELECT 
-    q.id, q.title, q.description, q.owner_id, q.visibility, q.slug, q.view_count, q.play_count, q.max_participants, q.current_question_index, q.total_questions, q.created_at, q.updated_at, q.published_at,
+    q.id, q.title, q.description, q.owner_id, q.visibility, q.slug, q.view_count, q.play_count, q.max_participants, q.current_question_index, q.total_questions, q.created_at, q.updated_at, q.published_at, q.forked_from_quiz_id,
     u.id as owner_user_id,
     u.username as owner_username,
     u.email as owner_email,
-    u.avatar_url as owner_avatar_url
+    u.avatar_url as owner_avatar_url,
+    fq.title as forked_from_title,
+    fq.visibility as forked_from_visibility,
+    fu.username as forked_from_owner_username
 FROM quizzes q
 LEFT JOIN users u ON q.owner_id = u.id
+LEFT JOIN quizzes fq ON q.forked_from_quiz_id = fq.id
+LEFT JOIN users fu ON fq.owner_id = fu.id
 WHERE q.id = $1
 `
 
 type GetQuizWithOwnerRow struct {
-	ID                   int64              `json:"id"`
-	Title                string             `json:"title"`
-	Description          *string            `json:"description"`
-	OwnerID              int64              `json:"owner_id"`
-	Visibility           QuizVisibility     `json:"visibility"`
-	Slug                 *string            `json:"slug"`
-	ViewCount            int32              `json:"view_count"`
-	PlayCount            int32              `json:"play_count"`
-	MaxParticipants      *int32             `json:"max_participants"`
-	CurrentQuestionIndex int32              `json:"current_question_index"`
-	TotalQuestions       *int32             `json:"total_questions"`
-	CreatedAt            pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
-	PublishedAt          pgtype.Timestamptz `json:"published_at"`
-	OwnerUserID          *int64             `json:"owner_user_id"`
-	OwnerUsername        *string            `json:"owner_username"`
-	OwnerEmail           *string            `json:"owner_email"`
-	OwnerAvatarUrl       *string            `json:"owner_avatar_url"`
+	ID                      int64              `json:"id"`
+	Title                   string             `json:"title"`
+	Description             *string            `json:"description"`
+	OwnerID                 int64              `json:"owner_id"`
+	Visibility              QuizVisibility     `json:"visibility"`
+	Slug                    *string            `json:"slug"`
+	ViewCount               int32              `json:"view_count"`
+	PlayCount               int32              `json:"play_count"`
+	MaxParticipants         *int32             `json:"max_participants"`
+	CurrentQuestionIndex    int32              `json:"current_question_index"`
+	TotalQuestions          *int32             `json:"total_questions"`
+	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt               pgtype.Timestamptz `json:"updated_at"`
+	PublishedAt             pgtype.Timestamptz `json:"published_at"`
+	ForkedFromQuizID        *int64             `json:"forked_from_quiz_id"`
+	OwnerUserID             *int64             `json:"owner_user_id"`
+	OwnerUsername           *string            `json:"owner_username"`
+	OwnerEmail              *string            `json:"owner_email"`
+	OwnerAvatarUrl          *string            `json:"owner_avatar_url"`
+	ForkedFromTitle         *string            `json:"forked_from_title"`
+	ForkedFromVisibility    NullQuizVisibility `json:"forked_from_visibility"`
+	ForkedFromOwnerUsername *string            `json:"forked_from_owner_username"`
 }
 
 func (q *Queries) GetQuizWithOwner(ctx context.Context, id int64) (GetQuizWithOwnerRow, error) {
@@ -320,10 +447,14 @@ func (q *Queries) GetQuizWithOwner(ctx context.Context, id int64) (GetQuizWithOw
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.PublishedAt,
+		&i.ForkedFromQuizID,
 		&i.OwnerUserID,
 		&i.OwnerUsername,
 		&i.OwnerEmail,
 		&i.OwnerAvatarUrl,
+		&i.ForkedFromTitle,
+		&i.ForkedFromVisibility,
+		&i.ForkedFromOwnerUsername,
 	)
 	return i, err
 }
@@ -350,11 +481,50 @@ func (q *Queries) IncrementQuizViewCount(ctx context.Context, id int64) error {
 	return err
 }
 
+const lockQuiz = `-- name: LockQuiz :exec
+SELECT id FROM quizzes WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) LockQuiz(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, lockQuiz, id)
+	return err
+}
+
+const makeQuizPrivate = `-- name: MakeQuizPrivate :one
+UPDATE quizzes
+SET visibility = 'private', published_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at, forked_from_quiz_id
+`
+
+func (q *Queries) MakeQuizPrivate(ctx context.Context, id int64) (Quiz, error) {
+	row := q.db.QueryRow(ctx, makeQuizPrivate, id)
+	var i Quiz
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.OwnerID,
+		&i.Visibility,
+		&i.Slug,
+		&i.ViewCount,
+		&i.PlayCount,
+		&i.MaxParticipants,
+		&i.CurrentQuestionIndex,
+		&i.TotalQuestions,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+		&i.ForkedFromQuizID,
+	)
+	return i, err
+}
+
 const updateQuiz = `-- name: UpdateQuiz :one
 UPDATE quizzes 
 SET title = $2, description = $3, visibility = $4, max_participants = $5, published_at = $6, updated_at = NOW()
 WHERE id = $1
-RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at
+RETURNING id, title, description, owner_id, visibility, slug, view_count, play_count, max_participants, current_question_index, total_questions, created_at, updated_at, published_at, forked_from_quiz_id
 `
 
 type UpdateQuizParams struct {
@@ -391,10 +561,43 @@ func (q *Queries) UpdateQuiz(ctx context.Context, arg UpdateQuizParams) (Quiz, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.PublishedAt,
+		&i.ForkedFromQuizID,
 	)
 	return i, err
 }
 
+const updateQuizOwner = `-- name: UpdateQuizOwner :exec
+UPDATE quizzes
+SET owner_id = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateQuizOwnerParams struct {
+	ID      int64 `json:"id"`
+	OwnerID int64 `json:"owner_id"`
+}
+
+func (q *Queries) UpdateQuizOwner(ctx context.Context, arg UpdateQuizOwnerParams) error {
+	_, err := q.db.Exec(ctx, updateQuizOwner, arg.ID, arg.OwnerID)
+	return err
+}
+
+const updateQuizSlug = `-- name: UpdateQuizSlug :exec
+UPDATE quizzes
+SET slug = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateQuizSlugParams struct {
+	ID   int64   `json:"id"`
+	Slug *string `json:"slug"`
+}
+
+func (q *Queries) UpdateQuizSlug(ctx context.Context, arg UpdateQuizSlugParams) error {
+	_, err := q.db.Exec(ctx, updateQuizSlug, arg.ID, arg.Slug)
+	return err
+}
+
 const updateQuizTotalQuestions = `-- name: UpdateQuizTotalQuestions :exec
 UPDATE quizzes 
 SET total_questions = $2, updated_at = NOW()
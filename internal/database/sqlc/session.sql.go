@@ -49,6 +49,23 @@ func (q *Queries) AddParticipant(ctx context.Context, arg AddParticipantParams)
 	return i, err
 }
 
+const cancelSession = `-- name: CancelSession :execrows
+UPDATE quiz_sessions
+SET
+    status = 'cancelled',
+    ended_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('waiting', 'active')
+`
+
+func (q *Queries) CancelSession(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelSession, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const checkJoinCodeExists = `-- name: CheckJoinCodeExists :one
 SELECT EXISTS(SELECT 1 FROM quiz_sessions WHERE join_code = $1)
 `
@@ -60,13 +77,25 @@ func (q *Queries) CheckJoinCodeExists(ctx context.Context, joinCode string) (boo
 	return exists, err
 }
 
+const countLiveSessions = `-- name: CountLiveSessions :one
+SELECT COUNT(*) FROM quiz_sessions
+WHERE status IN ('waiting', 'active')
+`
+
+func (q *Queries) CountLiveSessions(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countLiveSessions)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO quiz_sessions (
     quiz_id, host_id, join_code, status, max_participants,
-    current_question_index, participant_count
+    current_question_index, participant_count, quiz_version_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, quiz_id, host_id, join_code, status, current_question_index, max_participants, participant_count, started_at, ended_at, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, quiz_id, host_id, join_code, status, current_question_index, max_participants, participant_count, started_at, ended_at, created_at, updated_at, quiz_version_id
 `
 
 type CreateSessionParams struct {
@@ -77,6 +106,7 @@ type CreateSessionParams struct {
 	MaxParticipants      *int32        `json:"max_participants"`
 	CurrentQuestionIndex int32         `json:"current_question_index"`
 	ParticipantCount     int32         `json:"participant_count"`
+	QuizVersionID        *int64        `json:"quiz_version_id"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (QuizSession, error) {
@@ -88,6 +118,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Q
 		arg.MaxParticipants,
 		arg.CurrentQuestionIndex,
 		arg.ParticipantCount,
+		arg.QuizVersionID,
 	)
 	var i QuizSession
 	err := row.Scan(
@@ -103,6 +134,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Q
 		&i.EndedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.QuizVersionID,
 	)
 	return i, err
 }
@@ -123,7 +155,7 @@ func (q *Queries) EndSession(ctx context.Context, id int64) error {
 
 const getSessionByID = `-- name: GetSessionByID :one
 SELECT 
-    s.id, s.quiz_id, s.host_id, s.join_code, s.status, s.current_question_index, s.max_participants, s.participant_count, s.started_at, s.ended_at, s.created_at, s.updated_at,
+    s.id, s.quiz_id, s.host_id, s.join_code, s.status, s.current_question_index, s.max_participants, s.participant_count, s.started_at, s.ended_at, s.created_at, s.updated_at, s.quiz_version_id,
     q.title as quiz_title,
     q.description as quiz_description,
     q.total_questions as quiz_total_questions
@@ -145,6 +177,7 @@ type GetSessionByIDRow struct {
 	EndedAt              pgtype.Timestamptz `json:"ended_at"`
 	CreatedAt            pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
+	QuizVersionID        *int64             `json:"quiz_version_id"`
 	QuizTitle            string             `json:"quiz_title"`
 	QuizDescription      *string            `json:"quiz_description"`
 	QuizTotalQuestions   *int32             `json:"quiz_total_questions"`
@@ -166,6 +199,7 @@ func (q *Queries) GetSessionByID(ctx context.Context, id int64) (GetSessionByIDR
 		&i.EndedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.QuizVersionID,
 		&i.QuizTitle,
 		&i.QuizDescription,
 		&i.QuizTotalQuestions,
@@ -175,7 +209,7 @@ func (q *Queries) GetSessionByID(ctx context.Context, id int64) (GetSessionByIDR
 
 const getSessionByJoinCode = `-- name: GetSessionByJoinCode :one
 SELECT 
-    s.id, s.quiz_id, s.host_id, s.join_code, s.status, s.current_question_index, s.max_participants, s.participant_count, s.started_at, s.ended_at, s.created_at, s.updated_at,
+    s.id, s.quiz_id, s.host_id, s.join_code, s.status, s.current_question_index, s.max_participants, s.participant_count, s.started_at, s.ended_at, s.created_at, s.updated_at, s.quiz_version_id,
     q.title as quiz_title,
     q.description as quiz_description,
     q.total_questions as quiz_total_questions
@@ -197,6 +231,7 @@ type GetSessionByJoinCodeRow struct {
 	EndedAt              pgtype.Timestamptz `json:"ended_at"`
 	CreatedAt            pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
+	QuizVersionID        *int64             `json:"quiz_version_id"`
 	QuizTitle            string             `json:"quiz_title"`
 	QuizDescription      *string            `json:"quiz_description"`
 	QuizTotalQuestions   *int32             `json:"quiz_total_questions"`
@@ -218,6 +253,7 @@ func (q *Queries) GetSessionByJoinCode(ctx context.Context, joinCode string) (Ge
 		&i.EndedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.QuizVersionID,
 		&i.QuizTitle,
 		&i.QuizDescription,
 		&i.QuizTotalQuestions,
@@ -306,6 +342,78 @@ func (q *Queries) GetSessionParticipants(ctx context.Context, sessionID int64) (
 	return items, nil
 }
 
+const listLiveSessions = `-- name: ListLiveSessions :many
+SELECT
+    s.id, s.quiz_id, s.host_id, s.join_code, s.status, s.current_question_index, s.max_participants, s.participant_count, s.started_at, s.ended_at, s.created_at, s.updated_at, s.quiz_version_id,
+    q.title as quiz_title,
+    u.username as host_username
+FROM quiz_sessions s
+JOIN quizzes q ON s.quiz_id = q.id
+LEFT JOIN users u ON s.host_id = u.id
+WHERE s.status IN ('waiting', 'active')
+ORDER BY s.created_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListLiveSessionsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListLiveSessionsRow struct {
+	ID                   int64              `json:"id"`
+	QuizID               int64              `json:"quiz_id"`
+	HostID               *int64             `json:"host_id"`
+	JoinCode             string             `json:"join_code"`
+	Status               SessionStatus      `json:"status"`
+	CurrentQuestionIndex int32              `json:"current_question_index"`
+	MaxParticipants      *int32             `json:"max_participants"`
+	ParticipantCount     int32              `json:"participant_count"`
+	StartedAt            pgtype.Timestamptz `json:"started_at"`
+	EndedAt              pgtype.Timestamptz `json:"ended_at"`
+	CreatedAt            pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
+	QuizVersionID        *int64             `json:"quiz_version_id"`
+	QuizTitle            string             `json:"quiz_title"`
+	HostUsername         *string            `json:"host_username"`
+}
+
+func (q *Queries) ListLiveSessions(ctx context.Context, arg ListLiveSessionsParams) ([]ListLiveSessionsRow, error) {
+	rows, err := q.db.Query(ctx, listLiveSessions, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLiveSessionsRow{}
+	for rows.Next() {
+		var i ListLiveSessionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.QuizID,
+			&i.HostID,
+			&i.JoinCode,
+			&i.Status,
+			&i.CurrentQuestionIndex,
+			&i.MaxParticipants,
+			&i.ParticipantCount,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.QuizVersionID,
+			&i.QuizTitle,
+			&i.HostUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const startSession = `-- name: StartSession :exec
 UPDATE quiz_sessions 
 SET 
@@ -349,7 +457,7 @@ SET
     ended_at = $7,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, quiz_id, host_id, join_code, status, current_question_index, max_participants, participant_count, started_at, ended_at, created_at, updated_at
+RETURNING id, quiz_id, host_id, join_code, status, current_question_index, max_participants, participant_count, started_at, ended_at, created_at, updated_at, quiz_version_id
 `
 
 type UpdateSessionParams struct {
@@ -386,6 +494,7 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (Q
 		&i.EndedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.QuizVersionID,
 	)
 	return i, err
 }
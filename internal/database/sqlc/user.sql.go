@@ -11,6 +11,26 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE ($1::text IS NULL OR (username ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%'))
+  AND ($2::boolean IS NULL OR is_active = $2)
+  AND ($3::user_role IS NULL OR role = $3)
+`
+
+type CountUsersParams struct {
+	Query    *string      `json:"query"`
+	IsActive *bool        `json:"is_active"`
+	Role     NullUserRole `json:"role"`
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, arg.Query, arg.IsActive, arg.Role)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM users WHERE id = $1
 `
@@ -20,6 +40,34 @@ func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
 	return err
 }
 
+const findUserByUsernameOrEmail = `-- name: FindUserByUsernameOrEmail :one
+SELECT id, username, email, avatar_url, is_active
+FROM users
+WHERE username = $1 OR LOWER(email) = LOWER($1)
+LIMIT 1
+`
+
+type FindUserByUsernameOrEmailRow struct {
+	ID        int64   `json:"id"`
+	Username  string  `json:"username"`
+	Email     string  `json:"email"`
+	AvatarUrl *string `json:"avatar_url"`
+	IsActive  bool    `json:"is_active"`
+}
+
+func (q *Queries) FindUserByUsernameOrEmail(ctx context.Context, username string) (FindUserByUsernameOrEmailRow, error) {
+	row := q.db.QueryRow(ctx, findUserByUsernameOrEmail, username)
+	var i FindUserByUsernameOrEmailRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.AvatarUrl,
+		&i.IsActive,
+	)
+	return i, err
+}
+
 const findValidateName = `-- name: FindValidateName :one
 SELECT id, username
 FROM users
@@ -46,19 +94,22 @@ func (q *Queries) FindValidateName(ctx context.Context, arg FindValidateNamePara
 }
 
 const getUserDetail = `-- name: GetUserDetail :one
-SELECT id, username, email, is_active, created_at, updated_at, last_login_at
+SELECT id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role
 FROM users
 WHERE id = $1
 `
 
 type GetUserDetailRow struct {
-	ID          int64              `json:"id"`
-	Username    string             `json:"username"`
-	Email       string             `json:"email"`
-	IsActive    bool               `json:"is_active"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+	ID              int64              `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	IsActive        bool               `json:"is_active"`
+	EmailVerified   bool               `json:"email_verified"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
+	Role            UserRole           `json:"role"`
 }
 
 func (q *Queries) GetUserDetail(ctx context.Context, id int64) (GetUserDetailRow, error) {
@@ -69,9 +120,125 @@ func (q *Queries) GetUserDetail(ctx context.Context, id int64) (GetUserDetailRow
 		&i.Username,
 		&i.Email,
 		&i.IsActive,
+		&i.EmailVerified,
+		&i.EmailVerifiedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastLoginAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, username, email, avatar_url, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role
+FROM users
+WHERE ($3::text IS NULL OR (username ILIKE '%' || $3 || '%' OR email ILIKE '%' || $3 || '%'))
+  AND ($4::boolean IS NULL OR is_active = $4)
+  AND ($5::user_role IS NULL OR role = $5)
+ORDER BY created_at DESC, id DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListUsersParams struct {
+	Limit    int32        `json:"limit"`
+	Offset   int32        `json:"offset"`
+	Query    *string      `json:"query"`
+	IsActive *bool        `json:"is_active"`
+	Role     NullUserRole `json:"role"`
+}
+
+type ListUsersRow struct {
+	ID              int64              `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	AvatarUrl       *string            `json:"avatar_url"`
+	IsActive        bool               `json:"is_active"`
+	EmailVerified   bool               `json:"email_verified"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
+	Role            UserRole           `json:"role"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Limit,
+		arg.Offset,
+		arg.Query,
+		arg.IsActive,
+		arg.Role,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersRow{}
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.AvatarUrl,
+			&i.IsActive,
+			&i.EmailVerified,
+			&i.EmailVerifiedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastLoginAt,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserActive = `-- name: SetUserActive :one
+UPDATE users
+SET is_active = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role
+`
+
+type SetUserActiveParams struct {
+	ID       int64 `json:"id"`
+	IsActive bool  `json:"is_active"`
+}
+
+type SetUserActiveRow struct {
+	ID              int64              `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	IsActive        bool               `json:"is_active"`
+	EmailVerified   bool               `json:"email_verified"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
+	Role            UserRole           `json:"role"`
+}
+
+func (q *Queries) SetUserActive(ctx context.Context, arg SetUserActiveParams) (SetUserActiveRow, error) {
+	row := q.db.QueryRow(ctx, setUserActive, arg.ID, arg.IsActive)
+	var i SetUserActiveRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.IsActive,
+		&i.EmailVerified,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastLoginAt,
+		&i.Role,
 	)
 	return i, err
 }
@@ -80,7 +247,7 @@ const updateUser = `-- name: UpdateUser :one
 UPDATE users 
 SET username = $2, updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, email, is_active, created_at, updated_at, last_login_at
+RETURNING id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role
 `
 
 type UpdateUserParams struct {
@@ -89,13 +256,16 @@ type UpdateUserParams struct {
 }
 
 type UpdateUserRow struct {
-	ID          int64              `json:"id"`
-	Username    string             `json:"username"`
-	Email       string             `json:"email"`
-	IsActive    bool               `json:"is_active"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+	ID              int64              `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	IsActive        bool               `json:"is_active"`
+	EmailVerified   bool               `json:"email_verified"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
+	Role            UserRole           `json:"role"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error) {
@@ -106,9 +276,12 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 		&i.Username,
 		&i.Email,
 		&i.IsActive,
+		&i.EmailVerified,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastLoginAt,
+		&i.Role,
 	)
 	return i, err
 }
@@ -117,17 +290,20 @@ const updateUserLastLogin = `-- name: UpdateUserLastLogin :one
 UPDATE users 
 SET last_login_at = NOW(), updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, email, is_active, created_at, updated_at, last_login_at
+RETURNING id, username, email, is_active, email_verified, email_verified_at, created_at, updated_at, last_login_at, role
 `
 
 type UpdateUserLastLoginRow struct {
-	ID          int64              `json:"id"`
-	Username    string             `json:"username"`
-	Email       string             `json:"email"`
-	IsActive    bool               `json:"is_active"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+	ID              int64              `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	IsActive        bool               `json:"is_active"`
+	EmailVerified   bool               `json:"email_verified"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
+	Role            UserRole           `json:"role"`
 }
 
 func (q *Queries) UpdateUserLastLogin(ctx context.Context, id int64) (UpdateUserLastLoginRow, error) {
@@ -138,9 +314,12 @@ func (q *Queries) UpdateUserLastLogin(ctx context.Context, id int64) (UpdateUser
 		&i.Username,
 		&i.Email,
 		&i.IsActive,
+		&i.EmailVerified,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastLoginAt,
+		&i.Role,
 	)
 	return i, err
 }
@@ -6,6 +6,8 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
 )
 
 type Transaction[T any] struct {
@@ -16,7 +18,14 @@ func NewTransaction[T any](pool *pgxpool.Pool) *Transaction[T] {
 	return &Transaction[T]{pool}
 }
 
+// Execute runs f inside a single database transaction carried by the context under KEY_CURRENT_TRAN.
+// Repositories pick it up through QueriesFromContext, so every statement issued with the given
+// context commits or rolls back together. When ctx already carries a transaction, f joins it.
 func (t *Transaction[T]) Execute(ctx context.Context, f func(context.Context) (*T, error)) (*T, error) {
+	if _, ok := TxFromContext(ctx); ok {
+		return f(ctx)
+	}
+
 	slog.Info("[Transaction]*****Begin*****")
 	tx, err := t.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
@@ -35,7 +44,7 @@ func (t *Transaction[T]) Execute(ctx context.Context, f func(context.Context) (*
 
 	slog.Info("[Transaction]--executing...")
 
-	result, err := f(ctx)
+	result, err := f(context.WithValue(ctx, constants.KEY_CURRENT_TRAN, tx))
 	if err != nil {
 		slog.Info("[Transaction]--rollback to release locked by tran", slog.Any("err", err))
 		tx.Rollback(ctx)
@@ -49,3 +58,16 @@ func (t *Transaction[T]) Execute(ctx context.Context, f func(context.Context) (*
 
 	return result, nil
 }
+
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(constants.KEY_CURRENT_TRAN).(pgx.Tx)
+	return tx, ok
+}
+
+// QueriesFromContext binds queries to the transaction carried by ctx, if any
+func QueriesFromContext(ctx context.Context, queries *sqlc.Queries) *sqlc.Queries {
+	if tx, ok := TxFromContext(ctx); ok {
+		return queries.WithTx(tx)
+	}
+	return queries
+}
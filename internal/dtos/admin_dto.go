@@ -0,0 +1,59 @@
+package dtos
+
+import (
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/response"
+)
+
+type AdminListUsersRequest struct {
+	Query    *string          `query:"query" validate:"omitempty,max=100"` // Username or email
+	IsActive *bool            `query:"is_active"`
+	Role     *models.UserRole `query:"role" validate:"omitempty,oneof='' player admin"`
+	Page     int32            `query:"page" validate:"min=1"`
+	Limit    int32            `query:"limit" validate:"min=1,max=100"`
+}
+
+type AdminListUsersResponse struct {
+	Users      []*models.User            `json:"users"`
+	Pagination response.OffsetPagination `json:"pagination"`
+}
+
+type AdminDeactivateUserRequest struct {
+	UserID int64  `params:"user_id" validate:"required"`
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+type AdminReactivateUserRequest struct {
+	UserID int64  `params:"user_id" validate:"required"`
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+type AdminTakeDownQuizRequest struct {
+	QuizID int64  `params:"quiz_id" validate:"required"`
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+type AdminDeleteQuizRequest struct {
+	QuizID int64  `params:"quiz_id" validate:"required"`
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+type AdminListSessionsRequest struct {
+	Page  int32 `query:"page" validate:"min=1"`
+	Limit int32 `query:"limit" validate:"min=1,max=100"`
+}
+
+type AdminLiveSession struct {
+	*models.QuizSession
+	ConnectedClients int `json:"connected_clients"` // Sockets in the room on this instance
+}
+
+type AdminListSessionsResponse struct {
+	Sessions   []*AdminLiveSession       `json:"sessions"`
+	Pagination response.OffsetPagination `json:"pagination"`
+}
+
+type AdminEndSessionRequest struct {
+	SessionID int64  `params:"session_id" validate:"required"`
+	Reason    string `json:"reason" validate:"omitempty,max=500"`
+}
@@ -0,0 +1,23 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string               `json:"name" validate:"required,min=1,max=100"`
+	Scopes    []models.APIKeyScope `json:"scopes" validate:"required,min=1,unique,dive,oneof=quiz:read quiz:write session:read session:write"`
+	ExpiresAt *time.Time           `json:"expires_at"` // Never expires when omitted
+}
+
+// CreateAPIKeyResponse carries the only copy of the key the server will ever return
+type CreateAPIKeyResponse struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+type DeleteAPIKeyRequest struct {
+	APIKeyID int64 `params:"api_key_id" validate:"required"`
+}
@@ -1,14 +1,47 @@
 package dtos
 
+import "github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+
 type SignUpRequest struct {
 	Username string `json:"username" validate:"required,min=1,max=50"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6,max=100"`
+
+	Device DeviceInfo `json:"-"`
 }
 
 type SignInRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6,max=100"`
+
+	Device DeviceInfo `json:"-"`
+}
+
+// DeviceInfo describes the client signing in, taken from the request rather than the body
+type DeviceInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6,max=100"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type RevokeAuthSessionRequest struct {
+	SessionID string `params:"session_id" validate:"required,uuid"`
 }
 
 type SignUpConflictResult struct {
@@ -18,10 +51,16 @@ type SignUpConflictResult struct {
 
 // User Session data for caching
 type UserSession struct {
-	UserID    int64  `json:"user_id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	IsActive  bool   `json:"is_active"`
-	LoginIP   string `json:"login_ip,omitempty"`
-	UserAgent string `json:"user_agent,omitempty"`
+	UserID    int64           `json:"user_id"`
+	SessionID string          `json:"session_id,omitempty"`
+	Username  string          `json:"username"`
+	Email     string          `json:"email"`
+	IsActive  bool            `json:"is_active"`
+	Role      models.UserRole `json:"role"`
+	LoginIP   string          `json:"login_ip,omitempty"`
+	UserAgent string          `json:"user_agent,omitempty"`
+	// TokenID is the jti of the token the request was authenticated with
+	TokenID string `json:"-"`
+	// APIKeyID is set instead of SessionID and TokenID when the request used a personal API key
+	APIKeyID int64 `json:"-"`
 }
@@ -0,0 +1,7 @@
+package dtos
+
+// UploadMediaRequest is filled by the handler from the multipart "file" field
+type UploadMediaRequest struct {
+	FileName string
+	Content  []byte
+}
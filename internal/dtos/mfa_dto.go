@@ -0,0 +1,40 @@
+package dtos
+
+type MFAStatusResponse struct {
+	TOTPEnabled            bool  `json:"totp_enabled"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+type EnrollTOTPResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"` // Render as a QR code for authenticator apps
+}
+
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFACodeRequest takes a code from the authenticator app or an unused recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+
+	Device DeviceInfo `json:"-"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Shown once; only hashes are kept
+}
+
+// SignInResponse holds the token pair, or a challenge when the account needs a second factor
+type SignInResponse struct {
+	*TokenResponse
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+}
+
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+
+	Device DeviceInfo `json:"-"`
+}
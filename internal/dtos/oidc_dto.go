@@ -0,0 +1,50 @@
+package dtos
+
+type OIDCProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type OIDCLoginRequest struct {
+	Provider string `params:"provider" validate:"required,max=50"`
+}
+
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// OIDCCallbackRequest carries what the provider redirected back with
+type OIDCCallbackRequest struct {
+	Provider string `params:"provider" validate:"required,max=50"`
+	Code     string `json:"code" validate:"required"`
+	State    string `json:"state" validate:"required"`
+
+	Device DeviceInfo `json:"-"`
+}
+
+// Cached between the redirect to the provider and its callback, keyed by state
+type CachedOIDCLogin struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// OIDCClaims are the ID token claims used to find or create the user
+type OIDCClaims struct {
+	Subject           string      `json:"sub"`
+	Email             string      `json:"email"`
+	EmailVerified     interface{} `json:"email_verified"` // Some providers send "true" instead of true
+	PreferredUsername string      `json:"preferred_username"`
+	Name              string      `json:"name"`
+}
+
+func (c *OIDCClaims) IsEmailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
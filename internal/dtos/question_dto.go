@@ -3,19 +3,29 @@ package dtos
 import "github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 
 type CreateQuestionRequest struct {
-	QuizID    int64                `json:"quiz_id" validate:"required"`
-	Question  string               `json:"question" validate:"required,min=1,max=100"`
-	Type      models.QuestionType  `json:"type" validate:"required,oneof=single_choice multiple_choice_checkbox text_input"`
-	Answers   []models.AnswerData  `json:"answers"`
-	TimeLimit models.TimeLimitType `json:"time_limit" validate:"oneof=5 10 20 45 80"` // Predefined time limits
+	QuizID           int64                   `json:"quiz_id" validate:"required"`
+	Question         string                  `json:"question" validate:"required,min=1,max=2000"`
+	ContentFormat    models.ContentFormat    `json:"content_format" validate:"omitempty,oneof=plain markdown"` // Defaults to plain
+	Type             models.QuestionType     `json:"type" validate:"required,oneof=single_choice multiple_choice text_input"`
+	Answers          []models.AnswerData     `json:"answers"`
+	TimeLimit        int32                   `json:"time_limit" validate:"omitempty,min=1"`              // Seconds within the configured bounds, omit for the default
+	PointsMultiplier *int32                  `json:"points_multiplier" validate:"omitempty,oneof=0 1 2"` // Defaults to 1, 2 for double points
+	Media            *models.MediaAttachment `json:"media"`                                              // Uploaded image or audio shown with the question
+	Explanation      *string                 `json:"explanation" validate:"omitempty,max=2000"`          // Markdown, revealed once the question closes
+	ReferenceURL     *string                 `json:"reference_url" validate:"omitempty,http_url,max=2048"`
 }
 
 type UpdateQuestionRequest struct {
-	QuestionID int64                `params:"question_id" validate:"required"`
-	Question   string               `json:"question" validate:"required,min=1,max=100"`
-	Type       models.QuestionType  `json:"type" validate:"required,oneof=single_choice multiple_choice_checkbox text_input"`
-	Answers    []models.AnswerData  `json:"answers"`
-	TimeLimit  models.TimeLimitType `json:"time_limit" validate:"oneof=5 10 20 45 80"` // Predefined time limits
+	QuestionID       int64                   `params:"question_id" validate:"required"`
+	Question         string                  `json:"question" validate:"required,min=1,max=2000"`
+	ContentFormat    models.ContentFormat    `json:"content_format" validate:"omitempty,oneof=plain markdown"` // Defaults to plain
+	Type             models.QuestionType     `json:"type" validate:"required,oneof=single_choice multiple_choice text_input"`
+	Answers          []models.AnswerData     `json:"answers"`
+	TimeLimit        int32                   `json:"time_limit" validate:"omitempty,min=1"`              // Seconds within the configured bounds, omit for the default
+	PointsMultiplier *int32                  `json:"points_multiplier" validate:"omitempty,oneof=0 1 2"` // Defaults to 1, 2 for double points
+	Media            *models.MediaAttachment `json:"media"`                                              // Uploaded image or audio shown with the question
+	Explanation      *string                 `json:"explanation" validate:"omitempty,max=2000"`          // Markdown, revealed once the question closes
+	ReferenceURL     *string                 `json:"reference_url" validate:"omitempty,http_url,max=2048"`
 }
 
 type QuestionIndexesPayload struct {
@@ -24,9 +34,35 @@ type QuestionIndexesPayload struct {
 }
 
 type UpdateQuestionIndexRequest struct {
+	QuizID  int64                    `json:"quiz_id" validate:"required"`
 	Indexes []QuestionIndexesPayload `json:"indexes" validate:"required,dive"`
 }
 
 type DeleteQuestionRequest struct {
 	QuestionID int64 `params:"question_id" validate:"required"`
 }
+
+type BulkQuestionPayload struct {
+	QuestionID       *int64                  `json:"question_id"` // Omit to create a new question
+	Question         string                  `json:"question" validate:"required,min=1,max=2000"`
+	ContentFormat    models.ContentFormat    `json:"content_format" validate:"omitempty,oneof=plain markdown"` // Defaults to plain
+	Type             models.QuestionType     `json:"type" validate:"required,oneof=single_choice multiple_choice text_input"`
+	Answers          []models.AnswerData     `json:"answers"`
+	TimeLimit        int32                   `json:"time_limit" validate:"omitempty,min=1"`              // Seconds within the configured bounds, omit for the default
+	PointsMultiplier *int32                  `json:"points_multiplier" validate:"omitempty,oneof=0 1 2"` // Defaults to 1, 2 for double points
+	Media            *models.MediaAttachment `json:"media"`                                              // Uploaded image or audio shown with the question
+	Explanation      *string                 `json:"explanation" validate:"omitempty,max=2000"`          // Markdown, revealed once the question closes
+	ReferenceURL     *string                 `json:"reference_url" validate:"omitempty,http_url,max=2048"`
+}
+
+// BulkUpsertQuestionsRequest replaces the quiz's question list with the given ordered list.
+// Existing questions missing from the list are deleted.
+type BulkUpsertQuestionsRequest struct {
+	QuizID    int64                 `json:"quiz_id" validate:"required"`
+	Questions []BulkQuestionPayload `json:"questions" validate:"required,min=1,max=200"`
+}
+
+type BulkQuestionError struct {
+	Index  int               `json:"index"`
+	Errors map[string]string `json:"errors"`
+}
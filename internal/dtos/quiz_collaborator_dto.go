@@ -0,0 +1,29 @@
+package dtos
+
+import "github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+
+type ListQuizCollaboratorsRequest struct {
+	QuizID int64 `params:"quiz_id" validate:"required"`
+}
+
+type InviteQuizCollaboratorRequest struct {
+	QuizID     int64           `params:"quiz_id" validate:"required"`
+	Identifier string          `json:"identifier" validate:"required,max=255"` // Username or email of the user to invite
+	Role       models.QuizRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type UpdateQuizCollaboratorRequest struct {
+	QuizID int64           `params:"quiz_id" validate:"required"`
+	UserID int64           `params:"user_id" validate:"required"`
+	Role   models.QuizRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type RemoveQuizCollaboratorRequest struct {
+	QuizID int64 `params:"quiz_id" validate:"required"`
+	UserID int64 `params:"user_id" validate:"required"`
+}
+
+type TransferQuizOwnershipRequest struct {
+	QuizID int64 `params:"quiz_id" validate:"required"`
+	UserID int64 `json:"user_id" validate:"required"` // Must already be a collaborator
+}
@@ -20,11 +20,13 @@ type UpdateQuizRequest struct {
 	Description     *string               `json:"description" validate:"omitempty,max=1000"`
 	MaxParticipants int32                 `json:"max_participants" validate:"min=1,max=1000"`
 	Visibility      models.QuizVisibility `json:"visibility" validate:"required,oneof=private unlisted published"`
+	Slug            *string               `json:"slug" validate:"omitempty,min=3,max=100,slug"`
 }
 
 type GetMyQuizListRequest struct {
 	Query      *string                `query:"query"`
 	Visibility *models.QuizVisibility `query:"visibility" validate:"omitempty,oneof='' private unlisted published"`
+	Tag        *string                `query:"tag" validate:"omitempty,max=60"` // Tag or category slug
 	Page       int32                  `query:"page" validate:"min=1"`
 	Limit      int32                  `query:"limit" validate:"min=1,max=100"`
 }
@@ -34,10 +36,16 @@ type DeleteQuizRequest struct {
 }
 
 type GetQuizListRequest struct {
-	Query  *string `query:"query"`
-	Cursor *string `query:"cursor"`
-	Limit  int32   `query:"limit" validate:"min=1,max=100"`
-	SortBy *string `query:"sort_by" validate:"omitempty,oneof='' name_asc name_desc time_newest time_oldest view_count play_count"`
+	Query        *string `query:"query"`
+	Cursor       *string `query:"cursor"`
+	Limit        int32   `query:"limit" validate:"min=1,max=100"`
+	SortBy       *string `query:"sort_by" validate:"omitempty,oneof='' name_asc name_desc time_newest time_oldest view_count play_count relevance"`
+	OwnerID      *int64  `query:"owner_id" validate:"omitempty,min=1"`
+	MinQuestions *int32  `query:"min_questions" validate:"omitempty,min=0"`
+	MaxQuestions *int32  `query:"max_questions" validate:"omitempty,min=0"`
+	CreatedFrom  *string `query:"created_from" validate:"omitempty,date"` // YYYY-MM-DD, inclusive
+	CreatedTo    *string `query:"created_to" validate:"omitempty,date"`   // YYYY-MM-DD, inclusive
+	Tag          *string `query:"tag" validate:"omitempty,max=60"`        // Tag or category slug
 }
 
 type GetQuizListResponse struct {
@@ -49,3 +57,47 @@ type GetQuizListResponse struct {
 type GetQuizDetailRequest struct {
 	QuizID int64 `params:"quiz_id" validate:"required"`
 }
+
+type GetQuizBySlugRequest struct {
+	Slug string `params:"slug" validate:"required,max=100,slug"`
+}
+
+type ExportQuizRequest struct {
+	QuizID int64  `params:"quiz_id" validate:"required"`
+	Format string `query:"format" validate:"required,oneof=json csv gift moodle_xml aiken"`
+}
+
+type ExportQuizResponse struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+type ImportQuizRequest struct {
+	Format  string  `json:"format" form:"format" validate:"required,oneof=json csv gift moodle_xml aiken"`
+	Title   *string `json:"title" form:"title" validate:"omitempty,min=3,max=255"`
+	Content string  `json:"content" form:"content"` // Raw file content; multipart uploads fill it from the "file" field
+}
+
+type ImportQuestionError struct {
+	Row    int               `json:"row"`
+	Errors map[string]string `json:"errors"`
+}
+
+type ForkQuizRequest struct {
+	QuizID int64   `params:"quiz_id" validate:"required"`
+	Title  *string `json:"title" validate:"omitempty,min=3,max=255"`
+}
+
+type LintQuizRequest struct {
+	QuizID int64 `params:"quiz_id" validate:"required"`
+}
+
+type PublishQuizRequest struct {
+	QuizID int64 `params:"quiz_id" validate:"required"`
+}
+
+type PublishQuizResponse struct {
+	Quiz *models.Quiz           `json:"quiz"`
+	Lint *models.QuizLintReport `json:"lint"` // Warnings only, errors block publishing
+}
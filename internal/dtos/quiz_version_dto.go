@@ -0,0 +1,21 @@
+package dtos
+
+type ListQuizVersionsRequest struct {
+	QuizID int64 `params:"quiz_id" validate:"required"`
+}
+
+type GetQuizVersionRequest struct {
+	QuizID  int64 `params:"quiz_id" validate:"required"`
+	Version int32 `params:"version" validate:"required,min=1"`
+}
+
+type DiffQuizVersionsRequest struct {
+	QuizID int64 `params:"quiz_id" validate:"required"`
+	From   int32 `query:"from" validate:"required,min=1"`
+	To     int32 `query:"to" validate:"required,min=1"`
+}
+
+type RestoreQuizVersionRequest struct {
+	QuizID  int64 `params:"quiz_id" validate:"required"`
+	Version int32 `params:"version" validate:"required,min=1"`
+}
@@ -1,5 +1,11 @@
 package dtos
 
+import (
+	"time"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
 type CreateSessionRequest struct {
 	QuizID int64 `json:"quiz_id" validate:"required,min=1"`
 }
@@ -18,3 +24,16 @@ type CreateSessionResponse struct {
 type JoinSessionRequest struct {
 	JoinCode string `params:"join_code" validate:"required,len=6"`
 }
+
+type GetSessionReviewRequest struct {
+	JoinCode string `params:"join_code" validate:"required,len=6"`
+}
+
+// SessionReviewResponse lists every question of a finished game with its correct answers and explanation
+type SessionReviewResponse struct {
+	SessionID int64                    `json:"session_id"`
+	QuizID    int64                    `json:"quiz_id"`
+	QuizTitle string                   `json:"quiz_title"`
+	EndedAt   time.Time                `json:"ended_at"`
+	Questions []*models.QuestionReview `json:"questions"`
+}
@@ -0,0 +1,25 @@
+package dtos
+
+import "github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+
+type SetQuizTagsRequest struct {
+	QuizID int64    `params:"quiz_id" validate:"required"`
+	Tags   []string `json:"tags" validate:"max=10,dive,required,max=50"` // Tag names; a name matching a category assigns it
+}
+
+type GetCategoryQuizzesRequest struct {
+	Slug   string  `params:"slug" validate:"required,max=60"`
+	Query  *string `query:"query"`
+	Cursor *string `query:"cursor"`
+	Limit  int32   `query:"limit" validate:"min=1,max=100"`
+	SortBy *string `query:"sort_by" validate:"omitempty,oneof='' name_asc name_desc time_newest time_oldest view_count play_count relevance"`
+}
+
+type GetCategoryQuizzesResponse struct {
+	Category *models.Tag `json:"category"`
+	*GetQuizListResponse
+}
+
+type GetPopularTagsRequest struct {
+	Limit int32 `query:"limit" validate:"omitempty,min=1,max=50"`
+}
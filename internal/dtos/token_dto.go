@@ -2,6 +2,7 @@ package dtos
 
 import (
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 )
 
 type TokenResponse struct {
@@ -16,3 +17,31 @@ type JWTClaims struct {
 	Type        string      `json:"type"` // "access" or "refresh"
 	jwt.RegisteredClaims
 }
+
+// Cached login session. It is also the refresh token family: only the latest token pair issued
+// for it is accepted, and presenting an older refresh token revokes the whole session.
+type CachedAuthSession struct {
+	Session        models.AuthSession `json:"session"`
+	UserSession    UserSession        `json:"user_session"`
+	AccessTokenID  string             `json:"access_token_id"`
+	RefreshTokenID string             `json:"refresh_token_id"`
+}
+
+type ActionTokenPurpose string
+
+const (
+	ActionTokenEmailVerification ActionTokenPurpose = "EMAIL_VERIFICATION"
+	ActionTokenPasswordReset     ActionTokenPurpose = "PASSWORD_RESET"
+	ActionTokenAccountUnlock     ActionTokenPurpose = "ACCOUNT_UNLOCK"
+	ActionTokenMFAChallenge      ActionTokenPurpose = "MFA_CHALLENGE"
+)
+
+// Claims of the single-use tokens sent in emailed links
+type ActionTokenClaims struct {
+	UserID  int64              `json:"user_id"`
+	Email   string             `json:"email"`
+	Purpose ActionTokenPurpose `json:"purpose"`
+	// Fingerprint ties the token to state the action changes, so it stops working once that changes
+	Fingerprint string `json:"fingerprint,omitempty"`
+	jwt.RegisteredClaims
+}
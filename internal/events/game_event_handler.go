@@ -11,11 +11,13 @@ import (
 
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
 	ws "github.com/nghiavan0610/btaskee-quiz-service/pkg/websocket"
 	"github.com/nghiavan0610/btaskee-quiz-service/utils"
+	"github.com/samber/lo"
 )
 
 type GameEventHandler interface {
@@ -25,21 +27,23 @@ type GameEventHandler interface {
 	// Game lifecycle events
 	NotifyGameStart(sessionID int64) error
 	NotifyQuestionStart(sessionID int64, question *models.Question) error
-	NotifyQuestionEnd(sessionID int64) error
+	NotifyQuestionEnd(sessionID int64, question *models.Question) error
 	NotifyParticipantJoin(sessionID int64, participant *models.SessionParticipant) error
 	NotifyParticipantLeft(sessionID int64, participantID int64) error
+	NotifySessionCancelled(sessionID int64, reason string) error
 
 	// Client management
 	HandleClientDisconnect(client *ws.Client)
 }
 
 type gameEventHandler struct {
-	sessionRepo    repositories.SessionRepository
-	questionRepo   repositories.QuestionRepository
-	hub            ws.Hub
-	logger         *logger.Logger
-	questionTimers map[int64]*time.Timer // sessionID -> timer for auto progression
-	timerMutex     sync.RWMutex          // protect timer map
+	sessionRepo     repositories.SessionRepository
+	questionRepo    repositories.QuestionRepository
+	quizVersionRepo repositories.QuizVersionRepository
+	hub             ws.Hub
+	logger          *logger.Logger
+	questionTimers  map[int64]*time.Timer // sessionID -> timer for auto progression
+	timerMutex      sync.RWMutex          // protect timer map
 
 	// PERFORMANCE: Message marshaling cache for frequently sent messages
 	marshalCache sync.Map // string -> []byte (cached marshaled messages)
@@ -53,16 +57,18 @@ var (
 func ProvideGameEventHandler(
 	sessionRepo repositories.SessionRepository,
 	questionRepo repositories.QuestionRepository,
+	quizVersionRepo repositories.QuizVersionRepository,
 	hub ws.Hub,
 	logger *logger.Logger,
 ) GameEventHandler {
 	gameEventHandlerOnce.Do(func() {
 		gameEventHandlerInstance = &gameEventHandler{
-			sessionRepo:    sessionRepo,
-			questionRepo:   questionRepo,
-			hub:            hub,
-			logger:         logger,
-			questionTimers: make(map[int64]*time.Timer),
+			sessionRepo:     sessionRepo,
+			questionRepo:    questionRepo,
+			quizVersionRepo: quizVersionRepo,
+			hub:             hub,
+			logger:          logger,
+			questionTimers:  make(map[int64]*time.Timer),
 		}
 	})
 
@@ -187,12 +193,27 @@ func (s *gameEventHandler) handleSubmitAnswer(client *ws.Client, wsMsg *models.W
 
 	ctx := context.Background()
 
-	question, err := s.questionRepo.GetQuestionByID(ctx, answerPayload.QuestionID)
+	session, err := s.sessionRepo.GetSessionByID(ctx, client.SessionID)
+	if err != nil {
+		s.sendError(client, "SESSION_NOT_FOUND", "Session not found")
+		return
+	}
+
+	questions, err := s.getSessionQuestions(ctx, session)
 	if err != nil {
 		s.sendError(client, "QUESTION_NOT_FOUND", "Question not found")
 		return
 	}
 
+	// Only questions of this session can be answered, as they were when it was created
+	question, found := lo.Find(questions, func(q *models.Question) bool {
+		return q.ID == answerPayload.QuestionID
+	})
+	if !found {
+		s.sendError(client, "QUESTION_NOT_FOUND", "Question not found")
+		return
+	}
+
 	// Validate payload based on question type
 	switch question.Type {
 	case models.QuestionTypeSingleChoice, models.QuestionTypeTextInput:
@@ -212,13 +233,7 @@ func (s *gameEventHandler) handleSubmitAnswer(client *ws.Client, wsMsg *models.W
 
 	scoreEarned := int32(0)
 	if isCorrect {
-		// Score based on time: max 1000 points, reduced by time taken
-		baseScore := int32(constants.MaxQuestionScore)
-		timePenalty := int32(answerPayload.TimeTaken / 100) // 1 point per 100ms
-		scoreEarned = baseScore - timePenalty
-		if scoreEarned < constants.MinQuestionScore {
-			scoreEarned = constants.MinQuestionScore // minimum score for correct answer
-		}
+		scoreEarned = s.calculateScore(question, int64(answerPayload.TimeTaken))
 	}
 
 	// Update score and send response
@@ -263,7 +278,7 @@ func (s *gameEventHandler) handleStartGame(client *ws.Client, wsMsg *models.WSMe
 			if err != nil {
 				return nil, err
 			}
-			return s.questionRepo.GetQuestionListByQuiz(ctx, session.QuizID)
+			return s.getSessionQuestions(ctx, session)
 		},
 	}
 
@@ -339,7 +354,7 @@ func (s *gameEventHandler) handleNextQuestion(client *ws.Client, wsMsg *models.W
 			if err != nil {
 				return nil, err
 			}
-			return s.questionRepo.GetQuestionListByQuiz(ctx, session.QuizID)
+			return s.getSessionQuestions(ctx, session)
 		},
 	}
 
@@ -359,7 +374,7 @@ func (s *gameEventHandler) handleNextQuestion(client *ws.Client, wsMsg *models.W
 
 	// 2. End current question if needed
 	if session.CurrentQuestionIndex >= 0 {
-		s.NotifyQuestionEnd(client.SessionID)
+		s.NotifyQuestionEnd(client.SessionID, s.sessionQuestion(session, questions))
 	}
 
 	// 3. Get next question index
@@ -415,9 +430,15 @@ func (s *gameEventHandler) handleEndGame(client *ws.Client, wsMsg *models.WSMess
 	// Stop any running timers
 	s.stopQuestionTimer(client.SessionID)
 
+	questions, err := s.getSessionQuestions(ctx, session)
+	if err != nil {
+		s.logger.Error("Failed to get questions for game end", err)
+		questions = []*models.Question{}
+	}
+
 	// 2. End current question if active
 	if session.Status == models.SessionStatusActive {
-		s.NotifyQuestionEnd(client.SessionID)
+		s.NotifyQuestionEnd(client.SessionID, s.sessionQuestion(session, questions))
 	}
 
 	// 3. End session and get final leaderboard in parallel
@@ -454,6 +475,7 @@ func (s *gameEventHandler) handleEndGame(client *ws.Client, wsMsg *models.WSMess
 			"ended_at":          time.Now(),
 			"final_leaderboard": s.formatLeaderboard(finalLeaderboard),
 			"status":            "completed",
+			"review":            s.formatReview(questions),
 		},
 		Timestamp: time.Now(),
 	}
@@ -535,20 +557,26 @@ func (s *gameEventHandler) handleGetSessionState(client *ws.Client, wsMsg *model
 	// Get current question if session is active
 	var currentQuestion map[string]interface{}
 	if session.Status == models.SessionStatusActive && session.CurrentQuestionIndex >= 0 {
-		questions, err := s.questionRepo.GetQuestionListByQuiz(ctx, session.QuizID)
+		questions, err := s.getSessionQuestions(ctx, session)
 		if err == nil && int(session.CurrentQuestionIndex) < len(questions) {
 			question := questions[session.CurrentQuestionIndex]
 			currentQuestion = map[string]interface{}{
-				"id":         question.ID,
-				"question":   question.Question,
-				"type":       question.Type,
-				"time_limit": s.timeLimitToSeconds(question.TimeLimit),
-				"index":      question.Index,
+				"id":                question.ID,
+				"question":          question.Question,
+				"question_html":     question.QuestionHTML,
+				"content_format":    question.ContentFormat,
+				"type":              question.Type,
+				"time_limit":        s.timeLimitToSeconds(question.TimeLimit),
+				"points_multiplier": question.PointsMultiplier,
+				"index":             question.Index,
+				"media":             question.Media,
 				"answers": func() []map[string]interface{} {
 					answers := make([]map[string]interface{}, len(question.Answers))
 					for i, answer := range question.Answers {
 						answers[i] = map[string]interface{}{
-							"text": answer.Text,
+							"text":      answer.Text,
+							"text_html": answer.TextHTML,
+							"media":     answer.Media,
 						}
 					}
 					return answers
@@ -648,17 +676,23 @@ func (s *gameEventHandler) NotifyQuestionStart(sessionID int64, question *models
 	serverStartTime := time.Now()
 
 	safeQuestion := map[string]interface{}{
-		"id":         question.ID,
-		"question":   question.Question,
-		"type":       question.Type,
-		"time_limit": timeLimitSeconds, // seconds
-		"index":      question.Index,
-		"max_score":  1000, // Maximum possible score
+		"id":                question.ID,
+		"question":          question.Question,
+		"question_html":     question.QuestionHTML,
+		"content_format":    question.ContentFormat,
+		"type":              question.Type,
+		"time_limit":        timeLimitSeconds, // seconds
+		"index":             question.Index,
+		"max_score":         constants.MaxQuestionScore * question.PointsMultiplier, // Maximum possible score
+		"points_multiplier": question.PointsMultiplier,
+		"media":             question.Media,
 		"answers": func() []map[string]interface{} {
 			answers := make([]map[string]interface{}, len(question.Answers))
 			for i, answer := range question.Answers {
 				answers[i] = map[string]interface{}{
-					"text": answer.Text,
+					"text":      answer.Text,
+					"text_html": answer.TextHTML,
+					"media":     answer.Media,
 				}
 			}
 			return answers
@@ -684,15 +718,21 @@ func (s *gameEventHandler) NotifyQuestionStart(sessionID int64, question *models
 	return s.broadcastToRoom(sessionID, message, nil)
 }
 
-func (s *gameEventHandler) NotifyQuestionEnd(sessionID int64) error {
+// NotifyQuestionEnd closes the question and reveals its correct answers and explanation
+func (s *gameEventHandler) NotifyQuestionEnd(sessionID int64, question *models.Question) error {
 	s.stopQuestionTimer(sessionID)
 
+	payload := map[string]interface{}{
+		"session_id": sessionID,
+		"ended_at":   time.Now(),
+	}
+	if question != nil {
+		payload["review"] = transformers.ConvertToQuestionReviewModel(question)
+	}
+
 	message := &models.WSMessage{
-		Type: models.WSMsgTypeQuestionEnd,
-		Payload: map[string]interface{}{
-			"session_id": sessionID,
-			"ended_at":   time.Now(),
-		},
+		Type:      models.WSMsgTypeQuestionEnd,
+		Payload:   payload,
 		Timestamp: time.Now(),
 	}
 
@@ -728,6 +768,24 @@ func (s *gameEventHandler) NotifyParticipantLeft(sessionID int64, participantID
 	return s.broadcastToRoom(sessionID, message, nil)
 }
 
+// NotifySessionCancelled tells the room a session was ended from outside the game, e.g. by an admin
+func (s *gameEventHandler) NotifySessionCancelled(sessionID int64, reason string) error {
+	s.stopQuestionTimer(sessionID)
+
+	message := &models.WSMessage{
+		Type: models.WSMsgTypeQuizEnd,
+		Payload: map[string]interface{}{
+			"session_id":        sessionID,
+			"ended_at":          time.Now(),
+			"status":            string(models.SessionStatusCancelled),
+			"completion_reason": reason,
+		},
+		Timestamp: time.Now(),
+	}
+
+	return s.broadcastToRoom(sessionID, message, nil)
+}
+
 func (s *gameEventHandler) evaluateAnswer(question *models.Question, payload *models.WSAnswerPayload) bool {
 	switch question.Type {
 	case models.QuestionTypeSingleChoice:
@@ -852,9 +910,9 @@ func (s *gameEventHandler) sendToClient(client *ws.Client, message *models.WSMes
 }
 
 func (s *gameEventHandler) broadcastToRoom(sessionID int64, message *models.WSMessage, excludeClient *ws.Client) error {
+	// question_end is left out: it carries the reveal of a specific question, and two can close within the same second
 	cacheKey := ""
 	if message.Type == models.WSMsgTypeLeaderboard ||
-		message.Type == models.WSMsgTypeQuestionEnd ||
 		message.Type == models.WSMsgTypeQuizStart {
 		cacheKey = fmt.Sprintf("%s_%d_%v", message.Type, sessionID, message.Timestamp.Unix())
 	}
@@ -904,6 +962,48 @@ func (s *gameEventHandler) formatLeaderboard(participants []*models.LeaderboardP
 	return leaderboard
 }
 
+// getSessionQuestions returns the questions a session plays: those of the quiz version it was pinned
+// to when created, so later edits to the quiz do not reach a running game. Sessions created before
+// versions existed play the live questions. Either way clients see and answer with the question's id;
+// only versions taken before ids were recorded fall back to the question's position.
+func (s *gameEventHandler) getSessionQuestions(ctx context.Context, session *models.QuizSession) ([]*models.Question, error) {
+	if session.QuizVersionID == nil {
+		return s.questionRepo.GetQuestionListByQuiz(ctx, session.QuizID)
+	}
+
+	version, err := s.quizVersionRepo.GetQuizVersionByID(ctx, *session.QuizVersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]*models.Question, len(version.Questions))
+	for i, versionQuestion := range version.Questions {
+		questions[i] = transformers.ConvertVersionQuestionToModel(version.QuizID, versionQuestion)
+		if questions[i].ID == 0 {
+			questions[i].ID = int64(i + 1)
+		}
+	}
+
+	return questions, nil
+}
+
+// sessionQuestion returns the question the session is currently on, or nil when it is out of range
+func (s *gameEventHandler) sessionQuestion(session *models.QuizSession, questions []*models.Question) *models.Question {
+	if session.CurrentQuestionIndex < 0 || int(session.CurrentQuestionIndex) >= len(questions) {
+		return nil
+	}
+	return questions[session.CurrentQuestionIndex]
+}
+
+// formatReview reveals every question of a finished game for post-game review
+func (s *gameEventHandler) formatReview(questions []*models.Question) []*models.QuestionReview {
+	review := make([]*models.QuestionReview, len(questions))
+	for i, question := range questions {
+		review[i] = transformers.ConvertToQuestionReviewModel(question)
+	}
+	return review
+}
+
 func (s *gameEventHandler) sendError(client *ws.Client, code, message string) {
 	errorMsg := &models.WSMessage{
 		Type: models.WSMsgTypeError,
@@ -917,21 +1017,24 @@ func (s *gameEventHandler) sendError(client *ws.Client, code, message string) {
 	s.sendToClient(client, errorMsg)
 }
 
-func (s *gameEventHandler) timeLimitToSeconds(timeLimit models.TimeLimitType) int32 {
-	switch timeLimit {
-	case models.TimeLimitType5:
-		return 5
-	case models.TimeLimitType10:
-		return 10
-	case models.TimeLimitType20:
-		return 20
-	case models.TimeLimitType45:
-		return 45
-	case models.TimeLimitType80:
-		return 80
-	default:
+func (s *gameEventHandler) timeLimitToSeconds(timeLimit int32) int32 {
+	if timeLimit <= 0 {
 		return constants.QuestionTimeLimit
 	}
+	return timeLimit
+}
+
+// calculateScore scores a correct answer: max 1000 points, reduced by 1 point per 100ms taken down to the
+// minimum score, then scaled by the question's points multiplier
+func (s *gameEventHandler) calculateScore(question *models.Question, timeTakenMs int64) int32 {
+	baseScore := int32(constants.MaxQuestionScore)
+	timePenalty := int32(timeTakenMs / 100) // 1 point per 100ms
+	score := baseScore - timePenalty
+	if score < constants.MinQuestionScore {
+		score = constants.MinQuestionScore // minimum score for correct answer
+	}
+
+	return score * question.PointsMultiplier
 }
 
 func (s *gameEventHandler) startQuestionTimer(sessionID int64, timeLimit int32) {
@@ -972,7 +1075,7 @@ func (s *gameEventHandler) handleQuestionTimeout(sessionID int64) {
 			if err != nil {
 				return nil, err
 			}
-			return s.questionRepo.GetQuestionListByQuiz(ctx, session.QuizID)
+			return s.getSessionQuestions(ctx, session)
 		},
 	}
 
@@ -989,7 +1092,7 @@ func (s *gameEventHandler) handleQuestionTimeout(sessionID int64) {
 	questions := results[1].([]*models.Question)
 
 	// End current question
-	s.NotifyQuestionEnd(sessionID)
+	s.NotifyQuestionEnd(sessionID, s.sessionQuestion(session, questions))
 
 	// Show leaderboard for 5 seconds
 	s.showIntermediateLeaderboard(sessionID)
@@ -1059,7 +1162,7 @@ func (s *gameEventHandler) autoNextQuestion(sessionID int64) {
 
 	nextQuestionIndex := session.CurrentQuestionIndex + 1
 
-	questions, err := s.questionRepo.GetQuestionListByQuiz(ctx, session.QuizID)
+	questions, err := s.getSessionQuestions(ctx, session)
 	if err != nil {
 		s.logger.Error("Failed to get questions for auto next", map[string]interface{}{
 			"session_id": sessionID,
@@ -1095,6 +1198,13 @@ func (s *gameEventHandler) autoNextQuestion(sessionID int64) {
 func (s *gameEventHandler) autoEndGame(sessionID int64) {
 	ctx := context.Background()
 
+	questions := []*models.Question{}
+	if session, err := s.sessionRepo.GetSessionByID(ctx, sessionID); err == nil {
+		if sessionQuestions, err := s.getSessionQuestions(ctx, session); err == nil {
+			questions = sessionQuestions
+		}
+	}
+
 	err := s.sessionRepo.EndSession(ctx, sessionID)
 	if err != nil {
 		s.logger.Error("Failed to auto-end session", map[string]interface{}{
@@ -1124,6 +1234,7 @@ func (s *gameEventHandler) autoEndGame(sessionID int64) {
 			"auto_ended":        true,
 			"completion_reason": "all_questions_completed",
 			"server_driven":     true,
+			"review":            s.formatReview(questions),
 		},
 		Timestamp: time.Now(),
 	}
@@ -2,15 +2,14 @@ package guards
 
 import (
 	"context"
-	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/services"
-	"github.com/nghiavan0610/btaskee-quiz-service/pkg/cache"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
@@ -20,7 +19,6 @@ import (
 type guardConfig struct {
 	TokenName    string
 	ValidateFunc func(ctx context.Context, token string) (*dtos.JWTClaims, *exception.AppError)
-	CachePrefix  string
 	CheckActive  bool
 }
 
@@ -28,12 +26,20 @@ type AuthGuard interface {
 	AccessTokenGuard() fiber.Handler
 	RefreshTokenGuard() fiber.Handler
 	OptionalAccessTokenGuard() fiber.Handler
+	// APIKeyOrAccessTokenGuard also accepts a personal API key in X-API-Key. The key needs readScope for
+	// GET and HEAD requests and writeScope for the rest.
+	APIKeyOrAccessTokenGuard(readScope, writeScope models.APIKeyScope) fiber.Handler
+	// OptionalAPIKeyOrAccessTokenGuard is OptionalAccessTokenGuard that also accepts an API key. A key
+	// that is sent but rejected fails the request, so scripts do not silently run as a guest.
+	OptionalAPIKeyOrAccessTokenGuard(scope models.APIKeyScope) fiber.Handler
+	// RoleGuard rejects users whose current role lacks the permission. It goes after AccessTokenGuard.
+	RoleGuard(permission models.Permission) fiber.Handler
 	GetAuthUser(c *fiber.Ctx) (*dtos.UserSession, *exception.AppError)
 }
 
 type authGuard struct {
-	tokenService services.TokenService
-	cache        cache.Cache
+	tokenService  services.TokenService
+	apiKeyService services.APIKeyService
 }
 
 var (
@@ -41,11 +47,11 @@ var (
 	authGuardOnce     sync.Once
 )
 
-func ProvideAuthGuard(tokenService services.TokenService, cache cache.Cache) AuthGuard {
+func ProvideAuthGuard(tokenService services.TokenService, apiKeyService services.APIKeyService) AuthGuard {
 	authGuardOnce.Do(func() {
 		authGuardInstance = &authGuard{
-			tokenService: tokenService,
-			cache:        cache,
+			tokenService:  tokenService,
+			apiKeyService: apiKeyService,
 		}
 	})
 	return authGuardInstance
@@ -55,7 +61,6 @@ func (g *authGuard) AccessTokenGuard() fiber.Handler {
 	return g.createGuard(guardConfig{
 		TokenName:    "Access token",
 		ValidateFunc: g.tokenService.ValidateAccessToken,
-		CachePrefix:  string(constants.CachePrefixAccessToken),
 		CheckActive:  true,
 	})
 }
@@ -64,7 +69,6 @@ func (g *authGuard) RefreshTokenGuard() fiber.Handler {
 	return g.createGuard(guardConfig{
 		TokenName:    "Refresh token",
 		ValidateFunc: g.tokenService.ValidateRefreshToken,
-		CachePrefix:  string(constants.CachePrefixRefreshToken),
 		CheckActive:  true,
 	})
 }
@@ -96,26 +100,66 @@ func (g *authGuard) OptionalAccessTokenGuard() fiber.Handler {
 			return c.Next()
 		}
 
-		// Check if token is in cache (active)
-		var cachedUserSession dtos.UserSession
-		cacheErr := g.cache.GetObject(context.Background(), cache.CacheKeyOption{
-			Module:    string(constants.CacheModuleAuth),
-			Prefix:    string(constants.CachePrefixAccessToken),
-			UniqueKey: strconv.FormatInt(claims.UserSession.UserID, 10),
-		}, &cachedUserSession)
-		if cacheErr != nil {
-			// Token not in cache or cache error - continue without setting user
-			return c.Next()
-		}
-
 		// Check if user is active
-		if !cachedUserSession.IsActive {
+		userSession := claims.UserSession
+		if !userSession.IsActive {
 			// User not active - continue without setting user
 			return c.Next()
 		}
 
 		// Valid token and active user - set user in locals
-		c.Locals(string(constants.KEY_AUTH_USER), &cachedUserSession)
+		c.Locals(string(constants.KEY_AUTH_USER), &userSession)
+
+		return c.Next()
+	}
+}
+
+func (g *authGuard) APIKeyOrAccessTokenGuard(readScope, writeScope models.APIKeyScope) fiber.Handler {
+	accessTokenGuard := g.AccessTokenGuard()
+	return func(c *fiber.Ctx) error {
+		apiKey := c.Get("X-API-Key")
+		if apiKey == "" {
+			return accessTokenGuard(c)
+		}
+
+		scope := writeScope
+		if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+			scope = readScope
+		}
+
+		return g.apiKeyGuard(c, apiKey, scope)
+	}
+}
+
+func (g *authGuard) OptionalAPIKeyOrAccessTokenGuard(scope models.APIKeyScope) fiber.Handler {
+	optionalAccessTokenGuard := g.OptionalAccessTokenGuard()
+	return func(c *fiber.Ctx) error {
+		apiKey := c.Get("X-API-Key")
+		if apiKey == "" {
+			return optionalAccessTokenGuard(c)
+		}
+
+		return g.apiKeyGuard(c, apiKey, scope)
+	}
+}
+
+func (g *authGuard) RoleGuard(permission models.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authUser, appErr := g.GetAuthUser(c)
+		if appErr != nil {
+			return appErr
+		}
+
+		// The session holds the role from sign-in, so check the stored one in case it has changed since
+		if appErr := g.tokenService.ReloadUserSession(c.Context(), authUser); appErr != nil {
+			return appErr
+		}
+
+		if !models.HasPermission(authUser.Role, permission) {
+			return exception.Forbidden(errors.CodeForbidden, errors.ErrForbidden).
+				WithDetails("Your role does not allow this action").
+				WithMetadata("permission", string(permission))
+		}
 
 		return c.Next()
 	}
@@ -156,26 +200,13 @@ func (g *authGuard) createGuard(config guardConfig) fiber.Handler {
 				WithDetails("User session is missing from token claims")
 		}
 
+		// Claims carry the cached login session, which the token service has already checked
 		userSession := claims.UserSession
-		if config.CachePrefix != "" {
-			var cachedUserSession dtos.UserSession
-			appErr = g.cache.GetObject(context.Background(), cache.CacheKeyOption{
-				Module:    string(constants.CacheModuleAuth),
-				Prefix:    config.CachePrefix,
-				UniqueKey: strconv.FormatInt(claims.UserSession.UserID, 10),
-			}, &cachedUserSession)
-			if appErr != nil {
-				return exception.Unauthorized(errors.CodeUnauthorized, errors.ErrUnauthorizedAccess).
-					WithDetails("User session is missing")
-			}
-
-			// Check if user is active (if required)
-			if config.CheckActive && !cachedUserSession.IsActive {
-				return exception.Unauthorized(errors.CodeUnauthorized, errors.ErrUserDisabled).
-					WithDetails("User account is not active")
-			}
-
-			userSession = cachedUserSession
+
+		// Check if user is active (if required)
+		if config.CheckActive && !userSession.IsActive {
+			return exception.Unauthorized(errors.CodeUnauthorized, errors.ErrUserDisabled).
+				WithDetails("User account is not active")
 		}
 
 		c.Locals(string(constants.KEY_AUTH_USER), &userSession)
@@ -183,3 +214,14 @@ func (g *authGuard) createGuard(config guardConfig) fiber.Handler {
 		return c.Next()
 	}
 }
+
+func (g *authGuard) apiKeyGuard(c *fiber.Ctx, apiKey string, scope models.APIKeyScope) error {
+	userSession, appErr := g.apiKeyService.ValidateAPIKey(c.Context(), apiKey, scope)
+	if appErr != nil {
+		return appErr
+	}
+
+	c.Locals(string(constants.KEY_AUTH_USER), userSession)
+
+	return c.Next()
+}
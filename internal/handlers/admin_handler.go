@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/guards"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/services"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/middlewares"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/response"
+)
+
+type (
+	AdminHandler interface {
+		RegisterRoutes(r fiber.Router)
+	}
+
+	adminHandler struct {
+		adminService services.AdminService
+		authGuard    guards.AuthGuard
+	}
+)
+
+var (
+	adminHandlerOnce     sync.Once
+	adminHandlerInstance AdminHandler
+)
+
+func ProvideAdminHandler(
+	adminService services.AdminService,
+	authGuard guards.AuthGuard,
+) AdminHandler {
+	adminHandlerOnce.Do(func() {
+		adminHandlerInstance = &adminHandler{
+			adminService: adminService,
+			authGuard:    authGuard,
+		}
+	})
+	return adminHandlerInstance
+}
+
+func (h *adminHandler) RegisterRoutes(r fiber.Router) {
+	adminGroup := r.Group("/admin", h.authGuard.AccessTokenGuard())
+
+	adminGroup.Get("/users",
+		h.authGuard.RoleGuard(models.PermissionManageUsers),
+		middlewares.QueryStringValidator[dtos.AdminListUsersRequest](),
+		h.listUsers,
+	)
+	adminGroup.Post("/users/:user_id/deactivate",
+		h.authGuard.RoleGuard(models.PermissionManageUsers),
+		middlewares.PayloadValidator[dtos.AdminDeactivateUserRequest](),
+		h.deactivateUser,
+	)
+	adminGroup.Post("/users/:user_id/reactivate",
+		h.authGuard.RoleGuard(models.PermissionManageUsers),
+		middlewares.PayloadValidator[dtos.AdminReactivateUserRequest](),
+		h.reactivateUser,
+	)
+	adminGroup.Post("/quizzes/:quiz_id/takedown",
+		h.authGuard.RoleGuard(models.PermissionManageQuizzes),
+		middlewares.PayloadValidator[dtos.AdminTakeDownQuizRequest](),
+		h.takeDownQuiz,
+	)
+	adminGroup.Delete("/quizzes/:quiz_id",
+		h.authGuard.RoleGuard(models.PermissionManageQuizzes),
+		middlewares.PayloadValidator[dtos.AdminDeleteQuizRequest](),
+		h.deleteQuiz,
+	)
+	adminGroup.Get("/sessions",
+		h.authGuard.RoleGuard(models.PermissionManageSessions),
+		middlewares.QueryStringValidator[dtos.AdminListSessionsRequest](),
+		h.listLiveSessions,
+	)
+	adminGroup.Post("/sessions/:session_id/end",
+		h.authGuard.RoleGuard(models.PermissionManageSessions),
+		middlewares.PayloadValidator[dtos.AdminEndSessionRequest](),
+		h.endSession,
+	)
+}
+
+func (h *adminHandler) listUsers(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.AdminListUsersRequest](c, constants.KEY_REQ_QUERY_PARAMS)
+
+	res, appErr := h.adminService.ListUsers(c.Context(), req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *adminHandler) deactivateUser(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.AdminDeactivateUserRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.adminService.DeactivateUser(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *adminHandler) takeDownQuiz(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.AdminTakeDownQuizRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.adminService.TakeDownQuiz(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *adminHandler) reactivateUser(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.AdminReactivateUserRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.adminService.ReactivateUser(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *adminHandler) deleteQuiz(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.AdminDeleteQuizRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	appErr = h.adminService.DeleteQuiz(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *adminHandler) listLiveSessions(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.AdminListSessionsRequest](c, constants.KEY_REQ_QUERY_PARAMS)
+
+	res, appErr := h.adminService.ListLiveSessions(c.Context(), req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *adminHandler) endSession(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.AdminEndSessionRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	appErr = h.adminService.EndSession(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
@@ -20,6 +20,8 @@ type (
 
 	authHandler struct {
 		authService services.AuthService
+		oidcService services.OIDCService
+		mfaService  services.MFAService
 		authGuard   guards.AuthGuard
 	}
 )
@@ -29,9 +31,9 @@ var (
 	authHandlerInstance AuthHandler
 )
 
-func ProvideAuthHandler(authService services.AuthService, authGuard guards.AuthGuard) AuthHandler {
+func ProvideAuthHandler(authService services.AuthService, oidcService services.OIDCService, mfaService services.MFAService, authGuard guards.AuthGuard) AuthHandler {
 	authHandlerOnce.Do(func() {
-		authHandlerInstance = &authHandler{authService, authGuard}
+		authHandlerInstance = &authHandler{authService, oidcService, mfaService, authGuard}
 	})
 	return authHandlerInstance
 }
@@ -55,10 +57,125 @@ func (h *authHandler) RegisterRoutes(r fiber.Router) {
 		h.authGuard.AccessTokenGuard(),
 		h.signOut,
 	)
+	authGroup.Get("/signout-all",
+		h.authGuard.AccessTokenGuard(),
+		h.signOutAll,
+	)
+	authGroup.Get("/sessions",
+		h.authGuard.AccessTokenGuard(),
+		h.listSessions,
+	)
+	authGroup.Delete("/sessions/:session_id",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.PathParamsValidator[dtos.RevokeAuthSessionRequest](),
+		h.revokeSession,
+	)
+	authGroup.Post("/forgot-password",
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.05,
+			BurstSize:         3,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("forgot_password"),
+		}),
+		middlewares.BodyValidator[dtos.ForgotPasswordRequest](),
+		h.forgotPassword,
+	)
+	authGroup.Post("/reset-password",
+		middlewares.BodyValidator[dtos.ResetPasswordRequest](),
+		h.resetPassword,
+	)
+	authGroup.Post("/verify-email",
+		middlewares.BodyValidator[dtos.VerifyEmailRequest](),
+		h.verifyEmail,
+	)
+	authGroup.Post("/unlock",
+		middlewares.BodyValidator[dtos.UnlockAccountRequest](),
+		h.unlockAccount,
+	)
+	authGroup.Post("/verify-email/resend",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.05,
+			BurstSize:         3,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("verify_email_resend"),
+		}),
+		h.resendVerificationEmail,
+	)
+
+	// Not a group with the access token guard: the challenge is answered before there is a token
+	authGroup.Post("/mfa/challenge",
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.2,
+			BurstSize:         5,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("mfa_challenge"),
+		}),
+		middlewares.BodyValidator[dtos.MFAChallengeRequest](),
+		h.verifyMFAChallenge,
+	)
+	authGroup.Get("/mfa",
+		h.authGuard.AccessTokenGuard(),
+		h.getMFAStatus,
+	)
+	authGroup.Post("/mfa/totp/enroll",
+		h.authGuard.AccessTokenGuard(),
+		h.enrollTOTP,
+	)
+	authGroup.Post("/mfa/totp/confirm",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.2,
+			BurstSize:         5,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("mfa_confirm"),
+		}),
+		middlewares.BodyValidator[dtos.ConfirmTOTPRequest](),
+		h.confirmTOTP,
+	)
+	authGroup.Post("/mfa/totp/disable",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.2,
+			BurstSize:         5,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("mfa_disable"),
+		}),
+		middlewares.BodyValidator[dtos.MFACodeRequest](),
+		h.disableTOTP,
+	)
+	authGroup.Post("/mfa/recovery-codes",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.2,
+			BurstSize:         5,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("mfa_recovery_codes"),
+		}),
+		middlewares.BodyValidator[dtos.MFACodeRequest](),
+		h.regenerateRecoveryCodes,
+	)
+
+	oidcGroup := authGroup.Group("/oidc")
+	oidcGroup.Get("/providers", h.listOIDCProviders)
+	oidcGroup.Get("/:provider/login",
+		middlewares.PathParamsValidator[dtos.OIDCLoginRequest](),
+		h.startOIDCLogin,
+	)
+	oidcGroup.Post("/:provider/callback",
+		middlewares.PayloadValidator[dtos.OIDCCallbackRequest](),
+		h.completeOIDCLogin,
+	)
+}
+
+// RegisterRootRoutes serves the public signing keys where JWT libraries look for them
+func (h *authHandler) RegisterRootRoutes(r fiber.Router) {
+	r.Get("/.well-known/jwks.json", h.getJWKS)
+}
+
+func (h *authHandler) getJWKS(c *fiber.Ctx) error {
+	// Plain JWK Set rather than the usual response envelope, as JWKS clients expect
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.authService.GetJWKS())
 }
 
 func (h *authHandler) signUp(c *fiber.Ctx) error {
 	req := middlewares.GetRequest[dtos.SignUpRequest](c, constants.KEY_REQ_BODY_PARAMS)
+	req.Device = deviceInfo(c)
 
 	res, appErr := h.authService.SignUp(c.Context(), req)
 	if appErr != nil {
@@ -70,6 +187,7 @@ func (h *authHandler) signUp(c *fiber.Ctx) error {
 
 func (h *authHandler) signIn(c *fiber.Ctx) error {
 	req := middlewares.GetRequest[dtos.SignInRequest](c, constants.KEY_REQ_BODY_PARAMS)
+	req.Device = deviceInfo(c)
 
 	res, appErr := h.authService.SignIn(c.Context(), req)
 	if appErr != nil {
@@ -99,7 +217,227 @@ func (h *authHandler) signOut(c *fiber.Ctx) error {
 		return response.Error(c, appErr)
 	}
 
-	h.authService.SignOut(c.Context(), authUser)
+	if appErr := h.authService.SignOut(c.Context(), authUser); appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *authHandler) signOutAll(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	if appErr := h.authService.SignOutAll(c.Context(), authUser); appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *authHandler) listSessions(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	res, appErr := h.authService.ListSessions(c.Context(), authUser)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *authHandler) revokeSession(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.RevokeAuthSessionRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	if appErr := h.authService.RevokeSession(c.Context(), authUser, req); appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *authHandler) forgotPassword(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.ForgotPasswordRequest](c, constants.KEY_REQ_BODY_PARAMS)
+
+	if appErr := h.authService.ForgotPassword(c.Context(), req); appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *authHandler) resetPassword(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.ResetPasswordRequest](c, constants.KEY_REQ_BODY_PARAMS)
+
+	if appErr := h.authService.ResetPassword(c.Context(), req); appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *authHandler) verifyEmail(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.VerifyEmailRequest](c, constants.KEY_REQ_BODY_PARAMS)
+
+	if appErr := h.authService.VerifyEmail(c.Context(), req); appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *authHandler) unlockAccount(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.UnlockAccountRequest](c, constants.KEY_REQ_BODY_PARAMS)
+
+	if appErr := h.authService.UnlockAccount(c.Context(), req); appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *authHandler) resendVerificationEmail(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	if appErr := h.authService.SendVerificationEmail(c.Context(), authUser); appErr != nil {
+		return response.Error(c, appErr)
+	}
 
 	return response.Success(c, true)
 }
+
+func (h *authHandler) listOIDCProviders(c *fiber.Ctx) error {
+	return response.Success(c, h.oidcService.ListProviders())
+}
+
+func (h *authHandler) startOIDCLogin(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.OIDCLoginRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	res, appErr := h.oidcService.StartLogin(c.Context(), req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *authHandler) completeOIDCLogin(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.OIDCCallbackRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+	req.Device = deviceInfo(c)
+
+	res, appErr := h.oidcService.CompleteLogin(c.Context(), req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *authHandler) verifyMFAChallenge(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.MFAChallengeRequest](c, constants.KEY_REQ_BODY_PARAMS)
+	req.Device = deviceInfo(c)
+
+	res, appErr := h.authService.VerifyMFAChallenge(c.Context(), req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *authHandler) getMFAStatus(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	res, appErr := h.mfaService.GetStatus(c.Context(), authUser)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *authHandler) enrollTOTP(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	res, appErr := h.mfaService.EnrollTOTP(c.Context(), authUser)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *authHandler) confirmTOTP(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.ConfirmTOTPRequest](c, constants.KEY_REQ_BODY_PARAMS)
+
+	res, appErr := h.mfaService.ConfirmTOTP(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *authHandler) disableTOTP(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.MFACodeRequest](c, constants.KEY_REQ_BODY_PARAMS)
+	req.Device = deviceInfo(c)
+
+	if appErr := h.mfaService.DisableTOTP(c.Context(), authUser, req); appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *authHandler) regenerateRecoveryCodes(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.MFACodeRequest](c, constants.KEY_REQ_BODY_PARAMS)
+	req.Device = deviceInfo(c)
+
+	res, appErr := h.mfaService.RegenerateRecoveryCodes(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func deviceInfo(c *fiber.Ctx) dtos.DeviceInfo {
+	return dtos.DeviceInfo{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
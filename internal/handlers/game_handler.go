@@ -6,6 +6,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/guards"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/services"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/middlewares"
@@ -45,16 +46,21 @@ func (h *gameHandler) RegisterRoutes(r fiber.Router) {
 	gameGroup := r.Group("/games")
 
 	gameGroup.Post("/",
-		h.authGuard.OptionalAccessTokenGuard(),
+		h.authGuard.OptionalAPIKeyOrAccessTokenGuard(models.APIKeyScopeSessionWrite),
 		middlewares.BodyValidator[dtos.CreateSessionRequest](),
 		h.createSession,
 	)
 
 	gameGroup.Get("/join/:join_code",
-		h.authGuard.OptionalAccessTokenGuard(),
+		h.authGuard.OptionalAPIKeyOrAccessTokenGuard(models.APIKeyScopeSessionRead),
 		middlewares.PathParamsValidator[dtos.JoinSessionRequest](),
 		h.joinSession,
 	)
+
+	gameGroup.Get("/review/:join_code",
+		middlewares.PathParamsValidator[dtos.GetSessionReviewRequest](),
+		h.getSessionReview,
+	)
 }
 
 func (h *gameHandler) createSession(c *fiber.Ctx) error {
@@ -84,3 +90,14 @@ func (h *gameHandler) joinSession(c *fiber.Ctx) error {
 
 	return response.Success(c, res)
 }
+
+func (h *gameHandler) getSessionReview(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.GetSessionReviewRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	res, appErr := h.sessionService.GetSessionReview(c.Context(), req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
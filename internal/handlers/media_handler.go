@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"io"
+	"mime"
+	"path"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/guards"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/services"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/middlewares"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/response"
+)
+
+type (
+	MediaHandler interface {
+		RegisterRoutes(r fiber.Router)
+	}
+
+	mediaHandler struct {
+		config       *config.Config
+		mediaService services.MediaService
+		authGuard    guards.AuthGuard
+	}
+)
+
+var (
+	mediaHandlerOnce     sync.Once
+	mediaHandlerInstance MediaHandler
+)
+
+func ProvideMediaHandler(
+	config *config.Config,
+	mediaService services.MediaService,
+	authGuard guards.AuthGuard,
+) MediaHandler {
+	mediaHandlerOnce.Do(func() {
+		mediaHandlerInstance = &mediaHandler{
+			config:       config,
+			mediaService: mediaService,
+			authGuard:    authGuard,
+		}
+	})
+	return mediaHandlerInstance
+}
+
+func (h *mediaHandler) RegisterRoutes(r fiber.Router) {
+	mediaGroup := r.Group("/media")
+
+	mediaGroup.Post("/",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.2,
+			BurstSize:         10,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("media_upload"),
+		}),
+		// Room for the largest media file plus multipart overhead
+		middlewares.BodyLimit(middlewares.BodyLimitConfig{
+			Limit: int(max(h.config.Storage.GetMaxImageSize(), h.config.Storage.GetMaxAudioSize())) + 1<<20,
+		}),
+		h.uploadMedia,
+	)
+
+	// Public so media URLs can be used directly in <img> and <audio> tags during games
+	mediaGroup.Get("/files/*", h.getMediaFile)
+}
+
+func (h *mediaHandler) uploadMedia(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return response.Error(c, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidMediaFile).WithDetails("file is required"))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return response.Error(c, exception.BadRequest(errors.CodeBadRequest, errors.ErrInvalidMediaFile).WithDetails(err.Error()))
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return response.Error(c, exception.BadRequest(errors.CodeBadRequest, errors.ErrInvalidMediaFile).WithDetails(err.Error()))
+	}
+
+	res, appErr := h.mediaService.UploadMedia(c.Context(), authUser, &dtos.UploadMediaRequest{
+		FileName: fileHeader.Filename,
+		Content:  content,
+	})
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *mediaHandler) getMediaFile(c *fiber.Ctx) error {
+	key := c.Params("*")
+
+	file, appErr := h.mediaService.OpenMediaFile(c.Context(), key)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	// Keys are random and never reused, so files can be cached forever
+	c.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+	c.Set(fiber.HeaderContentType, mime.TypeByExtension(path.Ext(key)))
+
+	return c.SendStream(file)
+}
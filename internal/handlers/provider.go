@@ -9,14 +9,22 @@ type AppHandler interface {
 	RegisterRoutes(r fiber.Router)
 }
 
+// RootHandler is implemented by handlers that also serve routes outside the versioned API, such as /.well-known
+type RootHandler interface {
+	RegisterRootRoutes(r fiber.Router)
+}
+
 func ProvideAppHandlers(
 	healthHandler HealthHandler,
 	authHandler AuthHandler,
 	userHandler UserHandler,
 	quizHandler QuizHandler,
 	questionHandler QuestionHandler,
+	tagHandler TagHandler,
+	mediaHandler MediaHandler,
 	gameHandler GameHandler,
 	webSocketHandler WebSocketHandler,
+	adminHandler AdminHandler,
 ) []AppHandler {
 	return []AppHandler{
 		healthHandler,
@@ -24,8 +32,11 @@ func ProvideAppHandlers(
 		userHandler,
 		quizHandler,
 		questionHandler,
+		tagHandler,
+		mediaHandler,
 		gameHandler,
 		webSocketHandler,
+		adminHandler,
 	}
 }
 
@@ -36,7 +47,10 @@ var HandlerProviderSet = wire.NewSet(
 	ProvideUserHandler,
 	ProvideQuizHandler,
 	ProvideQuestionHandler,
+	ProvideTagHandler,
+	ProvideMediaHandler,
 	ProvideSessionHandler,
 	ProvideGameHandler,
 	ProvideWebSocketHandler,
+	ProvideAdminHandler,
 )
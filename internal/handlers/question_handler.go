@@ -7,6 +7,7 @@ import (
 	"github.com/nghiavan0610/btaskee-quiz-service/config"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/guards"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/services"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/middlewares"
@@ -46,7 +47,9 @@ func ProvideQuestionHandler(
 }
 
 func (h *questionHandler) RegisterRoutes(r fiber.Router) {
-	questionGroup := r.Group("/questions", h.authGuard.AccessTokenGuard())
+	questionGroup := r.Group("/questions",
+		h.authGuard.APIKeyOrAccessTokenGuard(models.APIKeyScopeQuizRead, models.APIKeyScopeQuizWrite),
+	)
 
 	questionGroup.Post("/",
 		middlewares.RateLimit(middlewares.RateLimitConfig{
@@ -61,6 +64,15 @@ func (h *questionHandler) RegisterRoutes(r fiber.Router) {
 		middlewares.BodyValidator[dtos.UpdateQuestionIndexRequest](),
 		h.updateQuestionIndex,
 	)
+	questionGroup.Put("/bulk",
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.2,
+			BurstSize:         3,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("question_bulk_upsert"),
+		}),
+		middlewares.BodyValidator[dtos.BulkUpsertQuestionsRequest](),
+		h.bulkUpsertQuestions,
+	)
 	questionGroup.Put("/:question_id",
 		middlewares.RateLimit(middlewares.RateLimitConfig{
 			RequestsPerSecond: 1,
@@ -140,3 +152,19 @@ func (h *questionHandler) deleteQuestion(c *fiber.Ctx) error {
 
 	return response.Success(c, true)
 }
+
+func (h *questionHandler) bulkUpsertQuestions(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.BulkUpsertQuestionsRequest](c, constants.KEY_REQ_BODY_PARAMS)
+
+	res, appErr := h.questionService.BulkUpsertQuestions(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
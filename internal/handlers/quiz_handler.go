@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"io"
+	"strings"
 	"sync"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/guards"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/services"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/middlewares"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/response"
 )
@@ -18,8 +23,11 @@ type (
 	}
 
 	quizHandler struct {
-		quizService services.QuizService
-		authGuard   guards.AuthGuard
+		quizService             services.QuizService
+		quizVersionService      services.QuizVersionService
+		tagService              services.TagService
+		quizCollaboratorService services.QuizCollaboratorService
+		authGuard               guards.AuthGuard
 	}
 )
 
@@ -30,12 +38,18 @@ var (
 
 func ProvideQuizHandler(
 	quizService services.QuizService,
+	quizVersionService services.QuizVersionService,
+	tagService services.TagService,
+	quizCollaboratorService services.QuizCollaboratorService,
 	authGuard guards.AuthGuard,
 ) QuizHandler {
 	quizHandlerOnce.Do(func() {
 		quizHandlerInstance = &quizHandler{
-			quizService: quizService,
-			authGuard:   authGuard,
+			quizService:             quizService,
+			quizVersionService:      quizVersionService,
+			tagService:              tagService,
+			quizCollaboratorService: quizCollaboratorService,
+			authGuard:               authGuard,
 		}
 	})
 	return quizHandlerInstance
@@ -44,9 +58,13 @@ func ProvideQuizHandler(
 func (h *quizHandler) RegisterRoutes(r fiber.Router) {
 	quizGroup := r.Group("/quizzes")
 
-	protectedGroup := quizGroup.Group("/mine", h.authGuard.AccessTokenGuard())
+	// Guards are per route: Fiber runs group middleware for the whole prefix, and sharing and
+	// ownership changes must not be reachable with an API key
+	protectedGroup := quizGroup.Group("/mine")
+	quizAccessGuard := h.authGuard.APIKeyOrAccessTokenGuard(models.APIKeyScopeQuizRead, models.APIKeyScopeQuizWrite)
 
 	protectedGroup.Post("/",
+		quizAccessGuard,
 		middlewares.RateLimit(middlewares.RateLimitConfig{
 			RequestsPerSecond: 0.05,
 			BurstSize:         3,
@@ -56,6 +74,7 @@ func (h *quizHandler) RegisterRoutes(r fiber.Router) {
 		h.createQuiz,
 	)
 	protectedGroup.Put("/:quiz_id",
+		quizAccessGuard,
 		middlewares.RateLimit(middlewares.RateLimitConfig{
 			RequestsPerSecond: 0.05,
 			BurstSize:         3,
@@ -65,27 +84,149 @@ func (h *quizHandler) RegisterRoutes(r fiber.Router) {
 		h.updateQuiz,
 	)
 	protectedGroup.Get("/:quiz_id",
+		quizAccessGuard,
 		middlewares.PathParamsValidator[dtos.GetMyQuizDetailRequest](),
 		h.getMyQuizDetail,
 	)
 	protectedGroup.Get("/",
+		quizAccessGuard,
 		middlewares.QueryStringValidator[dtos.GetMyQuizListRequest](),
 		h.getMyQuizList,
 	)
 	protectedGroup.Delete("/:quiz_id",
+		quizAccessGuard,
 		middlewares.PathParamsValidator[dtos.DeleteQuizRequest](),
 		h.deleteQuiz,
 	)
+	protectedGroup.Get("/:quiz_id/lint",
+		quizAccessGuard,
+		middlewares.PathParamsValidator[dtos.LintQuizRequest](),
+		h.lintQuiz,
+	)
+	protectedGroup.Post("/:quiz_id/publish",
+		quizAccessGuard,
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.05,
+			BurstSize:         3,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("quiz_publish"),
+		}),
+		middlewares.PathParamsValidator[dtos.PublishQuizRequest](),
+		h.publishQuiz,
+	)
+	protectedGroup.Get("/:quiz_id/export",
+		quizAccessGuard,
+		middlewares.PayloadValidator[dtos.ExportQuizRequest](),
+		h.exportQuiz,
+	)
+	protectedGroup.Post("/import",
+		quizAccessGuard,
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.05,
+			BurstSize:         3,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("quiz_import"),
+		}),
+		middlewares.BodyValidator[dtos.ImportQuizRequest](),
+		h.importQuiz,
+	)
+
+	protectedGroup.Put("/:quiz_id/tags",
+		quizAccessGuard,
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.05,
+			BurstSize:         3,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("quiz_tags"),
+		}),
+		middlewares.PayloadValidator[dtos.SetQuizTagsRequest](),
+		h.setQuizTags,
+	)
+
+	// Collaborators
+	protectedGroup.Get("/:quiz_id/collaborators",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.PathParamsValidator[dtos.ListQuizCollaboratorsRequest](),
+		h.listCollaborators,
+	)
+	protectedGroup.Post("/:quiz_id/collaborators",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.05,
+			BurstSize:         5,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("quiz_collaborator_invite"),
+		}),
+		middlewares.PayloadValidator[dtos.InviteQuizCollaboratorRequest](),
+		h.inviteCollaborator,
+	)
+	protectedGroup.Put("/:quiz_id/collaborators/:user_id",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.PayloadValidator[dtos.UpdateQuizCollaboratorRequest](),
+		h.updateCollaborator,
+	)
+	protectedGroup.Delete("/:quiz_id/collaborators/:user_id",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.PathParamsValidator[dtos.RemoveQuizCollaboratorRequest](),
+		h.removeCollaborator,
+	)
+	protectedGroup.Post("/:quiz_id/transfer-ownership",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.05,
+			BurstSize:         3,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("quiz_transfer_ownership"),
+		}),
+		middlewares.PayloadValidator[dtos.TransferQuizOwnershipRequest](),
+		h.transferOwnership,
+	)
+
+	// Version history
+	protectedGroup.Get("/:quiz_id/versions",
+		quizAccessGuard,
+		middlewares.PathParamsValidator[dtos.ListQuizVersionsRequest](),
+		h.listQuizVersions,
+	)
+	protectedGroup.Get("/:quiz_id/versions/diff",
+		quizAccessGuard,
+		middlewares.PayloadValidator[dtos.DiffQuizVersionsRequest](),
+		h.diffQuizVersions,
+	)
+	protectedGroup.Get("/:quiz_id/versions/:version",
+		quizAccessGuard,
+		middlewares.PathParamsValidator[dtos.GetQuizVersionRequest](),
+		h.getQuizVersion,
+	)
+	protectedGroup.Post("/:quiz_id/versions/:version/restore",
+		quizAccessGuard,
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.05,
+			BurstSize:         3,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("quiz_version_restore"),
+		}),
+		middlewares.PathParamsValidator[dtos.RestoreQuizVersionRequest](),
+		h.restoreQuizVersion,
+	)
 
 	// Public routes (no auth required)
 	quizGroup.Get("/",
 		middlewares.QueryStringValidator[dtos.GetQuizListRequest](),
 		h.getQuizList,
 	)
+	quizGroup.Get("/by-slug/:slug",
+		middlewares.PathParamsValidator[dtos.GetQuizBySlugRequest](),
+		h.getQuizBySlug,
+	)
 	quizGroup.Get("/:quiz_id",
 		middlewares.PathParamsValidator[dtos.GetQuizDetailRequest](),
 		h.getQuizDetail,
 	)
+	quizGroup.Post("/:quiz_id/fork",
+		h.authGuard.AccessTokenGuard(),
+		middlewares.RateLimit(middlewares.RateLimitConfig{
+			RequestsPerSecond: 0.05,
+			BurstSize:         3,
+			KeyGenerator:      middlewares.DefaultKeyGenerator("quiz_fork"),
+		}),
+		middlewares.PayloadValidator[dtos.ForkQuizRequest](),
+		h.forkQuiz,
+	)
 
 }
 
@@ -169,6 +310,269 @@ func (h *quizHandler) deleteQuiz(c *fiber.Ctx) error {
 	return response.Success(c, true)
 }
 
+func (h *quizHandler) lintQuiz(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.LintQuizRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	res, appErr := h.quizService.LintQuiz(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) publishQuiz(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.PublishQuizRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	res, appErr := h.quizService.PublishQuiz(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) forkQuiz(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.ForkQuizRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.quizService.ForkQuiz(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) exportQuiz(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.ExportQuizRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.quizService.ExportQuiz(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	c.Attachment(res.FileName)
+	c.Set(fiber.HeaderContentType, res.ContentType)
+	return c.Send(res.Content)
+}
+
+func (h *quizHandler) importQuiz(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.ImportQuizRequest](c, constants.KEY_REQ_BODY_PARAMS)
+
+	// Multipart uploads carry the file separately from the form fields
+	if req.Content == "" {
+		if fileHeader, err := c.FormFile("file"); err == nil {
+			file, err := fileHeader.Open()
+			if err != nil {
+				return response.Error(c, exception.BadRequest(errors.CodeBadRequest, errors.ErrInvalidQuizImportFile).WithDetails(err.Error()))
+			}
+			defer file.Close()
+
+			content, err := io.ReadAll(file)
+			if err != nil {
+				return response.Error(c, exception.BadRequest(errors.CodeBadRequest, errors.ErrInvalidQuizImportFile).WithDetails(err.Error()))
+			}
+			req.Content = string(content)
+		}
+	}
+
+	if req.Content == "" {
+		return response.Error(c, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizImportFile).WithDetails("file or content is required"))
+	}
+
+	res, appErr := h.quizService.ImportQuiz(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) setQuizTags(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.SetQuizTagsRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.tagService.SetQuizTags(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) listCollaborators(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.ListQuizCollaboratorsRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	res, appErr := h.quizCollaboratorService.ListCollaborators(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) inviteCollaborator(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.InviteQuizCollaboratorRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.quizCollaboratorService.InviteCollaborator(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) updateCollaborator(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.UpdateQuizCollaboratorRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.quizCollaboratorService.UpdateCollaborator(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) removeCollaborator(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.RemoveQuizCollaboratorRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	appErr = h.quizCollaboratorService.RemoveCollaborator(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
+
+func (h *quizHandler) transferOwnership(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.TransferQuizOwnershipRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.quizCollaboratorService.TransferOwnership(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) listQuizVersions(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.ListQuizVersionsRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	res, appErr := h.quizVersionService.ListQuizVersions(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) getQuizVersion(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.GetQuizVersionRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	res, appErr := h.quizVersionService.GetQuizVersion(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) diffQuizVersions(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.DiffQuizVersionsRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.quizVersionService.DiffQuizVersions(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *quizHandler) restoreQuizVersion(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.RestoreQuizVersionRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	res, appErr := h.quizVersionService.RestoreQuizVersion(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
 func (h *quizHandler) getQuizList(c *fiber.Ctx) error {
 	req := middlewares.GetRequest[dtos.GetQuizListRequest](c, constants.KEY_REQ_QUERY_PARAMS)
 
@@ -192,3 +596,21 @@ func (h *quizHandler) getQuizDetail(c *fiber.Ctx) error {
 
 	return response.Success(c, res)
 }
+
+func (h *quizHandler) getQuizBySlug(c *fiber.Ctx) error {
+	authUser, _ := h.authGuard.GetAuthUser(c)
+
+	req := middlewares.GetRequest[dtos.GetQuizBySlugRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	res, appErr := h.quizService.GetQuizBySlug(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	// Old slugs permanently redirect to the quiz's current one
+	if res.Slug != nil && *res.Slug != req.Slug {
+		return c.Redirect(strings.TrimSuffix(c.Path(), req.Slug)+*res.Slug, fiber.StatusMovedPermanently)
+	}
+
+	return response.Success(c, res)
+}
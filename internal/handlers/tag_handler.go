@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/services"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/middlewares"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/response"
+)
+
+type (
+	TagHandler interface {
+		RegisterRoutes(r fiber.Router)
+	}
+
+	tagHandler struct {
+		tagService services.TagService
+	}
+)
+
+var (
+	tagHandlerOnce     sync.Once
+	tagHandlerInstance TagHandler
+)
+
+func ProvideTagHandler(tagService services.TagService) TagHandler {
+	tagHandlerOnce.Do(func() {
+		tagHandlerInstance = &tagHandler{
+			tagService: tagService,
+		}
+	})
+	return tagHandlerInstance
+}
+
+func (h *tagHandler) RegisterRoutes(r fiber.Router) {
+	// Public routes (no auth required)
+	categoryGroup := r.Group("/categories")
+
+	categoryGroup.Get("/", h.listCategories)
+	categoryGroup.Get("/:slug/quizzes",
+		middlewares.PayloadValidator[dtos.GetCategoryQuizzesRequest](),
+		h.getCategoryQuizzes,
+	)
+
+	tagGroup := r.Group("/tags")
+
+	tagGroup.Get("/popular",
+		middlewares.QueryStringValidator[dtos.GetPopularTagsRequest](),
+		h.getPopularTags,
+	)
+}
+
+func (h *tagHandler) listCategories(c *fiber.Ctx) error {
+	res, appErr := h.tagService.ListCategories(c.Context())
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *tagHandler) getCategoryQuizzes(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.GetCategoryQuizzesRequest](c, constants.KEY_REQ_PAYLOAD_PARAMS)
+
+	res, appErr := h.tagService.GetCategoryQuizzes(c.Context(), req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *tagHandler) getPopularTags(c *fiber.Ctx) error {
+	req := middlewares.GetRequest[dtos.GetPopularTagsRequest](c, constants.KEY_REQ_QUERY_PARAMS)
+
+	res, appErr := h.tagService.GetPopularTags(c.Context(), req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
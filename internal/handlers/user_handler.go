@@ -19,8 +19,9 @@ type (
 	}
 
 	userHandler struct {
-		userService services.UserService
-		authGuard   guards.AuthGuard
+		userService   services.UserService
+		apiKeyService services.APIKeyService
+		authGuard     guards.AuthGuard
 	}
 )
 
@@ -29,9 +30,9 @@ var (
 	userHandlerInstance UserHandler
 )
 
-func ProvideUserHandler(userService services.UserService, authGuard guards.AuthGuard) UserHandler {
+func ProvideUserHandler(userService services.UserService, apiKeyService services.APIKeyService, authGuard guards.AuthGuard) UserHandler {
 	userHandlerOnce.Do(func() {
-		userHandlerInstance = &userHandler{userService, authGuard}
+		userHandlerInstance = &userHandler{userService, apiKeyService, authGuard}
 	})
 	return userHandlerInstance
 }
@@ -45,6 +46,17 @@ func (h *userHandler) RegisterRoutes(r fiber.Router) {
 		middlewares.BodyValidator[dtos.UpdateMineRequest](),
 		h.updateMine,
 	)
+
+	// API keys are managed with a signed-in session only, so a leaked key cannot mint broader ones
+	protectedGroup.Get("/mine/api-keys", h.getAPIKeys)
+	protectedGroup.Post("/mine/api-keys",
+		middlewares.BodyValidator[dtos.CreateAPIKeyRequest](),
+		h.createAPIKey,
+	)
+	protectedGroup.Delete("/mine/api-keys/:api_key_id",
+		middlewares.PathParamsValidator[dtos.DeleteAPIKeyRequest](),
+		h.deleteAPIKey,
+	)
 }
 
 func (h *userHandler) getMine(c *fiber.Ctx) error {
@@ -76,3 +88,49 @@ func (h *userHandler) updateMine(c *fiber.Ctx) error {
 
 	return response.Success(c, res)
 }
+
+func (h *userHandler) getAPIKeys(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	res, appErr := h.apiKeyService.GetAPIKeys(c.Context(), authUser)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *userHandler) createAPIKey(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.CreateAPIKeyRequest](c, constants.KEY_REQ_BODY_PARAMS)
+
+	res, appErr := h.apiKeyService.CreateAPIKey(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, res)
+}
+
+func (h *userHandler) deleteAPIKey(c *fiber.Ctx) error {
+	authUser, appErr := h.authGuard.GetAuthUser(c)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	req := middlewares.GetRequest[dtos.DeleteAPIKeyRequest](c, constants.KEY_REQ_PATH_PARAMS)
+
+	appErr = h.apiKeyService.DeleteAPIKey(c.Context(), authUser, req)
+	if appErr != nil {
+		return response.Error(c, appErr)
+	}
+
+	return response.Success(c, true)
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// APIKeyScope limits what a personal API key can do. Requests signed in with a JWT are not scoped.
+type APIKeyScope string
+
+const (
+	APIKeyScopeQuizRead     APIKeyScope = "quiz:read"
+	APIKeyScopeQuizWrite    APIKeyScope = "quiz:write"
+	APIKeyScopeSessionRead  APIKeyScope = "session:read"
+	APIKeyScopeSessionWrite APIKeyScope = "session:write"
+)
+
+// APIKey is a personal key for scripts. The secret is shown once on creation and only its hash is stored.
+type APIKey struct {
+	ID         int64         `json:"id"`
+	UserID     int64         `json:"user_id"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"` // First characters of the key, to tell keys apart
+	Scopes     []APIKeyScope `json:"scopes"`
+	ExpiresAt  *time.Time    `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time    `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+
+	// Owner is loaded when a key is looked up to authenticate a request
+	Owner *User `json:"-"`
+}
+
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
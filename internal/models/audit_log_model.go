@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Audit log actions
+const (
+	AuditActionRefreshTokenReused = "auth.refresh_token_reused"
+	AuditActionUserDeactivated    = "admin.user_deactivated"
+	AuditActionUserReactivated    = "admin.user_reactivated"
+	AuditActionQuizTakenDown      = "admin.quiz_taken_down"
+	AuditActionQuizDeleted        = "admin.quiz_deleted"
+	AuditActionSessionEnded       = "admin.session_ended"
+)
+
+// Audit log target types
+const (
+	AuditTargetUser    = "user"
+	AuditTargetQuiz    = "quiz"
+	AuditTargetSession = "session"
+)
+
+type AuditLog struct {
+	ID         int64                  `json:"id"`
+	ActorID    *int64                 `json:"actor_id,omitempty"`
+	Action     string                 `json:"action"`
+	TargetType *string                `json:"target_type,omitempty"`
+	TargetID   *int64                 `json:"target_id,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// AuthSession is one signed-in device of a user
+type AuthSession struct {
+	ID              string    `json:"id"`
+	IP              string    `json:"ip,omitempty"`
+	UserAgent       string    `json:"user_agent,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	Current         bool      `json:"current"`
+}
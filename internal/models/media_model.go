@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+type MediaKind string
+
+const (
+	MediaKindImage MediaKind = "image"
+	MediaKindAudio MediaKind = "audio"
+)
+
+// Media is an uploaded file owned by a user
+type Media struct {
+	ID           int64     `json:"id"`
+	OwnerID      int64     `json:"owner_id"`
+	Kind         MediaKind `json:"kind"`
+	ContentType  string    `json:"content_type"`
+	SizeBytes    int64     `json:"size_bytes"`
+	OriginalName *string   `json:"original_name,omitempty"`
+	URL          string    `json:"url"`
+	StorageKey   string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// MediaAttachment references uploaded media from a question or an answer option.
+// Clients only send the id; the rest is filled in from the media record when the question is saved.
+type MediaAttachment struct {
+	ID          int64     `json:"id" validate:"required"`
+	Kind        MediaKind `json:"kind,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	URL         string    `json:"url,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UserTOTP is a user's authenticator app enrolment. It only protects sign-in once EnabledAt is set.
+type UserTOTP struct {
+	UserID       int64      `json:"user_id"`
+	Secret       string     `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep *int64     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (t *UserTOTP) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
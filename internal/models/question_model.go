@@ -14,29 +14,51 @@ const (
 	QuestionTypeTextInput      = sqlc.QuestionTypeTextInput
 )
 
-type TimeLimitType = sqlc.TimeLimitType
+// ContentFormat tells how question and answer text is written
+type ContentFormat string
 
 const (
-	TimeLimitType5  = sqlc.TimeLimitType5  // 5 seconds
-	TimeLimitType10 = sqlc.TimeLimitType10 // 10 seconds
-	TimeLimitType20 = sqlc.TimeLimitType20 // 20 seconds
-	TimeLimitType45 = sqlc.TimeLimitType45 // 45 seconds
-	TimeLimitType80 = sqlc.TimeLimitType80 // 80 seconds
+	ContentFormatPlain    ContentFormat = "plain"
+	ContentFormatMarkdown ContentFormat = "markdown" // CommonMark with GFM tables, code fences and TeX math
 )
 
 type AnswerData struct {
-	Text      string `json:"text"`
-	IsCorrect bool   `json:"is_correct"`
+	Text      string           `json:"text"`
+	TextHTML  string           `json:"text_html,omitempty"` // Rendered from Text on read, never stored
+	IsCorrect bool             `json:"is_correct"`
+	Media     *MediaAttachment `json:"media,omitempty"`
 }
 
 type Question struct {
-	ID        int64         `json:"id"`
-	QuizID    int64         `json:"quiz_id"`
-	Question  string        `json:"question"`
-	Type      QuestionType  `json:"type"`
-	Answers   []AnswerData  `json:"answers"`
-	TimeLimit TimeLimitType `json:"time_limit"`
-	Index     int32         `json:"index"`
-	CreatedAt time.Time     `json:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at"`
+	ID               int64            `json:"id"`
+	QuizID           int64            `json:"quiz_id"`
+	Question         string           `json:"question"`
+	QuestionHTML     string           `json:"question_html"` // Sanitized HTML rendered from Question
+	ContentFormat    ContentFormat    `json:"content_format"`
+	Type             QuestionType     `json:"type"`
+	Answers          []AnswerData     `json:"answers"`
+	TimeLimit        int32            `json:"time_limit"`        // seconds
+	PointsMultiplier int32            `json:"points_multiplier"` // 0, 1 or 2
+	Media            *MediaAttachment `json:"media,omitempty"`
+	Explanation      *string          `json:"explanation,omitempty"` // Markdown, revealed to players once the question closes
+	ExplanationHTML  string           `json:"explanation_html,omitempty"`
+	ReferenceURL     *string          `json:"reference_url,omitempty"`
+	Index            int32            `json:"index"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+// QuestionReview is what players get once a question has closed: the correct answers and why they are correct
+type QuestionReview struct {
+	QuestionID      int64            `json:"question_id,omitempty"`
+	Index           int32            `json:"index"`
+	Question        string           `json:"question"`
+	QuestionHTML    string           `json:"question_html"`
+	ContentFormat   ContentFormat    `json:"content_format"`
+	Type            QuestionType     `json:"type"`
+	Answers         []AnswerData     `json:"answers"`
+	Media           *MediaAttachment `json:"media,omitempty"`
+	Explanation     *string          `json:"explanation,omitempty"`
+	ExplanationHTML string           `json:"explanation_html,omitempty"`
+	ReferenceURL    *string          `json:"reference_url,omitempty"`
 }
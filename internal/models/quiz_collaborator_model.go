@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// QuizRole is what a user may do with a quiz. Owners can do everything, editors change the quiz
+// and its questions, viewers only see it.
+type QuizRole string
+
+const (
+	QuizRoleOwner  QuizRole = "owner"
+	QuizRoleEditor QuizRole = "editor"
+	QuizRoleViewer QuizRole = "viewer"
+)
+
+var quizRoleRanks = map[QuizRole]int{
+	QuizRoleViewer: 1,
+	QuizRoleEditor: 2,
+	QuizRoleOwner:  3,
+}
+
+// Includes reports whether the role grants at least the required one
+func (r QuizRole) Includes(required QuizRole) bool {
+	return quizRoleRanks[r] > 0 && quizRoleRanks[r] >= quizRoleRanks[required]
+}
+
+type QuizCollaborator struct {
+	QuizID    int64     `json:"quiz_id"`
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	AvatarURL *string   `json:"avatar_url"`
+	Role      QuizRole  `json:"role"`
+	InvitedBy *int64    `json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+type QuizLintSeverity string
+
+const (
+	QuizLintSeverityError   QuizLintSeverity = "error"   // Blocks publishing
+	QuizLintSeverityWarning QuizLintSeverity = "warning" // Reported, publishing goes ahead
+)
+
+// QuizLintIssue is one problem found in a quiz. Question fields are unset for quiz-level issues.
+type QuizLintIssue struct {
+	Severity      QuizLintSeverity `json:"severity"`
+	Code          string           `json:"code"`
+	Message       string           `json:"message"`
+	QuestionID    *int64           `json:"question_id,omitempty"`
+	QuestionIndex *int32           `json:"question_index,omitempty"`
+	AnswerIndexes []int            `json:"answer_indexes,omitempty"`
+}
+
+type QuizLintReport struct {
+	Publishable bool            `json:"publishable"`
+	Errors      int             `json:"errors"`
+	Warnings    int             `json:"warnings"`
+	Issues      []QuizLintIssue `json:"issues"`
+}
@@ -11,6 +11,7 @@ type QuizVisibility = sqlc.QuizVisibility
 
 const (
 	QuizVisibilityPrivate   = sqlc.QuizVisibilityPrivate
+	QuizVisibilityUnlisted  = sqlc.QuizVisibilityUnlisted
 	QuizVisibilityPublished = sqlc.QuizVisibilityPublished
 )
 
@@ -21,6 +22,13 @@ type Owner struct {
 	AvatarURL *string `json:"avatar_url"`
 }
 
+// ForkedFrom attributes a forked quiz to its source. Title is only exposed while the source is published.
+type ForkedFrom struct {
+	QuizID        int64   `json:"quiz_id"`
+	Title         *string `json:"title,omitempty"`
+	OwnerUsername *string `json:"owner_username,omitempty"`
+}
+
 type Quiz struct {
 	ID                   int64          `json:"id"`
 	Title                string         `json:"title"`
@@ -36,8 +44,24 @@ type Quiz struct {
 	CreatedAt            time.Time      `json:"created_at"`
 	UpdatedAt            time.Time      `json:"updated_at"`
 	PublishedAt          *time.Time     `json:"published_at,omitempty"`
+	ForkedFromQuizID     *int64         `json:"forked_from_quiz_id,omitempty"`
+	ForkedFrom           *ForkedFrom    `json:"forked_from,omitempty"`
+	Relevance            *float32       `json:"relevance,omitempty"` // Search rank, only set by catalogue searches
+	Tags                 []Tag          `json:"tags,omitempty"`
 	Questions            []Question     `json:"questions,omitempty"`
 	Owner                *Owner         `json:"owner,omitempty"`
+	Role                 QuizRole       `json:"role,omitempty"` // The caller's access, only set on the author views
+}
+
+// PublicQuizFilter narrows the public catalogue. Nil fields are not filtered on.
+type PublicQuizFilter struct {
+	Query        *string
+	OwnerID      *int64
+	MinQuestions *int32
+	MaxQuestions *int32
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time // Exclusive
+	Tag          *string    // Tag or category slug
 }
 
 type MyQuizListResponse struct {
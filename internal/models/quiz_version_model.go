@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+)
+
+type QuizVersionReason string
+
+const (
+	QuizVersionReasonSave    QuizVersionReason = "save"
+	QuizVersionReasonPublish QuizVersionReason = "publish"
+	QuizVersionReasonRestore QuizVersionReason = "restore"
+	QuizVersionReasonSession QuizVersionReason = "session"
+)
+
+// QuizVersionQuestion is the frozen copy of a question stored inside a version. QuestionID is the id the
+// question had when the version was taken, so sessions pinned to the version answer with real ids;
+// restoring a version creates new rows, which the restore's own version records.
+type QuizVersionQuestion struct {
+	QuestionID       int64            `json:"question_id,omitempty"`
+	Index            int32            `json:"index"`
+	Question         string           `json:"question"`
+	ContentFormat    ContentFormat    `json:"content_format,omitempty"`
+	Type             QuestionType     `json:"type"`
+	Answers          []AnswerData     `json:"answers"`
+	TimeLimit        int32            `json:"time_limit"`
+	PointsMultiplier int32            `json:"points_multiplier"`
+	Media            *MediaAttachment `json:"media,omitempty"`
+	Explanation      *string          `json:"explanation,omitempty"`
+	ReferenceURL     *string          `json:"reference_url,omitempty"`
+}
+
+type QuizVersion struct {
+	ID              int64                 `json:"id"`
+	QuizID          int64                 `json:"quiz_id"`
+	Version         int32                 `json:"version"`
+	Title           string                `json:"title"`
+	Description     *string               `json:"description,omitempty"`
+	Visibility      QuizVisibility        `json:"visibility"`
+	MaxParticipants *int32                `json:"max_participants,omitempty"`
+	ContentHash     string                `json:"content_hash"`
+	Reason          QuizVersionReason     `json:"reason"`
+	CreatedBy       *int64                `json:"created_by,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	QuestionCount   int32                 `json:"question_count"`
+	Questions       []QuizVersionQuestion `json:"questions,omitempty"`
+}
+
+type QuizVersionFieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type QuizVersionQuestionChangeType string
+
+const (
+	QuizVersionQuestionAdded    QuizVersionQuestionChangeType = "added"
+	QuizVersionQuestionRemoved  QuizVersionQuestionChangeType = "removed"
+	QuizVersionQuestionModified QuizVersionQuestionChangeType = "modified"
+)
+
+type QuizVersionQuestionChange struct {
+	Index  int32                         `json:"index"`
+	Change QuizVersionQuestionChangeType `json:"change"`
+	Fields []string                      `json:"fields,omitempty"`
+	From   *QuizVersionQuestion          `json:"from,omitempty"`
+	To     *QuizVersionQuestion          `json:"to,omitempty"`
+}
+
+type QuizVersionDiff struct {
+	QuizID      int64                       `json:"quiz_id"`
+	FromVersion int32                       `json:"from_version"`
+	ToVersion   int32                       `json:"to_version"`
+	Fields      []QuizVersionFieldChange    `json:"fields"`
+	Questions   []QuizVersionQuestionChange `json:"questions"`
+}
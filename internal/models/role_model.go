@@ -0,0 +1,39 @@
+package models
+
+import "github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+
+type UserRole = sqlc.UserRole
+
+const (
+	UserRolePlayer = sqlc.UserRolePlayer
+	UserRoleAdmin  = sqlc.UserRoleAdmin
+)
+
+// Permission is checked by RoleGuard. Routes ask for a permission rather than a role, so roles can be
+// reshaped without touching them.
+type Permission string
+
+const (
+	PermissionManageUsers    Permission = "users:manage"
+	PermissionManageQuizzes  Permission = "quizzes:manage"
+	PermissionManageSessions Permission = "sessions:manage"
+)
+
+var rolePermissions = map[UserRole][]Permission{
+	UserRolePlayer: {},
+	UserRoleAdmin: {
+		PermissionManageUsers,
+		PermissionManageQuizzes,
+		PermissionManageSessions,
+	},
+}
+
+// HasPermission reports whether the role grants the permission
+func HasPermission(role UserRole, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -26,6 +26,7 @@ type QuizSession struct {
 	EndedAt              time.Time     `json:"ended_at,omitempty"`
 	CreatedAt            time.Time     `json:"created_at"`
 	UpdatedAt            time.Time     `json:"updated_at"`
+	QuizVersionID        *int64        `json:"quiz_version_id,omitempty"`
 
 	// Related data
 	Quiz         *Quiz                 `json:"quiz,omitempty"`
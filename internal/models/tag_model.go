@@ -0,0 +1,11 @@
+package models
+
+// Tag labels a quiz. Categories are curated tags that can be browsed publicly.
+type Tag struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Slug        string  `json:"slug"`
+	IsCategory  bool    `json:"is_category"`
+	Description *string `json:"description,omitempty"`
+	QuizCount   *int64  `json:"quiz_count,omitempty"` // Published quizzes only, set by browse endpoints
+}
@@ -3,13 +3,23 @@ package models
 import "time"
 
 type User struct {
-	ID          int64      `json:"id"`
-	Username    string     `json:"username"`
-	Email       string     `json:"email"`
-	Password    string     `json:"-"`
-	AvatarURL   *string    `json:"avatar_url,omitempty"`
-	IsActive    bool       `json:"is_active"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	ID              int64      `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	Password        string     `json:"-"`
+	AvatarURL       *string    `json:"avatar_url,omitempty"`
+	IsActive        bool       `json:"is_active"`
+	Role            UserRole   `json:"role"`
+	EmailVerified   bool       `json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	LastLoginAt     *time.Time `json:"last_login_at,omitempty"`
+}
+
+// UserFilter narrows the admin user list. Nil fields are not filtered on.
+type UserFilter struct {
+	Query    *string // Matches username or email
+	IsActive *bool
+	Role     *UserRole
 }
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+)
+
+type (
+	APIKeyRepository interface {
+		CreateAPIKey(ctx context.Context, apiKey *models.APIKey, keyHash string) (*models.APIKey, error)
+		// GetAPIKeyByHash returns the key with its Owner loaded
+		GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+		GetAPIKeysByUser(ctx context.Context, userID int64) ([]*models.APIKey, error)
+		CountAPIKeysByUser(ctx context.Context, userID int64) (int64, error)
+		// DeleteAPIKey reports false when the user has no such key
+		DeleteAPIKey(ctx context.Context, id, userID int64) (bool, error)
+		// DeleteAPIKeysByUser removes every key of the user and returns how many there were
+		DeleteAPIKeysByUser(ctx context.Context, userID int64) (int64, error)
+		// TouchAPIKeyLastUsed writes at most once a minute per key, so busy scripts do not turn every
+		// request into an UPDATE
+		TouchAPIKeyLastUsed(ctx context.Context, id int64) error
+	}
+
+	apiKeyRepository struct {
+		queries *sqlc.Queries
+	}
+)
+
+func ProvideAPIKeyRepository(queries *sqlc.Queries) APIKeyRepository {
+	return &apiKeyRepository{
+		queries: queries,
+	}
+}
+
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *apiKeyRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, apiKey *models.APIKey, keyHash string) (*models.APIKey, error) {
+	scopes := make([]string, len(apiKey.Scopes))
+	for i, scope := range apiKey.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if apiKey.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *apiKey.ExpiresAt, Valid: true}
+	}
+
+	result, err := r.getQueries(ctx).CreateAPIKey(ctx, sqlc.CreateAPIKeyParams{
+		UserID:    apiKey.UserID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		KeyHash:   keyHash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToAPIKeyModel(result), nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	result, err := r.getQueries(ctx).GetAPIKeyByHash(ctx, keyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := transformers.ConvertToAPIKeyModel(sqlc.ApiKey{
+		ID:         result.ID,
+		UserID:     result.UserID,
+		Name:       result.Name,
+		Prefix:     result.Prefix,
+		KeyHash:    result.KeyHash,
+		Scopes:     result.Scopes,
+		ExpiresAt:  result.ExpiresAt,
+		LastUsedAt: result.LastUsedAt,
+		CreatedAt:  result.CreatedAt,
+	})
+	apiKey.Owner = &models.User{
+		ID:       result.UserID,
+		Username: result.Username,
+		Email:    result.Email,
+		IsActive: result.IsActive,
+		Role:     result.Role,
+	}
+
+	return apiKey, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeysByUser(ctx context.Context, userID int64) ([]*models.APIKey, error) {
+	results, err := r.getQueries(ctx).ListAPIKeysByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys := make([]*models.APIKey, len(results))
+	for i, result := range results {
+		apiKeys[i] = transformers.ConvertToAPIKeyModel(result)
+	}
+
+	return apiKeys, nil
+}
+
+func (r *apiKeyRepository) CountAPIKeysByUser(ctx context.Context, userID int64) (int64, error) {
+	return r.getQueries(ctx).CountAPIKeysByUser(ctx, userID)
+}
+
+func (r *apiKeyRepository) DeleteAPIKey(ctx context.Context, id, userID int64) (bool, error) {
+	rows, err := r.getQueries(ctx).DeleteAPIKey(ctx, sqlc.DeleteAPIKeyParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *apiKeyRepository) DeleteAPIKeysByUser(ctx context.Context, userID int64) (int64, error) {
+	return r.getQueries(ctx).DeleteAPIKeysByUser(ctx, userID)
+}
+
+func (r *apiKeyRepository) TouchAPIKeyLastUsed(ctx context.Context, id int64) error {
+	return r.getQueries(ctx).TouchAPIKeyLastUsed(ctx, id)
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+)
+
+type (
+	AuditLogRepository interface {
+		CreateAuditLog(ctx context.Context, auditLog *models.AuditLog) (*models.AuditLog, error)
+	}
+
+	auditLogRepository struct {
+		queries *sqlc.Queries
+	}
+)
+
+func ProvideAuditLogRepository(queries *sqlc.Queries) AuditLogRepository {
+	return &auditLogRepository{
+		queries: queries,
+	}
+}
+
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *auditLogRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
+func (r *auditLogRepository) CreateAuditLog(ctx context.Context, auditLog *models.AuditLog) (*models.AuditLog, error) {
+	metadata := auditLog.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.getQueries(ctx).CreateAuditLog(ctx, sqlc.CreateAuditLogParams{
+		ActorID:    auditLog.ActorID,
+		Action:     auditLog.Action,
+		TargetType: auditLog.TargetType,
+		TargetID:   auditLog.TargetID,
+		Metadata:   metadataJSON,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToAuditLogModel(result), nil
+}
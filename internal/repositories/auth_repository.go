@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"time"
 
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
@@ -13,6 +15,10 @@ type (
 		CheckEmailOrUsernameExists(ctx context.Context, email, username string) (*dtos.SignUpConflictResult, error)
 		RegisterAccount(ctx context.Context, user *models.User) (*models.User, error)
 		GetUserByEmailIncludePassword(ctx context.Context, email string) (*models.User, error)
+		GetUserByIDIncludePassword(ctx context.Context, id int64) (*models.User, error)
+		UpdatePassword(ctx context.Context, id int64, hashedPassword string) error
+		// MarkEmailVerified reports false when the user was already verified or the email no longer matches
+		MarkEmailVerified(ctx context.Context, id int64, email string) (bool, error)
 	}
 
 	authRepository struct {
@@ -26,13 +32,18 @@ func ProvideAuthRepository(queries *sqlc.Queries) AuthRepository {
 	}
 }
 
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *authRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
 func (r *authRepository) CheckEmailOrUsernameExists(ctx context.Context, email, username string) (*dtos.SignUpConflictResult, error) {
 	params := sqlc.CheckEmailOrUsernameExistsParams{
 		Email:    email,
 		Username: username,
 	}
 
-	result, err := r.queries.CheckEmailOrUsernameExists(ctx, params)
+	result, err := r.getQueries(ctx).CheckEmailOrUsernameExists(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -57,34 +68,76 @@ func (r *authRepository) RegisterAccount(ctx context.Context, user *models.User)
 		Password: user.Password,
 	}
 
-	result, err := r.queries.RegisterAccount(ctx, params)
+	result, err := r.getQueries(ctx).RegisterAccount(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.User{
-		ID:        result.ID,
-		Username:  result.Username,
-		Email:     result.Email,
-		IsActive:  result.IsActive,
-		CreatedAt: result.CreatedAt.Time,
-		UpdatedAt: result.UpdatedAt.Time,
+		ID:            result.ID,
+		Username:      result.Username,
+		Email:         result.Email,
+		IsActive:      result.IsActive,
+		Role:          result.Role,
+		EmailVerified: result.EmailVerified,
+		CreatedAt:     result.CreatedAt.Time,
+		UpdatedAt:     result.UpdatedAt.Time,
 	}, nil
 }
 
 func (r *authRepository) GetUserByEmailIncludePassword(ctx context.Context, email string) (*models.User, error) {
-	result, err := r.queries.GetUserByEmailIncludePassword(ctx, email)
+	result, err := r.getQueries(ctx).GetUserByEmailIncludePassword(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 
+	return convertUserIncludePassword(result), nil
+}
+
+func (r *authRepository) GetUserByIDIncludePassword(ctx context.Context, id int64) (*models.User, error) {
+	result, err := r.getQueries(ctx).GetUserByIDIncludePassword(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertUserIncludePassword(result), nil
+}
+
+func (r *authRepository) UpdatePassword(ctx context.Context, id int64, hashedPassword string) error {
+	return r.getQueries(ctx).UpdateUserPassword(ctx, sqlc.UpdateUserPasswordParams{
+		ID:       id,
+		Password: hashedPassword,
+	})
+}
+
+func (r *authRepository) MarkEmailVerified(ctx context.Context, id int64, email string) (bool, error) {
+	rows, err := r.getQueries(ctx).MarkUserEmailVerified(ctx, sqlc.MarkUserEmailVerifiedParams{
+		ID:    id,
+		Email: email,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func convertUserIncludePassword(result sqlc.User) *models.User {
+	var emailVerifiedAt *time.Time
+	if result.EmailVerifiedAt.Valid {
+		emailVerifiedAt = &result.EmailVerifiedAt.Time
+	}
+
 	return &models.User{
-		ID:        result.ID,
-		Username:  result.Username,
-		Email:     result.Email,
-		Password:  result.Password,
-		IsActive:  result.IsActive,
-		CreatedAt: result.CreatedAt.Time,
-		UpdatedAt: result.UpdatedAt.Time,
-	}, nil
+		ID:              result.ID,
+		Username:        result.Username,
+		Email:           result.Email,
+		Password:        result.Password,
+		IsActive:        result.IsActive,
+		Role:            result.Role,
+		EmailVerified:   result.EmailVerified,
+		EmailVerifiedAt: emailVerifiedAt,
+		CreatedAt:       result.CreatedAt.Time,
+		UpdatedAt:       result.UpdatedAt.Time,
+	}
 }
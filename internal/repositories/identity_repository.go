@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+)
+
+type (
+	IdentityRepository interface {
+		GetIdentity(ctx context.Context, provider, subject string) (*models.Identity, error)
+		CreateIdentity(ctx context.Context, identity *models.Identity) (*models.Identity, error)
+		// UpdateIdentityLogin records a sign-in and the email the provider reported with it
+		UpdateIdentityLogin(ctx context.Context, id int64, email *string) error
+	}
+
+	identityRepository struct {
+		queries *sqlc.Queries
+	}
+)
+
+func ProvideIdentityRepository(queries *sqlc.Queries) IdentityRepository {
+	return &identityRepository{
+		queries: queries,
+	}
+}
+
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *identityRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
+func (r *identityRepository) GetIdentity(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	result, err := r.getQueries(ctx).GetIdentityByProviderSubject(ctx, sqlc.GetIdentityByProviderSubjectParams{
+		Provider: provider,
+		Subject:  subject,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToIdentityModel(result), nil
+}
+
+func (r *identityRepository) CreateIdentity(ctx context.Context, identity *models.Identity) (*models.Identity, error) {
+	result, err := r.getQueries(ctx).CreateIdentity(ctx, sqlc.CreateIdentityParams{
+		UserID:   identity.UserID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToIdentityModel(result), nil
+}
+
+func (r *identityRepository) UpdateIdentityLogin(ctx context.Context, id int64, email *string) error {
+	return r.getQueries(ctx).UpdateIdentityLogin(ctx, sqlc.UpdateIdentityLoginParams{
+		ID:    id,
+		Email: email,
+	})
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+)
+
+type (
+	MediaRepository interface {
+		CreateMedia(ctx context.Context, media *models.Media) (*models.Media, error)
+		GetMediaByIDs(ctx context.Context, ids []int64) ([]*models.Media, error)
+	}
+
+	mediaRepository struct {
+		queries *sqlc.Queries
+	}
+)
+
+func ProvideMediaRepository(queries *sqlc.Queries) MediaRepository {
+	return &mediaRepository{
+		queries: queries,
+	}
+}
+
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *mediaRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
+func (r *mediaRepository) CreateMedia(ctx context.Context, media *models.Media) (*models.Media, error) {
+	result, err := r.getQueries(ctx).CreateMedia(ctx, sqlc.CreateMediaParams{
+		OwnerID:      media.OwnerID,
+		StorageKey:   media.StorageKey,
+		Kind:         string(media.Kind),
+		ContentType:  media.ContentType,
+		SizeBytes:    media.SizeBytes,
+		OriginalName: media.OriginalName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToMediaModel(result), nil
+}
+
+func (r *mediaRepository) GetMediaByIDs(ctx context.Context, ids []int64) ([]*models.Media, error) {
+	results, err := r.getQueries(ctx).GetMediaByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	media := make([]*models.Media, len(results))
+	for i, result := range results {
+		media[i] = transformers.ConvertToMediaModel(result)
+	}
+
+	return media, nil
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	goErrors "errors"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/secretbox"
+)
+
+type (
+	// MFARepository seals TOTP secrets before they are written and opens them when read, so callers
+	// only see the plain base32 secret
+	MFARepository interface {
+		GetUserTOTP(ctx context.Context, userID int64) (*models.UserTOTP, error)
+		// UpsertPendingTOTP starts or restarts an enrolment. It returns pgx.ErrNoRows when TOTP is
+		// already enabled, rather than replacing a secret in use.
+		UpsertPendingTOTP(ctx context.Context, userID int64, secret string) (*models.UserTOTP, error)
+		// EnableTOTP completes an enrolment with the step of the code that confirmed it
+		EnableTOTP(ctx context.Context, userID int64, step int64) (bool, error)
+		// UseTOTPStep reports false when the step is not newer than the last one accepted
+		UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error)
+		DeleteTOTP(ctx context.Context, userID int64) error
+
+		CreateRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+		// UseRecoveryCode reports false when there is no unused code with the hash
+		UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+		CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+		DeleteRecoveryCodes(ctx context.Context, userID int64) error
+	}
+
+	mfaRepository struct {
+		queries *sqlc.Queries
+		box     secretbox.Box
+	}
+)
+
+func ProvideMFARepository(queries *sqlc.Queries, box secretbox.Box) MFARepository {
+	return &mfaRepository{
+		queries: queries,
+		box:     box,
+	}
+}
+
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *mfaRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
+func (r *mfaRepository) GetUserTOTP(ctx context.Context, userID int64) (*models.UserTOTP, error) {
+	result, err := r.getQueries(ctx).GetUserTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	userTOTP := transformers.ConvertToUserTOTPModel(result)
+	userTOTP.Secret, err = r.openSecret(ctx, userID, result.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return userTOTP, nil
+}
+
+func (r *mfaRepository) UpsertPendingTOTP(ctx context.Context, userID int64, secret string) (*models.UserTOTP, error) {
+	sealedSecret, err := r.box.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.getQueries(ctx).UpsertPendingUserTOTP(ctx, sqlc.UpsertPendingUserTOTPParams{
+		UserID: userID,
+		Secret: sealedSecret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	userTOTP := transformers.ConvertToUserTOTPModel(result)
+	userTOTP.Secret = secret
+
+	return userTOTP, nil
+}
+
+func (r *mfaRepository) EnableTOTP(ctx context.Context, userID int64, step int64) (bool, error) {
+	rows, err := r.getQueries(ctx).EnableUserTOTP(ctx, sqlc.EnableUserTOTPParams{
+		UserID:       userID,
+		LastUsedStep: &step,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *mfaRepository) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	rows, err := r.getQueries(ctx).UseUserTOTPStep(ctx, sqlc.UseUserTOTPStepParams{
+		UserID:       userID,
+		LastUsedStep: &step,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *mfaRepository) DeleteTOTP(ctx context.Context, userID int64) error {
+	return r.getQueries(ctx).DeleteUserTOTP(ctx, userID)
+}
+
+func (r *mfaRepository) CreateRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	return r.getQueries(ctx).CreateRecoveryCodes(ctx, sqlc.CreateRecoveryCodesParams{
+		UserID:     userID,
+		CodeHashes: codeHashes,
+	})
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	rows, err := r.getQueries(ctx).UseRecoveryCode(ctx, sqlc.UseRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: codeHash,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *mfaRepository) CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	return r.getQueries(ctx).CountUnusedRecoveryCodes(ctx, userID)
+}
+
+func (r *mfaRepository) DeleteRecoveryCodes(ctx context.Context, userID int64) error {
+	return r.getQueries(ctx).DeleteRecoveryCodes(ctx, userID)
+}
+
+// openSecret decrypts a stored secret. One stored in plain text before secrets were sealed is sealed
+// in place, so it is only ever read in the clear once.
+func (r *mfaRepository) openSecret(ctx context.Context, userID int64, stored string) (string, error) {
+	secret, err := r.box.Open(stored)
+	if !goErrors.Is(err, secretbox.ErrNotSealed) {
+		return secret, err
+	}
+
+	sealedSecret, err := r.box.Seal(stored)
+	if err != nil {
+		return "", err
+	}
+	if err := r.getQueries(ctx).UpdateUserTOTPSecret(ctx, sqlc.UpdateUserTOTPSecretParams{
+		UserID: userID,
+		Secret: sealedSecret,
+	}); err != nil {
+		return "", err
+	}
+
+	return stored, nil
+}
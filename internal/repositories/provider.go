@@ -10,4 +10,12 @@ var RepositoryProviderSet = wire.NewSet(
 	ProvideQuizRepository,
 	ProvideQuestionRepository,
 	ProvideSessionRepository,
+	ProvideQuizVersionRepository,
+	ProvideTagRepository,
+	ProvideMediaRepository,
+	ProvideQuizCollaboratorRepository,
+	ProvideAuditLogRepository,
+	ProvideIdentityRepository,
+	ProvideAPIKeyRepository,
+	ProvideMFARepository,
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
@@ -21,6 +22,7 @@ type (
 		UpdateQuestionIndex(ctx context.Context, questionID int64, index int32) error
 		UpdateQuestionIndexesBatch(ctx context.Context, questionIDs []int64, indexes []int32) error
 		DeleteQuestion(ctx context.Context, id int64) error
+		DeleteQuestionsByQuizExcept(ctx context.Context, quizID int64, keepIDs []int64) error
 	}
 
 	questionRepository struct {
@@ -34,22 +36,37 @@ func ProvideQuestionRepository(queries *sqlc.Queries) QuestionRepository {
 	}
 }
 
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *questionRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
 func (r *questionRepository) CreateQuestion(ctx context.Context, question *models.Question) (*models.Question, error) {
 	answersBytes, err := transformers.ConvertAnswersToJSON(question.Answers)
 	if err != nil {
 		return nil, err
 	}
 
-	params := sqlc.CreateQuestionParams{
-		QuizID:    question.QuizID,
-		Question:  question.Question,
-		Type:      sqlc.QuestionType(question.Type),
-		Answers:   answersBytes,
-		Index:     question.Index,
-		TimeLimit: sqlc.TimeLimitType(question.TimeLimit),
+	mediaBytes, err := transformers.ConvertMediaAttachmentToJSON(question.Media)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := r.queries.CreateQuestion(ctx, params)
+	params := sqlc.CreateQuestionParams{
+		QuizID:           question.QuizID,
+		Question:         question.Question,
+		Type:             sqlc.QuestionType(question.Type),
+		Answers:          answersBytes,
+		Index:            question.Index,
+		TimeLimit:        question.TimeLimit,
+		Media:            mediaBytes,
+		ContentFormat:    string(contentFormat(question.ContentFormat)),
+		Explanation:      question.Explanation,
+		ReferenceUrl:     question.ReferenceURL,
+		PointsMultiplier: question.PointsMultiplier,
+	}
+
+	result, err := r.getQueries(ctx).CreateQuestion(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +75,7 @@ func (r *questionRepository) CreateQuestion(ctx context.Context, question *model
 }
 
 func (r *questionRepository) GetQuestionByID(ctx context.Context, id int64) (*models.Question, error) {
-	result, err := r.queries.GetQuestionByID(ctx, id)
+	result, err := r.getQueries(ctx).GetQuestionByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +84,7 @@ func (r *questionRepository) GetQuestionByID(ctx context.Context, id int64) (*mo
 }
 
 func (r *questionRepository) GetMaxQuestionIndexByQuiz(ctx context.Context, id int64) (int32, error) {
-	result, err := r.queries.GetMaxQuestionIndexByQuiz(ctx, id)
+	result, err := r.getQueries(ctx).GetMaxQuestionIndexByQuiz(ctx, id)
 	if err != nil {
 		return 0, err
 	}
@@ -76,7 +93,7 @@ func (r *questionRepository) GetMaxQuestionIndexByQuiz(ctx context.Context, id i
 }
 
 func (r *questionRepository) GetQuestionListByQuiz(ctx context.Context, quizID int64) ([]*models.Question, error) {
-	results, err := r.queries.GetQuestionListByQuiz(ctx, quizID)
+	results, err := r.getQueries(ctx).GetQuestionListByQuiz(ctx, quizID)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +111,7 @@ func (r *questionRepository) GetQuestionListByQuiz(ctx context.Context, quizID i
 }
 
 func (r *questionRepository) CountQuestionsByQuiz(ctx context.Context, quizID int64) (int64, error) {
-	return r.queries.CountQuestionsByQuiz(ctx, quizID)
+	return r.getQueries(ctx).CountQuestionsByQuiz(ctx, quizID)
 }
 
 func (r *questionRepository) UpdateQuestion(ctx context.Context, question *models.Question) (*models.Question, error) {
@@ -104,15 +121,25 @@ func (r *questionRepository) UpdateQuestion(ctx context.Context, question *model
 			WithDetails(err.Error())
 	}
 
-	params := sqlc.UpdateQuestionParams{
-		ID:        question.ID,
-		Question:  question.Question,
-		Type:      sqlc.QuestionType(question.Type),
-		Answers:   answersBytes,
-		TimeLimit: sqlc.TimeLimitType(question.TimeLimit),
+	mediaBytes, err := transformers.ConvertMediaAttachmentToJSON(question.Media)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := r.queries.UpdateQuestion(ctx, params)
+	params := sqlc.UpdateQuestionParams{
+		ID:               question.ID,
+		Question:         question.Question,
+		Type:             sqlc.QuestionType(question.Type),
+		Answers:          answersBytes,
+		TimeLimit:        question.TimeLimit,
+		Media:            mediaBytes,
+		ContentFormat:    string(contentFormat(question.ContentFormat)),
+		Explanation:      question.Explanation,
+		ReferenceUrl:     question.ReferenceURL,
+		PointsMultiplier: question.PointsMultiplier,
+	}
+
+	result, err := r.getQueries(ctx).UpdateQuestion(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +148,7 @@ func (r *questionRepository) UpdateQuestion(ctx context.Context, question *model
 }
 
 func (r *questionRepository) DeleteQuestion(ctx context.Context, id int64) error {
-	err := r.queries.DeleteQuestion(ctx, id)
+	err := r.getQueries(ctx).DeleteQuestion(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -129,13 +156,22 @@ func (r *questionRepository) DeleteQuestion(ctx context.Context, id int64) error
 	return nil
 }
 
+func (r *questionRepository) DeleteQuestionsByQuizExcept(ctx context.Context, quizID int64, keepIDs []int64) error {
+	params := sqlc.DeleteQuestionsByQuizExceptParams{
+		QuizID:  quizID,
+		KeepIds: keepIDs,
+	}
+
+	return r.getQueries(ctx).DeleteQuestionsByQuizExcept(ctx, params)
+}
+
 func (r *questionRepository) UpdateQuestionIndex(ctx context.Context, questionID int64, index int32) error {
 	params := sqlc.UpdateQuestionIndexParams{
 		ID:    questionID,
 		Index: index,
 	}
 
-	err := r.queries.UpdateQuestionIndex(ctx, params)
+	err := r.getQueries(ctx).UpdateQuestionIndex(ctx, params)
 	if err != nil {
 		return err
 	}
@@ -155,7 +191,7 @@ func (r *questionRepository) UpdateQuestionIndexesBatch(ctx context.Context, que
 			Index: indexes[i],
 		}
 
-		err := r.queries.UpdateQuestionIndex(ctx, params)
+		err := r.getQueries(ctx).UpdateQuestionIndex(ctx, params)
 		if err != nil {
 			return fmt.Errorf("failed to update question %d: %w", questionID, err)
 		}
@@ -163,3 +199,11 @@ func (r *questionRepository) UpdateQuestionIndexesBatch(ctx context.Context, que
 
 	return nil
 }
+
+// contentFormat falls back to plain text for callers that do not set a format
+func contentFormat(format models.ContentFormat) models.ContentFormat {
+	if format == "" {
+		return models.ContentFormatPlain
+	}
+	return format
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+)
+
+type (
+	QuizCollaboratorRepository interface {
+		// UpsertCollaborator adds a user to a quiz or changes the role they already have
+		UpsertCollaborator(ctx context.Context, collaborator *models.QuizCollaborator) (*models.QuizCollaborator, error)
+		GetCollaboratorRole(ctx context.Context, quizID int64, userID int64) (models.QuizRole, error)
+		GetCollaboratorRolesByUser(ctx context.Context, userID int64, quizIDs []int64) (map[int64]models.QuizRole, error)
+		ListCollaborators(ctx context.Context, quizID int64) ([]*models.QuizCollaborator, error)
+		// DeleteCollaborator reports whether the user was a collaborator
+		DeleteCollaborator(ctx context.Context, quizID int64, userID int64) (bool, error)
+	}
+
+	quizCollaboratorRepository struct {
+		queries *sqlc.Queries
+	}
+)
+
+func ProvideQuizCollaboratorRepository(queries *sqlc.Queries) QuizCollaboratorRepository {
+	return &quizCollaboratorRepository{
+		queries: queries,
+	}
+}
+
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *quizCollaboratorRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
+func (r *quizCollaboratorRepository) UpsertCollaborator(ctx context.Context, collaborator *models.QuizCollaborator) (*models.QuizCollaborator, error) {
+	result, err := r.getQueries(ctx).UpsertQuizCollaborator(ctx, sqlc.UpsertQuizCollaboratorParams{
+		QuizID:    collaborator.QuizID,
+		UserID:    collaborator.UserID,
+		Role:      string(collaborator.Role),
+		InvitedBy: collaborator.InvitedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	upserted := *collaborator
+	upserted.Role = models.QuizRole(result.Role)
+	upserted.InvitedBy = result.InvitedBy
+	upserted.CreatedAt = result.CreatedAt.Time
+	upserted.UpdatedAt = result.UpdatedAt.Time
+
+	return &upserted, nil
+}
+
+func (r *quizCollaboratorRepository) GetCollaboratorRole(ctx context.Context, quizID int64, userID int64) (models.QuizRole, error) {
+	role, err := r.getQueries(ctx).GetQuizCollaboratorRole(ctx, sqlc.GetQuizCollaboratorRoleParams{
+		QuizID: quizID,
+		UserID: userID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return models.QuizRole(role), nil
+}
+
+func (r *quizCollaboratorRepository) GetCollaboratorRolesByUser(ctx context.Context, userID int64, quizIDs []int64) (map[int64]models.QuizRole, error) {
+	roles := make(map[int64]models.QuizRole)
+	if len(quizIDs) == 0 {
+		return roles, nil
+	}
+
+	results, err := r.getQueries(ctx).GetQuizCollaboratorRolesByUser(ctx, sqlc.GetQuizCollaboratorRolesByUserParams{
+		UserID:  userID,
+		QuizIds: quizIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		roles[result.QuizID] = models.QuizRole(result.Role)
+	}
+
+	return roles, nil
+}
+
+func (r *quizCollaboratorRepository) ListCollaborators(ctx context.Context, quizID int64) ([]*models.QuizCollaborator, error) {
+	results, err := r.getQueries(ctx).ListQuizCollaborators(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborators := make([]*models.QuizCollaborator, len(results))
+	for i, result := range results {
+		collaborators[i] = transformers.ConvertToQuizCollaboratorModel(result)
+	}
+
+	return collaborators, nil
+}
+
+func (r *quizCollaboratorRepository) DeleteCollaborator(ctx context.Context, quizID int64, userID int64) (bool, error) {
+	rows, err := r.getQueries(ctx).DeleteQuizCollaborator(ctx, sqlc.DeleteQuizCollaboratorParams{
+		QuizID: quizID,
+		UserID: userID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
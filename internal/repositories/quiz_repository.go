@@ -2,9 +2,12 @@ package repositories
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	helpers "github.com/nghiavan0610/btaskee-quiz-service/helpers/quiz"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
@@ -13,16 +16,28 @@ import (
 
 type (
 	QuizRepository interface {
+		// CreateQuiz inserts the quiz under quiz.Slug, or the next free slug for its title when a
+		// concurrent create took that one first
 		CreateQuiz(ctx context.Context, quiz *models.Quiz) (*models.Quiz, error)
 		UpdateQuiz(ctx context.Context, quiz *models.Quiz) (*models.Quiz, error)
+		// MakePrivate unpublishes the quiz without touching its other fields
+		MakePrivate(ctx context.Context, quizID int64) (*models.Quiz, error)
 		GetQuizDetail(ctx context.Context, id int64, includeQuestions bool) (*models.Quiz, error)
 		DeleteQuiz(ctx context.Context, id int64) error
-		GetQuizListByOwner(ctx context.Context, ownerID int64, query *string, visibility *models.QuizVisibility, limit, offset int32) ([]*models.Quiz, error)
-		CountQuizListByOwner(ctx context.Context, ownerID int64, query *string, visibility *models.QuizVisibility) (int64, error)
-		GetPublicQuizList(ctx context.Context, query *string, cursor *string, limit int32, sortBy *string) ([]*models.Quiz, error)
+		// GetQuizListByOwner lists the quizzes a user owns or collaborates on
+		GetQuizListByOwner(ctx context.Context, ownerID int64, query *string, visibility *models.QuizVisibility, tag *string, limit, offset int32) ([]*models.Quiz, error)
+		CountQuizListByOwner(ctx context.Context, ownerID int64, query *string, visibility *models.QuizVisibility, tag *string) (int64, error)
+		GetPublicQuizList(ctx context.Context, filter *models.PublicQuizFilter, cursor *string, limit int32, sortBy *string) ([]*models.Quiz, error)
 		IncrementViewCount(ctx context.Context, quizID int64) error
 		IncrementPlayCount(ctx context.Context, quizID int64) error
 		UpdateTotalQuestions(ctx context.Context, quizID int64, totalQuestions int32) error
+		// LockQuiz holds the quiz row until the transaction ends, so concurrent question edits and
+		// version numbering are applied one at a time. It must run inside a transaction.
+		LockQuiz(ctx context.Context, quizID int64) error
+		UpdateOwner(ctx context.Context, quizID int64, ownerID int64) error
+		GenerateSlug(ctx context.Context, title string) (string, error)
+		ResolveSlug(ctx context.Context, slug string) (int64, error)
+		UpdateSlug(ctx context.Context, quizID int64, oldSlug *string, newSlug string) error
 	}
 
 	quizRepository struct {
@@ -30,62 +45,75 @@ type (
 	}
 )
 
+// maxSlugRetries bounds the candidates tried for a quiz slug
+const maxSlugRetries = 20
+
 func ProvideQuizRepository(queries *sqlc.Queries) QuizRepository {
 	return &quizRepository{
 		queries: queries,
 	}
 }
 
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *quizRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
 func (r *quizRepository) CreateQuiz(ctx context.Context, quiz *models.Quiz) (*models.Quiz, error) {
 	params := sqlc.CreateQuizParams{
-		Title:           quiz.Title,
-		Description:     quiz.Description,
-		Visibility:      sqlc.QuizVisibilityPrivate,
-		OwnerID:         quiz.OwnerID,
-		MaxParticipants: quiz.MaxParticipants,
+		Title:            quiz.Title,
+		Description:      quiz.Description,
+		Visibility:       sqlc.QuizVisibilityPrivate,
+		OwnerID:          quiz.OwnerID,
+		MaxParticipants:  quiz.MaxParticipants,
+		ForkedFromQuizID: quiz.ForkedFromQuizID,
+		Slug:             quiz.Slug,
 	}
 
-	result, err := r.queries.CreateQuiz(ctx, params)
-	if err != nil {
-		return nil, err
-	}
+	// The insert skips a taken slug instead of failing, which would abort the caller's transaction
+	for attempt := 0; ; attempt++ {
+		result, err := r.getQueries(ctx).CreateQuiz(ctx, params)
+		if err == nil {
+			return transformers.ConvertToQuizModel(result), nil
+		}
+		if err != pgx.ErrNoRows || params.Slug == nil || attempt == maxSlugRetries {
+			return nil, err
+		}
 
-	return transformers.ConvertToQuizModel(result), nil
+		slug, err := r.GenerateSlug(ctx, quiz.Title)
+		if err != nil {
+			return nil, err
+		}
+		params.Slug = &slug
+	}
 }
 
 func (r *quizRepository) GetQuizDetail(ctx context.Context, id int64, includeQuestions bool) (*models.Quiz, error) {
-	result, err := r.queries.GetQuizWithOwner(ctx, id)
+	result, err := r.getQueries(ctx).GetQuizWithOwner(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	quiz := transformers.ConvertToQuizModel(result)
 
+	if err := r.attachTags(ctx, quiz); err != nil {
+		return nil, err
+	}
+
 	// Load questions if requested
 	if includeQuestions {
-		questions, err := r.queries.GetQuestionListByQuiz(ctx, id)
+		questions, err := r.getQueries(ctx).GetQuestionListByQuiz(ctx, id)
 		if err != nil {
 			return nil, err
 		}
 
 		quiz.Questions = make([]models.Question, len(questions))
 		for i, q := range questions {
-			answers, err := transformers.ParseAnswersFromJSON(q.Answers)
+			question, err := transformers.ConvertToQuestionModel(q)
 			if err != nil {
 				return nil, err
 			}
-
-			quiz.Questions[i] = models.Question{
-				ID:        q.ID,
-				QuizID:    q.QuizID,
-				Question:  q.Question,
-				Type:      models.QuestionType(q.Type),
-				Answers:   answers,
-				TimeLimit: models.TimeLimitType(q.TimeLimit),
-				Index:     q.Index,
-				CreatedAt: q.CreatedAt.Time,
-				UpdatedAt: q.UpdatedAt.Time,
-			}
+			quiz.Questions[i] = *question
 		}
 	}
 
@@ -101,7 +129,7 @@ func (r *quizRepository) UpdateQuiz(ctx context.Context, quiz *models.Quiz) (*mo
 		}
 	}
 
-	result, err := r.queries.UpdateQuiz(ctx, sqlc.UpdateQuizParams{
+	result, err := r.getQueries(ctx).UpdateQuiz(ctx, sqlc.UpdateQuizParams{
 		ID:              quiz.ID,
 		Title:           quiz.Title,
 		Description:     quiz.Description,
@@ -116,15 +144,24 @@ func (r *quizRepository) UpdateQuiz(ctx context.Context, quiz *models.Quiz) (*mo
 	return transformers.ConvertToQuizModel(result), nil
 }
 
+func (r *quizRepository) MakePrivate(ctx context.Context, quizID int64) (*models.Quiz, error) {
+	result, err := r.getQueries(ctx).MakeQuizPrivate(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToQuizModel(result), nil
+}
+
 func (r *quizRepository) DeleteQuiz(ctx context.Context, id int64) error {
-	err := r.queries.DeleteQuiz(ctx, id)
+	err := r.getQueries(ctx).DeleteQuiz(ctx, id)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *quizRepository) GetQuizListByOwner(ctx context.Context, ownerID int64, query *string, visibility *models.QuizVisibility, limit, offset int32) ([]*models.Quiz, error) {
+func (r *quizRepository) GetQuizListByOwner(ctx context.Context, ownerID int64, query *string, visibility *models.QuizVisibility, tag *string, limit, offset int32) ([]*models.Quiz, error) {
 	var visibilityParam sqlc.NullQuizVisibility
 	if lo.FromPtr(visibility) != "" {
 		visibilityParam = sqlc.NullQuizVisibility{
@@ -135,10 +172,11 @@ func (r *quizRepository) GetQuizListByOwner(ctx context.Context, ownerID int64,
 		visibilityParam = sqlc.NullQuizVisibility{Valid: false}
 	}
 
-	results, err := r.queries.GetQuizListByOwner(ctx, sqlc.GetQuizListByOwnerParams{
+	results, err := r.getQueries(ctx).GetQuizListByOwner(ctx, sqlc.GetQuizListByOwnerParams{
 		OwnerID:    ownerID,
 		Query:      query,
 		Visibility: visibilityParam,
+		Tag:        tag,
 		Limit:      limit,
 		Offset:     offset,
 	})
@@ -151,10 +189,14 @@ func (r *quizRepository) GetQuizListByOwner(ctx context.Context, ownerID int64,
 		quizzes[i] = transformers.ConvertToQuizModel(result)
 	}
 
+	if err := r.attachTags(ctx, quizzes...); err != nil {
+		return nil, err
+	}
+
 	return quizzes, nil
 }
 
-func (r *quizRepository) CountQuizListByOwner(ctx context.Context, ownerID int64, query *string, visibility *models.QuizVisibility) (int64, error) {
+func (r *quizRepository) CountQuizListByOwner(ctx context.Context, ownerID int64, query *string, visibility *models.QuizVisibility, tag *string) (int64, error) {
 	var visibilityParam sqlc.NullQuizVisibility
 	if lo.FromPtr(visibility) != "" {
 		visibilityParam = sqlc.NullQuizVisibility{
@@ -165,10 +207,11 @@ func (r *quizRepository) CountQuizListByOwner(ctx context.Context, ownerID int64
 		visibilityParam = sqlc.NullQuizVisibility{Valid: false}
 	}
 
-	count, err := r.queries.CountQuizListByOwner(ctx, sqlc.CountQuizListByOwnerParams{
+	count, err := r.getQueries(ctx).CountQuizListByOwner(ctx, sqlc.CountQuizListByOwnerParams{
 		OwnerID:    ownerID,
 		Query:      query,
 		Visibility: visibilityParam,
+		Tag:        tag,
 	})
 	if err != nil {
 		return 0, err
@@ -177,7 +220,7 @@ func (r *quizRepository) CountQuizListByOwner(ctx context.Context, ownerID int64
 	return count, nil
 }
 
-func (r *quizRepository) GetPublicQuizList(ctx context.Context, query *string, cursor *string, limit int32, sortBy *string) ([]*models.Quiz, error) {
+func (r *quizRepository) GetPublicQuizList(ctx context.Context, filter *models.PublicQuizFilter, cursor *string, limit int32, sortBy *string) ([]*models.Quiz, error) {
 	validSortBy := helpers.ValidateQuizSortBy(sortBy)
 
 	// Decode cursor if provided
@@ -187,9 +230,19 @@ func (r *quizRepository) GetPublicQuizList(ctx context.Context, query *string, c
 	}
 
 	params := sqlc.GetPublicQuizzesParams{
-		Query:  query,
-		Limit:  limit,
-		SortBy: &validSortBy,
+		Query:        filter.Query,
+		OwnerID:      filter.OwnerID,
+		MinQuestions: filter.MinQuestions,
+		MaxQuestions: filter.MaxQuestions,
+		Tag:          filter.Tag,
+		Limit:        limit,
+		SortBy:       &validSortBy,
+	}
+	if filter.CreatedFrom != nil {
+		params.CreatedFrom = pgtype.Timestamptz{Time: *filter.CreatedFrom, Valid: true}
+	}
+	if filter.CreatedTo != nil {
+		params.CreatedTo = pgtype.Timestamptz{Time: *filter.CreatedTo, Valid: true}
 	}
 
 	if cursorData != nil {
@@ -215,10 +268,14 @@ func (r *quizRepository) GetPublicQuizList(ctx context.Context, query *string, c
 			if cursorData.PlayCount != nil {
 				params.CursorPlayCount = cursorData.PlayCount
 			}
+		case "relevance":
+			if cursorData.Relevance != nil {
+				params.CursorRelevance = cursorData.Relevance
+			}
 		}
 	}
 
-	quizzes, err := r.queries.GetPublicQuizzes(ctx, params)
+	quizzes, err := r.getQueries(ctx).GetPublicQuizzes(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -228,15 +285,19 @@ func (r *quizRepository) GetPublicQuizList(ctx context.Context, query *string, c
 		result[i] = transformers.ConvertToQuizModel(quiz)
 	}
 
+	if err := r.attachTags(ctx, result...); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
 func (r *quizRepository) IncrementViewCount(ctx context.Context, quizID int64) error {
-	return r.queries.IncrementQuizViewCount(ctx, quizID)
+	return r.getQueries(ctx).IncrementQuizViewCount(ctx, quizID)
 }
 
 func (r *quizRepository) IncrementPlayCount(ctx context.Context, quizID int64) error {
-	return r.queries.IncrementQuizPlayCount(ctx, quizID)
+	return r.getQueries(ctx).IncrementQuizPlayCount(ctx, quizID)
 }
 
 func (r *quizRepository) UpdateTotalQuestions(ctx context.Context, quizID int64, totalQuestions int32) error {
@@ -244,5 +305,89 @@ func (r *quizRepository) UpdateTotalQuestions(ctx context.Context, quizID int64,
 		ID:             quizID,
 		TotalQuestions: &totalQuestions,
 	}
-	return r.queries.UpdateQuizTotalQuestions(ctx, params)
+	return r.getQueries(ctx).UpdateQuizTotalQuestions(ctx, params)
+}
+
+func (r *quizRepository) LockQuiz(ctx context.Context, quizID int64) error {
+	return r.getQueries(ctx).LockQuiz(ctx, quizID)
+}
+
+func (r *quizRepository) UpdateOwner(ctx context.Context, quizID int64, ownerID int64) error {
+	return r.getQueries(ctx).UpdateQuizOwner(ctx, sqlc.UpdateQuizOwnerParams{
+		ID:      quizID,
+		OwnerID: ownerID,
+	})
+}
+
+func (r *quizRepository) GenerateSlug(ctx context.Context, title string) (string, error) {
+	base := helpers.QuizSlugBase(title)
+
+	for attempt := range maxSlugRetries {
+		slug := helpers.QuizSlugCandidate(base, attempt)
+
+		exists, err := r.getQueries(ctx).CheckQuizSlugExists(ctx, &slug)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return slug, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate unique slug after %d attempts", maxSlugRetries)
+}
+
+// ResolveSlug finds the quiz a slug points to, following slugs the quiz used to have
+func (r *quizRepository) ResolveSlug(ctx context.Context, slug string) (int64, error) {
+	quizID, err := r.getQueries(ctx).GetQuizIDBySlug(ctx, &slug)
+	if err != pgx.ErrNoRows {
+		return quizID, err
+	}
+
+	return r.getQueries(ctx).GetQuizIDBySlugRedirect(ctx, slug)
+}
+
+// UpdateSlug moves a quiz to a new slug and keeps the old one as a redirect
+func (r *quizRepository) UpdateSlug(ctx context.Context, quizID int64, oldSlug *string, newSlug string) error {
+	queries := r.getQueries(ctx)
+
+	// The new slug may be one the quiz used before
+	if err := queries.DeleteQuizSlugRedirect(ctx, newSlug); err != nil {
+		return err
+	}
+
+	if err := queries.UpdateQuizSlug(ctx, sqlc.UpdateQuizSlugParams{ID: quizID, Slug: &newSlug}); err != nil {
+		return err
+	}
+
+	if oldSlug == nil {
+		return nil
+	}
+
+	return queries.CreateQuizSlugRedirect(ctx, sqlc.CreateQuizSlugRedirectParams{
+		Slug:   *oldSlug,
+		QuizID: quizID,
+	})
+}
+
+// attachTags loads the tags of all given quizzes in one query
+func (r *quizRepository) attachTags(ctx context.Context, quizzes ...*models.Quiz) error {
+	if len(quizzes) == 0 {
+		return nil
+	}
+
+	quizIDs := lo.Map(quizzes, func(quiz *models.Quiz, _ int) int64 { return quiz.ID })
+
+	results, err := r.getQueries(ctx).GetTagsByQuizIDs(ctx, quizIDs)
+	if err != nil {
+		return err
+	}
+
+	tags := transformers.GroupTagsByQuizID(results)
+	for _, quiz := range quizzes {
+		quiz.Tags = tags[quiz.ID]
+	}
+
+	return nil
 }
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+)
+
+type (
+	QuizVersionRepository interface {
+		CreateQuizVersion(ctx context.Context, version *models.QuizVersion) (*models.QuizVersion, error)
+		GetLatestQuizVersion(ctx context.Context, quizID int64) (*models.QuizVersion, error)
+		GetQuizVersion(ctx context.Context, quizID int64, version int32) (*models.QuizVersion, error)
+		GetQuizVersionByID(ctx context.Context, id int64) (*models.QuizVersion, error)
+		ListQuizVersions(ctx context.Context, quizID int64) ([]*models.QuizVersion, error)
+	}
+
+	quizVersionRepository struct {
+		queries *sqlc.Queries
+	}
+)
+
+func ProvideQuizVersionRepository(queries *sqlc.Queries) QuizVersionRepository {
+	return &quizVersionRepository{
+		queries: queries,
+	}
+}
+
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *quizVersionRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
+func (r *quizVersionRepository) CreateQuizVersion(ctx context.Context, version *models.QuizVersion) (*models.QuizVersion, error) {
+	questionsJSON, err := transformers.ConvertQuizVersionQuestionsToJSON(version.Questions)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.getQueries(ctx).CreateQuizVersion(ctx, sqlc.CreateQuizVersionParams{
+		QuizID:          version.QuizID,
+		Title:           version.Title,
+		Description:     version.Description,
+		Visibility:      sqlc.QuizVisibility(version.Visibility),
+		MaxParticipants: version.MaxParticipants,
+		Questions:       questionsJSON,
+		ContentHash:     version.ContentHash,
+		Reason:          string(version.Reason),
+		CreatedBy:       version.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToQuizVersionModel(result)
+}
+
+func (r *quizVersionRepository) GetLatestQuizVersion(ctx context.Context, quizID int64) (*models.QuizVersion, error) {
+	result, err := r.getQueries(ctx).GetLatestQuizVersion(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToQuizVersionModel(result)
+}
+
+func (r *quizVersionRepository) GetQuizVersion(ctx context.Context, quizID int64, version int32) (*models.QuizVersion, error) {
+	result, err := r.getQueries(ctx).GetQuizVersion(ctx, sqlc.GetQuizVersionParams{
+		QuizID:  quizID,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToQuizVersionModel(result)
+}
+
+func (r *quizVersionRepository) GetQuizVersionByID(ctx context.Context, id int64) (*models.QuizVersion, error) {
+	result, err := r.getQueries(ctx).GetQuizVersionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToQuizVersionModel(result)
+}
+
+func (r *quizVersionRepository) ListQuizVersions(ctx context.Context, quizID int64) ([]*models.QuizVersion, error) {
+	results, err := r.getQueries(ctx).ListQuizVersions(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*models.QuizVersion, len(results))
+	for i, result := range results {
+		versions[i] = transformers.ConvertToQuizVersionSummaryModel(result)
+	}
+
+	return versions, nil
+}
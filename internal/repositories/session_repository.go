@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	helpers "github.com/nghiavan0610/btaskee-quiz-service/helpers/session"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
@@ -26,6 +27,12 @@ type SessionRepository interface {
 	GetSessionParticipants(ctx context.Context, sessionID int64) ([]*models.SessionParticipant, error)
 	GetSessionLeaderboard(ctx context.Context, sessionID int64) ([]*models.LeaderboardParticipant, error)
 	UpdateParticipantScore(ctx context.Context, participantID int64, score int32) error
+
+	// Admin
+	ListLiveSessions(ctx context.Context, limit, offset int32) ([]*models.QuizSession, error)
+	CountLiveSessions(ctx context.Context) (int64, error)
+	// CancelSession ends a waiting or active session and reports false when it was not live
+	CancelSession(ctx context.Context, sessionID int64) (bool, error)
 }
 
 type sessionRepository struct {
@@ -38,10 +45,15 @@ func ProvideSessionRepository(queries *sqlc.Queries) SessionRepository {
 	}
 }
 
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *sessionRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
 // ===== HTTP API (Initial Setup Only) =====
 
 func (r *sessionRepository) GetSessionByID(ctx context.Context, sessionID int64) (*models.QuizSession, error) {
-	result, err := r.queries.GetSessionByID(ctx, sessionID)
+	result, err := r.getQueries(ctx).GetSessionByID(ctx, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +71,7 @@ func (r *sessionRepository) GetSessionByID(ctx context.Context, sessionID int64)
 		EndedAt:              result.EndedAt.Time,
 		CreatedAt:            result.CreatedAt.Time,
 		UpdatedAt:            result.UpdatedAt.Time,
+		QuizVersionID:        result.QuizVersionID,
 		Quiz: &models.Quiz{
 			ID:             result.QuizID,
 			Title:          result.QuizTitle,
@@ -79,9 +92,10 @@ func (r *sessionRepository) CreateSession(ctx context.Context, session *models.Q
 		MaxParticipants:      session.MaxParticipants,
 		CurrentQuestionIndex: session.CurrentQuestionIndex,
 		ParticipantCount:     session.ParticipantCount,
+		QuizVersionID:        session.QuizVersionID,
 	}
 
-	result, err := r.queries.CreateSession(ctx, params)
+	result, err := r.getQueries(ctx).CreateSession(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +104,7 @@ func (r *sessionRepository) CreateSession(ctx context.Context, session *models.Q
 }
 
 func (r *sessionRepository) GetSessionByJoinCode(ctx context.Context, joinCode string) (*models.QuizSession, error) {
-	result, err := r.queries.GetSessionByJoinCode(ctx, joinCode)
+	result, err := r.getQueries(ctx).GetSessionByJoinCode(ctx, joinCode)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +122,7 @@ func (r *sessionRepository) GetSessionByJoinCode(ctx context.Context, joinCode s
 		EndedAt:              result.EndedAt.Time,
 		CreatedAt:            result.CreatedAt.Time,
 		UpdatedAt:            result.UpdatedAt.Time,
+		QuizVersionID:        result.QuizVersionID,
 		Quiz: &models.Quiz{
 			ID:             result.QuizID,
 			Title:          result.QuizTitle,
@@ -128,7 +143,7 @@ func (r *sessionRepository) AddParticipant(ctx context.Context, participant *mod
 		IsHost:    participant.IsHost,
 	}
 
-	result, err := r.queries.AddParticipant(ctx, params)
+	result, err := r.getQueries(ctx).AddParticipant(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -145,7 +160,7 @@ func (r *sessionRepository) GenerateJoinCode(ctx context.Context) (string, error
 			return "", err
 		}
 
-		exists, err := r.queries.CheckJoinCodeExists(ctx, code)
+		exists, err := r.getQueries(ctx).CheckJoinCodeExists(ctx, code)
 		if err != nil {
 			return "", err
 		}
@@ -167,7 +182,7 @@ func (r *sessionRepository) UpdateSession(ctx context.Context, session *models.Q
 		ParticipantCount:     session.ParticipantCount,
 	}
 
-	result, err := r.queries.UpdateSession(ctx, params)
+	result, err := r.getQueries(ctx).UpdateSession(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -178,11 +193,11 @@ func (r *sessionRepository) UpdateSession(ctx context.Context, session *models.Q
 // ===== WebSocket API (Real-time Gameplay) =====
 
 func (r *sessionRepository) StartSession(ctx context.Context, sessionID int64) error {
-	return r.queries.StartSession(ctx, sessionID)
+	return r.getQueries(ctx).StartSession(ctx, sessionID)
 }
 
 func (r *sessionRepository) EndSession(ctx context.Context, sessionID int64) error {
-	return r.queries.EndSession(ctx, sessionID)
+	return r.getQueries(ctx).EndSession(ctx, sessionID)
 }
 
 func (r *sessionRepository) UpdateSessionQuestion(ctx context.Context, sessionID int64, questionIndex int32) error {
@@ -190,11 +205,11 @@ func (r *sessionRepository) UpdateSessionQuestion(ctx context.Context, sessionID
 		ID:                   sessionID,
 		CurrentQuestionIndex: questionIndex,
 	}
-	return r.queries.UpdateSessionQuestion(ctx, params)
+	return r.getQueries(ctx).UpdateSessionQuestion(ctx, params)
 }
 
 func (r *sessionRepository) GetSessionParticipants(ctx context.Context, sessionID int64) ([]*models.SessionParticipant, error) {
-	result, err := r.queries.GetSessionParticipants(ctx, sessionID)
+	result, err := r.getQueries(ctx).GetSessionParticipants(ctx, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +223,7 @@ func (r *sessionRepository) GetSessionParticipants(ctx context.Context, sessionI
 }
 
 func (r *sessionRepository) GetSessionLeaderboard(ctx context.Context, sessionID int64) ([]*models.LeaderboardParticipant, error) {
-	result, err := r.queries.GetSessionLeaderboard(ctx, sessionID)
+	result, err := r.getQueries(ctx).GetSessionLeaderboard(ctx, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -226,5 +241,60 @@ func (r *sessionRepository) UpdateParticipantScore(ctx context.Context, particip
 		ID:    participantID,
 		Score: score,
 	}
-	return r.queries.UpdateParticipantScore(ctx, params)
+	return r.getQueries(ctx).UpdateParticipantScore(ctx, params)
+}
+
+// ===== Admin =====
+
+func (r *sessionRepository) ListLiveSessions(ctx context.Context, limit, offset int32) ([]*models.QuizSession, error) {
+	results, err := r.getQueries(ctx).ListLiveSessions(ctx, sqlc.ListLiveSessionsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*models.QuizSession, len(results))
+	for i, result := range results {
+		sessions[i] = &models.QuizSession{
+			ID:                   result.ID,
+			QuizID:               result.QuizID,
+			HostID:               result.HostID,
+			JoinCode:             result.JoinCode,
+			Status:               models.SessionStatus(result.Status),
+			CurrentQuestionIndex: result.CurrentQuestionIndex,
+			MaxParticipants:      result.MaxParticipants,
+			ParticipantCount:     result.ParticipantCount,
+			StartedAt:            result.StartedAt.Time,
+			EndedAt:              result.EndedAt.Time,
+			CreatedAt:            result.CreatedAt.Time,
+			UpdatedAt:            result.UpdatedAt.Time,
+			QuizVersionID:        result.QuizVersionID,
+			Quiz: &models.Quiz{
+				ID:    result.QuizID,
+				Title: result.QuizTitle,
+			},
+		}
+		if result.HostID != nil && result.HostUsername != nil {
+			sessions[i].Host = &models.User{
+				ID:       *result.HostID,
+				Username: *result.HostUsername,
+			}
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) CountLiveSessions(ctx context.Context) (int64, error) {
+	return r.getQueries(ctx).CountLiveSessions(ctx)
+}
+
+func (r *sessionRepository) CancelSession(ctx context.Context, sessionID int64) (bool, error) {
+	rows, err := r.getQueries(ctx).CancelSession(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
 }
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+)
+
+type (
+	TagRepository interface {
+		SetQuizTags(ctx context.Context, quizID int64, tags []models.Tag) ([]models.Tag, error)
+		GetTagsByQuizIDs(ctx context.Context, quizIDs []int64) (map[int64][]models.Tag, error)
+		GetCategoryBySlug(ctx context.Context, slug string) (*models.Tag, error)
+		ListCategories(ctx context.Context) ([]*models.Tag, error)
+		GetPopularTags(ctx context.Context, limit int32) ([]*models.Tag, error)
+	}
+
+	tagRepository struct {
+		queries *sqlc.Queries
+	}
+)
+
+func ProvideTagRepository(queries *sqlc.Queries) TagRepository {
+	return &tagRepository{
+		queries: queries,
+	}
+}
+
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *tagRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
+// SetQuizTags replaces the tags on a quiz. Tags are matched by slug, so an existing
+// category is assigned as is and unknown slugs become new free-form tags.
+func (r *tagRepository) SetQuizTags(ctx context.Context, quizID int64, tags []models.Tag) ([]models.Tag, error) {
+	queries := r.getQueries(ctx)
+
+	if err := queries.DeleteQuizTags(ctx, quizID); err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		result, err := queries.UpsertTag(ctx, sqlc.UpsertTagParams{
+			Name: tag.Name,
+			Slug: tag.Slug,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := queries.AddQuizTag(ctx, sqlc.AddQuizTagParams{QuizID: quizID, TagID: result.ID}); err != nil {
+			return nil, err
+		}
+	}
+
+	quizTags, err := r.GetTagsByQuizIDs(ctx, []int64{quizID})
+	if err != nil {
+		return nil, err
+	}
+
+	return quizTags[quizID], nil
+}
+
+func (r *tagRepository) GetTagsByQuizIDs(ctx context.Context, quizIDs []int64) (map[int64][]models.Tag, error) {
+	if len(quizIDs) == 0 {
+		return map[int64][]models.Tag{}, nil
+	}
+
+	results, err := r.getQueries(ctx).GetTagsByQuizIDs(ctx, quizIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.GroupTagsByQuizID(results), nil
+}
+
+func (r *tagRepository) GetCategoryBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	result, err := r.getQueries(ctx).GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformers.ConvertToTagModel(result), nil
+}
+
+func (r *tagRepository) ListCategories(ctx context.Context) ([]*models.Tag, error) {
+	results, err := r.getQueries(ctx).ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]*models.Tag, len(results))
+	for i, result := range results {
+		categories[i] = transformers.ConvertToCategoryModel(result)
+	}
+
+	return categories, nil
+}
+
+func (r *tagRepository) GetPopularTags(ctx context.Context, limit int32) ([]*models.Tag, error) {
+	results, err := r.getQueries(ctx).GetPopularTags(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]*models.Tag, len(results))
+	for i, result := range results {
+		tags[i] = transformers.ConvertToPopularTagModel(result)
+	}
+
+	return tags, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 )
@@ -15,6 +16,10 @@ type (
 		UpdateUser(ctx context.Context, user *models.User) (*models.User, error)
 		DeleteUser(ctx context.Context, id int64) error
 		UpdateUserLastLogin(ctx context.Context, id int64) (*models.User, error)
+		FindUserByUsernameOrEmail(ctx context.Context, identifier string) (*models.User, error)
+		SetUserActive(ctx context.Context, id int64, isActive bool) (*models.User, error)
+		ListUsers(ctx context.Context, filter *models.UserFilter, limit, offset int32) ([]*models.User, error)
+		CountUsers(ctx context.Context, filter *models.UserFilter) (int64, error)
 	}
 
 	userRepository struct {
@@ -28,8 +33,13 @@ func ProvideUserRepository(queries *sqlc.Queries) UserRepository {
 	}
 }
 
+// getQueries returns queries bound to the transaction carried by ctx, if any
+func (r *userRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return database.QueriesFromContext(ctx, r.queries)
+}
+
 func (r *userRepository) GetUserDetail(ctx context.Context, id int64) (*models.User, error) {
-	result, err := r.queries.GetUserDetail(ctx, id)
+	result, err := r.getQueries(ctx).GetUserDetail(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -39,14 +49,22 @@ func (r *userRepository) GetUserDetail(ctx context.Context, id int64) (*models.U
 		lastLoginAt = &result.LastLoginAt.Time
 	}
 
+	var emailVerifiedAt *time.Time
+	if result.EmailVerifiedAt.Valid {
+		emailVerifiedAt = &result.EmailVerifiedAt.Time
+	}
+
 	return &models.User{
-		ID:          result.ID,
-		Username:    result.Username,
-		Email:       result.Email,
-		IsActive:    result.IsActive,
-		CreatedAt:   result.CreatedAt.Time,
-		UpdatedAt:   result.UpdatedAt.Time,
-		LastLoginAt: lastLoginAt,
+		ID:              result.ID,
+		Username:        result.Username,
+		Email:           result.Email,
+		IsActive:        result.IsActive,
+		Role:            result.Role,
+		EmailVerified:   result.EmailVerified,
+		EmailVerifiedAt: emailVerifiedAt,
+		CreatedAt:       result.CreatedAt.Time,
+		UpdatedAt:       result.UpdatedAt.Time,
+		LastLoginAt:     lastLoginAt,
 	}, nil
 }
 
@@ -56,7 +74,7 @@ func (r *userRepository) FindValidateName(ctx context.Context, username string,
 		ExcludeID: excludeId,
 	}
 
-	result, err := r.queries.FindValidateName(ctx, params)
+	result, err := r.getQueries(ctx).FindValidateName(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +91,7 @@ func (r *userRepository) UpdateUser(ctx context.Context, user *models.User) (*mo
 		Username: user.Username,
 	}
 
-	result, err := r.queries.UpdateUser(ctx, params)
+	result, err := r.getQueries(ctx).UpdateUser(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -83,23 +101,79 @@ func (r *userRepository) UpdateUser(ctx context.Context, user *models.User) (*mo
 		lastLoginAt = &result.LastLoginAt.Time
 	}
 
+	var emailVerifiedAt *time.Time
+	if result.EmailVerifiedAt.Valid {
+		emailVerifiedAt = &result.EmailVerifiedAt.Time
+	}
+
 	return &models.User{
-		ID:          result.ID,
-		Username:    result.Username,
-		Email:       result.Email,
-		IsActive:    result.IsActive,
-		CreatedAt:   result.CreatedAt.Time,
-		UpdatedAt:   result.UpdatedAt.Time,
-		LastLoginAt: lastLoginAt,
+		ID:              result.ID,
+		Username:        result.Username,
+		Email:           result.Email,
+		IsActive:        result.IsActive,
+		Role:            result.Role,
+		EmailVerified:   result.EmailVerified,
+		EmailVerifiedAt: emailVerifiedAt,
+		CreatedAt:       result.CreatedAt.Time,
+		UpdatedAt:       result.UpdatedAt.Time,
+		LastLoginAt:     lastLoginAt,
 	}, nil
 }
 
 func (r *userRepository) DeleteUser(ctx context.Context, id int64) error {
-	return r.queries.DeleteUser(ctx, id)
+	return r.getQueries(ctx).DeleteUser(ctx, id)
 }
 
 func (r *userRepository) UpdateUserLastLogin(ctx context.Context, id int64) (*models.User, error) {
-	result, err := r.queries.UpdateUserLastLogin(ctx, id)
+	result, err := r.getQueries(ctx).UpdateUserLastLogin(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastLoginAt *time.Time
+	if result.LastLoginAt.Valid {
+		lastLoginAt = &result.LastLoginAt.Time
+	}
+
+	var emailVerifiedAt *time.Time
+	if result.EmailVerifiedAt.Valid {
+		emailVerifiedAt = &result.EmailVerifiedAt.Time
+	}
+
+	return &models.User{
+		ID:              result.ID,
+		Username:        result.Username,
+		Email:           result.Email,
+		IsActive:        result.IsActive,
+		Role:            result.Role,
+		EmailVerified:   result.EmailVerified,
+		EmailVerifiedAt: emailVerifiedAt,
+		CreatedAt:       result.CreatedAt.Time,
+		UpdatedAt:       result.UpdatedAt.Time,
+		LastLoginAt:     lastLoginAt,
+	}, nil
+}
+
+func (r *userRepository) FindUserByUsernameOrEmail(ctx context.Context, identifier string) (*models.User, error) {
+	result, err := r.getQueries(ctx).FindUserByUsernameOrEmail(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.User{
+		ID:        result.ID,
+		Username:  result.Username,
+		Email:     result.Email,
+		AvatarURL: result.AvatarUrl,
+		IsActive:  result.IsActive,
+	}, nil
+}
+
+func (r *userRepository) SetUserActive(ctx context.Context, id int64, isActive bool) (*models.User, error) {
+	result, err := r.getQueries(ctx).SetUserActive(ctx, sqlc.SetUserActiveParams{
+		ID:       id,
+		IsActive: isActive,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -109,13 +183,78 @@ func (r *userRepository) UpdateUserLastLogin(ctx context.Context, id int64) (*mo
 		lastLoginAt = &result.LastLoginAt.Time
 	}
 
+	var emailVerifiedAt *time.Time
+	if result.EmailVerifiedAt.Valid {
+		emailVerifiedAt = &result.EmailVerifiedAt.Time
+	}
+
 	return &models.User{
-		ID:          result.ID,
-		Username:    result.Username,
-		Email:       result.Email,
-		IsActive:    result.IsActive,
-		CreatedAt:   result.CreatedAt.Time,
-		UpdatedAt:   result.UpdatedAt.Time,
-		LastLoginAt: lastLoginAt,
+		ID:              result.ID,
+		Username:        result.Username,
+		Email:           result.Email,
+		IsActive:        result.IsActive,
+		Role:            result.Role,
+		EmailVerified:   result.EmailVerified,
+		EmailVerifiedAt: emailVerifiedAt,
+		CreatedAt:       result.CreatedAt.Time,
+		UpdatedAt:       result.UpdatedAt.Time,
+		LastLoginAt:     lastLoginAt,
 	}, nil
 }
+
+func (r *userRepository) ListUsers(ctx context.Context, filter *models.UserFilter, limit, offset int32) ([]*models.User, error) {
+	results, err := r.getQueries(ctx).ListUsers(ctx, sqlc.ListUsersParams{
+		Query:    filter.Query,
+		IsActive: filter.IsActive,
+		Role:     userRoleParam(filter.Role),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*models.User, len(results))
+	for i, result := range results {
+		var lastLoginAt *time.Time
+		if result.LastLoginAt.Valid {
+			lastLoginAt = &result.LastLoginAt.Time
+		}
+
+		var emailVerifiedAt *time.Time
+		if result.EmailVerifiedAt.Valid {
+			emailVerifiedAt = &result.EmailVerifiedAt.Time
+		}
+
+		users[i] = &models.User{
+			ID:              result.ID,
+			Username:        result.Username,
+			Email:           result.Email,
+			AvatarURL:       result.AvatarUrl,
+			IsActive:        result.IsActive,
+			Role:            result.Role,
+			EmailVerified:   result.EmailVerified,
+			EmailVerifiedAt: emailVerifiedAt,
+			CreatedAt:       result.CreatedAt.Time,
+			UpdatedAt:       result.UpdatedAt.Time,
+			LastLoginAt:     lastLoginAt,
+		}
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) CountUsers(ctx context.Context, filter *models.UserFilter) (int64, error) {
+	return r.getQueries(ctx).CountUsers(ctx, sqlc.CountUsersParams{
+		Query:    filter.Query,
+		IsActive: filter.IsActive,
+		Role:     userRoleParam(filter.Role),
+	})
+}
+
+func userRoleParam(role *models.UserRole) sqlc.NullUserRole {
+	if role == nil || *role == "" {
+		return sqlc.NullUserRole{Valid: false}
+	}
+	return sqlc.NullUserRole{UserRole: *role, Valid: true}
+}
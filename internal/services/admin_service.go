@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/events"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/response"
+	ws "github.com/nghiavan0610/btaskee-quiz-service/pkg/websocket"
+	"github.com/nghiavan0610/btaskee-quiz-service/utils"
+)
+
+type (
+	// AdminService holds moderation actions that bypass ownership checks. Access is enforced by
+	// RoleGuard on the routes, and every action writes its audit log row in the same transaction, so
+	// an action that cannot be audited does not happen.
+	AdminService interface {
+		ListUsers(ctx context.Context, req *dtos.AdminListUsersRequest) (*dtos.AdminListUsersResponse, *exception.AppError)
+		// DeactivateUser disables the account and signs it out everywhere
+		DeactivateUser(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminDeactivateUserRequest) (*models.User, *exception.AppError)
+		ReactivateUser(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminReactivateUserRequest) (*models.User, *exception.AppError)
+		// TakeDownQuiz makes any quiz private, removing it from the catalogue and shared links
+		TakeDownQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminTakeDownQuizRequest) (*models.Quiz, *exception.AppError)
+		DeleteQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminDeleteQuizRequest) *exception.AppError
+		// ListLiveSessions returns waiting and active sessions with the clients connected to this instance
+		ListLiveSessions(ctx context.Context, req *dtos.AdminListSessionsRequest) (*dtos.AdminListSessionsResponse, *exception.AppError)
+		// EndSession cancels a live session and tells its room the game is over
+		EndSession(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminEndSessionRequest) *exception.AppError
+	}
+
+	adminService struct {
+		pool             *pgxpool.Pool
+		logger           *logger.Logger
+		userRepo         repositories.UserRepository
+		quizRepo         repositories.QuizRepository
+		sessionRepo      repositories.SessionRepository
+		tokenService     TokenService
+		auditService     AuditService
+		hub              ws.Hub
+		gameEventHandler events.GameEventHandler
+	}
+)
+
+var (
+	adminServiceOnce     sync.Once
+	adminServiceInstance AdminService
+)
+
+func ProvideAdminService(
+	pool *pgxpool.Pool,
+	logger *logger.Logger,
+	userRepo repositories.UserRepository,
+	quizRepo repositories.QuizRepository,
+	sessionRepo repositories.SessionRepository,
+	tokenService TokenService,
+	auditService AuditService,
+	hub ws.Hub,
+	gameEventHandler events.GameEventHandler,
+) AdminService {
+	adminServiceOnce.Do(func() {
+		adminServiceInstance = &adminService{
+			pool:             pool,
+			logger:           logger,
+			userRepo:         userRepo,
+			quizRepo:         quizRepo,
+			sessionRepo:      sessionRepo,
+			tokenService:     tokenService,
+			auditService:     auditService,
+			hub:              hub,
+			gameEventHandler: gameEventHandler,
+		}
+	})
+	return adminServiceInstance
+}
+
+func (s *adminService) ListUsers(ctx context.Context, req *dtos.AdminListUsersRequest) (*dtos.AdminListUsersResponse, *exception.AppError) {
+	s.logger.Info("[ADMIN LIST USERS]", req)
+
+	offset, limit := utils.CalculateOffset(req.Page, req.Limit)
+	filter := &models.UserFilter{
+		Query:    req.Query,
+		IsActive: req.IsActive,
+		Role:     req.Role,
+	}
+
+	queries := []func(context.Context) (any, error){
+		func(ctx context.Context) (any, error) {
+			return s.userRepo.ListUsers(ctx, filter, limit, offset)
+		},
+		func(ctx context.Context) (any, error) {
+			return s.userRepo.CountUsers(ctx, filter)
+		},
+	}
+
+	results, err := utils.RunQueriesParallel(ctx, queries)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return &dtos.AdminListUsersResponse{
+		Users: results[0].([]*models.User),
+		Pagination: response.OffsetPagination{
+			Page:       req.Page,
+			Limit:      req.Limit,
+			TotalItems: results[1].(int64),
+		},
+	}, nil
+}
+
+func (s *adminService) DeactivateUser(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminDeactivateUserRequest) (*models.User, *exception.AppError) {
+	s.logger.Info("[ADMIN DEACTIVATE USER]", authUser, req)
+
+	if req.UserID == authUser.UserID {
+		return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrCannotModerateSelf)
+	}
+
+	user, err := database.NewTransaction[models.User](s.pool).Execute(ctx, func(ctx context.Context) (*models.User, error) {
+		user, err := s.userRepo.SetUserActive(ctx, req.UserID, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.record(ctx, authUser, models.AuditActionUserDeactivated, models.AuditTargetUser, user.ID, auditReason(req.Reason)); err != nil {
+			return nil, err
+		}
+
+		// Tokens carry the session's copy of is_active, so only revoking the sessions locks the user out now.
+		// It goes last so a failure rolls the deactivation back.
+		if appErr := s.tokenService.RevokeAllSessions(ctx, user.ID); appErr != nil {
+			return nil, appErr
+		}
+
+		return user, nil
+	})
+	if err != nil {
+		return nil, userActionError(err)
+	}
+
+	return user, nil
+}
+
+func (s *adminService) ReactivateUser(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminReactivateUserRequest) (*models.User, *exception.AppError) {
+	s.logger.Info("[ADMIN REACTIVATE USER]", authUser, req)
+
+	if req.UserID == authUser.UserID {
+		return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrCannotModerateSelf)
+	}
+
+	user, err := database.NewTransaction[models.User](s.pool).Execute(ctx, func(ctx context.Context) (*models.User, error) {
+		user, err := s.userRepo.SetUserActive(ctx, req.UserID, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.record(ctx, authUser, models.AuditActionUserReactivated, models.AuditTargetUser, user.ID, auditReason(req.Reason)); err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	})
+	if err != nil {
+		return nil, userActionError(err)
+	}
+
+	return user, nil
+}
+
+func (s *adminService) TakeDownQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminTakeDownQuizRequest) (*models.Quiz, *exception.AppError) {
+	s.logger.Info("[ADMIN TAKE DOWN QUIZ]", authUser, req)
+
+	updatedQuiz, err := database.NewTransaction[models.Quiz](s.pool).Execute(ctx, func(ctx context.Context) (*models.Quiz, error) {
+		// Read the visibility being replaced under the lock, and change only visibility, so a
+		// concurrent edit by the owner is neither lost nor misreported
+		if err := s.quizRepo.LockQuiz(ctx, req.QuizID); err != nil {
+			return nil, err
+		}
+		quiz, err := s.quizRepo.GetQuizDetail(ctx, req.QuizID, false)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
+			}
+			return nil, err
+		}
+
+		updatedQuiz, err := s.quizRepo.MakePrivate(ctx, quiz.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		metadata := auditReason(req.Reason)
+		metadata["previous_visibility"] = quiz.Visibility
+		if err := s.record(ctx, authUser, models.AuditActionQuizTakenDown, models.AuditTargetQuiz, quiz.ID, metadata); err != nil {
+			return nil, err
+		}
+
+		return updatedQuiz, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return updatedQuiz, nil
+}
+
+func (s *adminService) DeleteQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminDeleteQuizRequest) *exception.AppError {
+	s.logger.Info("[ADMIN DELETE QUIZ]", authUser, req)
+
+	quiz, err := s.quizRepo.GetQuizDetail(ctx, req.QuizID, false)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
+		}
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	_, err = database.NewTransaction[struct{}](s.pool).Execute(ctx, func(ctx context.Context) (*struct{}, error) {
+		if err := s.quizRepo.DeleteQuiz(ctx, quiz.ID); err != nil {
+			return nil, err
+		}
+
+		// The quiz row is gone, so keep enough in the log to tell what was removed
+		metadata := auditReason(req.Reason)
+		metadata["title"] = quiz.Title
+		metadata["owner_id"] = quiz.OwnerID
+		return nil, s.record(ctx, authUser, models.AuditActionQuizDeleted, models.AuditTargetQuiz, quiz.ID, metadata)
+	})
+	if err != nil {
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return nil
+}
+
+func (s *adminService) ListLiveSessions(ctx context.Context, req *dtos.AdminListSessionsRequest) (*dtos.AdminListSessionsResponse, *exception.AppError) {
+	s.logger.Info("[ADMIN LIST LIVE SESSIONS]", req)
+
+	offset, limit := utils.CalculateOffset(req.Page, req.Limit)
+
+	queries := []func(context.Context) (any, error){
+		func(ctx context.Context) (any, error) {
+			return s.sessionRepo.ListLiveSessions(ctx, limit, offset)
+		},
+		func(ctx context.Context) (any, error) {
+			return s.sessionRepo.CountLiveSessions(ctx)
+		},
+	}
+
+	results, err := utils.RunQueriesParallel(ctx, queries)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	sessions := results[0].([]*models.QuizSession)
+	liveSessions := make([]*dtos.AdminLiveSession, len(sessions))
+	for i, session := range sessions {
+		liveSessions[i] = &dtos.AdminLiveSession{
+			QuizSession:      session,
+			ConnectedClients: s.hub.GetRoomClientCount(session.ID),
+		}
+	}
+
+	return &dtos.AdminListSessionsResponse{
+		Sessions: liveSessions,
+		Pagination: response.OffsetPagination{
+			Page:       req.Page,
+			Limit:      req.Limit,
+			TotalItems: results[1].(int64),
+		},
+	}, nil
+}
+
+func (s *adminService) EndSession(ctx context.Context, authUser *dtos.UserSession, req *dtos.AdminEndSessionRequest) *exception.AppError {
+	s.logger.Info("[ADMIN END SESSION]", authUser, req)
+
+	session, err := s.sessionRepo.GetSessionByID(ctx, req.SessionID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return exception.NotFound(errors.CodeNotFound, errors.ErrSessionNotFound)
+		}
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	_, err = database.NewTransaction[struct{}](s.pool).Execute(ctx, func(ctx context.Context) (*struct{}, error) {
+		cancelled, err := s.sessionRepo.CancelSession(ctx, session.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !cancelled {
+			return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrSessionAlreadyEnded)
+		}
+
+		metadata := auditReason(req.Reason)
+		metadata["previous_status"] = session.Status
+		metadata["quiz_id"] = session.QuizID
+		return nil, s.record(ctx, authUser, models.AuditActionSessionEnded, models.AuditTargetSession, session.ID, metadata)
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return appErr
+		}
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	// Only tell the room once the session is ended for good
+	if err := s.gameEventHandler.NotifySessionCancelled(session.ID, "ended_by_admin"); err != nil {
+		s.logger.Warn("[ADMIN END SESSION] Failed to notify room", err)
+	}
+
+	return nil
+}
+
+// record writes the audit row for an action. Call it inside the action's transaction.
+func (s *adminService) record(ctx context.Context, authUser *dtos.UserSession, action, targetType string, targetID int64, metadata map[string]interface{}) error {
+	return s.auditService.Write(ctx, &models.AuditLog{
+		ActorID:    &authUser.UserID,
+		Action:     action,
+		TargetType: &targetType,
+		TargetID:   &targetID,
+		Metadata:   metadata,
+	})
+}
+
+// userActionError maps an error from a transaction that updated a user
+func userActionError(err error) *exception.AppError {
+	var appErr *exception.AppError
+	if goErrors.As(err, &appErr) {
+		return appErr
+	}
+	if goErrors.Is(err, pgx.ErrNoRows) {
+		return exception.NotFound(errors.CodeNotFound, errors.ErrUserNotFound)
+	}
+	return exception.InternalError(errors.CodeDBError, err.Error())
+}
+
+func auditReason(reason string) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	if reason != "" {
+		metadata["reason"] = reason
+	}
+	return metadata
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+const (
+	// apiKeyPrefix marks our keys so secret scanners and humans can recognise them
+	apiKeyPrefix      = "bqk_"
+	maxAPIKeysPerUser = 20
+)
+
+type (
+	APIKeyService interface {
+		CreateAPIKey(ctx context.Context, authUser *dtos.UserSession, req *dtos.CreateAPIKeyRequest) (*dtos.CreateAPIKeyResponse, *exception.AppError)
+		GetAPIKeys(ctx context.Context, authUser *dtos.UserSession) ([]*models.APIKey, *exception.AppError)
+		DeleteAPIKey(ctx context.Context, authUser *dtos.UserSession, req *dtos.DeleteAPIKeyRequest) *exception.AppError
+		// ValidateAPIKey authenticates a request made with a personal API key that must grant scope
+		ValidateAPIKey(ctx context.Context, key string, scope models.APIKeyScope) (*dtos.UserSession, *exception.AppError)
+	}
+
+	apiKeyService struct {
+		logger     *logger.Logger
+		apiKeyRepo repositories.APIKeyRepository
+	}
+)
+
+var (
+	apiKeyServiceOnce     sync.Once
+	apiKeyServiceInstance APIKeyService
+)
+
+func ProvideAPIKeyService(
+	logger *logger.Logger,
+	apiKeyRepo repositories.APIKeyRepository,
+) APIKeyService {
+	apiKeyServiceOnce.Do(func() {
+		apiKeyServiceInstance = &apiKeyService{
+			logger:     logger,
+			apiKeyRepo: apiKeyRepo,
+		}
+	})
+	return apiKeyServiceInstance
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, authUser *dtos.UserSession, req *dtos.CreateAPIKeyRequest) (*dtos.CreateAPIKeyResponse, *exception.AppError) {
+	s.logger.Info("[CREATE API KEY]", authUser, req)
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrAPIKeyExpiryInPast)
+	}
+
+	count, err := s.apiKeyRepo.CountAPIKeysByUser(ctx, authUser.UserID)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrAPIKeyLimitReached).
+			WithMetadata("max_api_keys", maxAPIKeysPerUser)
+	}
+
+	key, prefix, err := generateAPIKey()
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeTokenGenerateFailed, errors.ErrTokenGenerateFailed)
+	}
+
+	apiKey, err := s.apiKeyRepo.CreateAPIKey(ctx, &models.APIKey{
+		UserID:    authUser.UserID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}, hashAPIKey(key))
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return &dtos.CreateAPIKeyResponse{
+		APIKey: apiKey,
+		Key:    key,
+	}, nil
+}
+
+func (s *apiKeyService) GetAPIKeys(ctx context.Context, authUser *dtos.UserSession) ([]*models.APIKey, *exception.AppError) {
+	s.logger.Info("[GET API KEYS]", authUser)
+
+	apiKeys, err := s.apiKeyRepo.GetAPIKeysByUser(ctx, authUser.UserID)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return apiKeys, nil
+}
+
+func (s *apiKeyService) DeleteAPIKey(ctx context.Context, authUser *dtos.UserSession, req *dtos.DeleteAPIKeyRequest) *exception.AppError {
+	s.logger.Info("[DELETE API KEY]", authUser, req)
+
+	deleted, err := s.apiKeyRepo.DeleteAPIKey(ctx, req.APIKeyID, authUser.UserID)
+	if err != nil {
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	if !deleted {
+		return exception.NotFound(errors.CodeNotFound, errors.ErrAPIKeyNotFound)
+	}
+
+	return nil
+}
+
+func (s *apiKeyService) ValidateAPIKey(ctx context.Context, key string, scope models.APIKeyScope) (*dtos.UserSession, *exception.AppError) {
+	apiKey, err := s.apiKeyRepo.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, exception.Unauthorized(errors.CodeAPIKeyInvalid, errors.ErrAPIKeyInvalid)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	if apiKey.IsExpired(time.Now()) {
+		return nil, exception.Unauthorized(errors.CodeAPIKeyInvalid, errors.ErrAPIKeyExpired)
+	}
+
+	// Keys are checked against the live user row, so deactivation applies to them at once
+	if !apiKey.Owner.IsActive {
+		return nil, exception.Unauthorized(errors.CodeUnauthorized, errors.ErrUserDisabled).
+			WithDetails("User account is not active")
+	}
+
+	if !apiKey.HasScope(scope) {
+		return nil, exception.Forbidden(errors.CodeAPIKeyInsufficientScope, errors.ErrAPIKeyInsufficientScope).
+			WithMetadata("scope", string(scope))
+	}
+
+	if err := s.apiKeyRepo.TouchAPIKeyLastUsed(ctx, apiKey.ID); err != nil {
+		s.logger.Warn("[VALIDATE API KEY] Failed to record last use", err)
+	}
+
+	return &dtos.UserSession{
+		UserID:   apiKey.Owner.ID,
+		Username: apiKey.Owner.Username,
+		Email:    apiKey.Owner.Email,
+		IsActive: apiKey.Owner.IsActive,
+		Role:     apiKey.Owner.Role,
+		APIKeyID: apiKey.ID,
+	}, nil
+}
+
+// generateAPIKey returns a new key and the prefix stored alongside its hash
+func generateAPIKey() (key, prefix string, err error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = apiKeyPrefix + hex.EncodeToString(b)
+
+	secret, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	return prefix + "_" + secret, prefix, nil
+}
+
+// hashAPIKey is a plain SHA-256: keys are 256-bit random, so there is nothing for a slow hash to protect
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
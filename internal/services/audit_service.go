@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+type (
+	AuditService interface {
+		// Record writes an audit event. Failures are logged rather than returned so they never
+		// change the outcome of the action being audited.
+		Record(ctx context.Context, auditLog *models.AuditLog)
+		// Write writes an audit event and returns any failure. Call it inside the transaction of an
+		// action that must not happen without its audit row.
+		Write(ctx context.Context, auditLog *models.AuditLog) error
+	}
+
+	auditService struct {
+		logger       *logger.Logger
+		auditLogRepo repositories.AuditLogRepository
+	}
+)
+
+var (
+	auditServiceOnce     sync.Once
+	auditServiceInstance AuditService
+)
+
+func ProvideAuditService(
+	logger *logger.Logger,
+	auditLogRepo repositories.AuditLogRepository,
+) AuditService {
+	auditServiceOnce.Do(func() {
+		auditServiceInstance = &auditService{
+			logger:       logger,
+			auditLogRepo: auditLogRepo,
+		}
+	})
+	return auditServiceInstance
+}
+
+func (s *auditService) Record(ctx context.Context, auditLog *models.AuditLog) {
+	if err := s.Write(ctx, auditLog); err != nil {
+		s.logger.Error("[AUDIT] Failed to write audit log", err)
+	}
+}
+
+func (s *auditService) Write(ctx context.Context, auditLog *models.AuditLog) error {
+	s.logger.WarnFields("[AUDIT] "+auditLog.Action, map[string]interface{}{
+		"actor_id":    auditLog.ActorID,
+		"target_type": auditLog.TargetType,
+		"target_id":   auditLog.TargetID,
+		"metadata":    auditLog.Metadata,
+	})
+
+	_, err := s.auditLogRepo.CreateAuditLog(ctx, auditLog)
+	return err
+}
@@ -2,50 +2,102 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	goErrors "errors"
-	"strconv"
+	"fmt"
+	"net/url"
 	"sync"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/jwtkeys"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/mailer"
 	"github.com/nghiavan0610/btaskee-quiz-service/utils"
 )
 
 type (
 	AuthService interface {
 		SignUp(ctx context.Context, req *dtos.SignUpRequest) (*dtos.TokenResponse, *exception.AppError)
-		SignIn(ctx context.Context, req *dtos.SignInRequest) (*dtos.TokenResponse, *exception.AppError)
+		// SignIn returns a token pair, or an MFA challenge to finish with VerifyMFAChallenge when the
+		// account has a second factor
+		SignIn(ctx context.Context, req *dtos.SignInRequest) (*dtos.SignInResponse, *exception.AppError)
+		VerifyMFAChallenge(ctx context.Context, req *dtos.MFAChallengeRequest) (*dtos.TokenResponse, *exception.AppError)
 		RefreshToken(ctx context.Context, authUser *dtos.UserSession) (*dtos.TokenResponse, *exception.AppError)
-		SignOut(ctx context.Context, authUser *dtos.UserSession)
+		// SignOut ends the login session the request was made with
+		SignOut(ctx context.Context, authUser *dtos.UserSession) *exception.AppError
+		// SignOutAll ends every login session of the user, including the current one
+		SignOutAll(ctx context.Context, authUser *dtos.UserSession) *exception.AppError
+		ListSessions(ctx context.Context, authUser *dtos.UserSession) ([]*models.AuthSession, *exception.AppError)
+		RevokeSession(ctx context.Context, authUser *dtos.UserSession, req *dtos.RevokeAuthSessionRequest) *exception.AppError
+		// ForgotPassword emails a reset link. It succeeds for unknown emails too, so it cannot be used
+		// to find out who has an account.
+		ForgotPassword(ctx context.Context, req *dtos.ForgotPasswordRequest) *exception.AppError
+		// ResetPassword sets a new password from a reset link, signs the user out everywhere and
+		// deletes their API keys
+		ResetPassword(ctx context.Context, req *dtos.ResetPasswordRequest) *exception.AppError
+		VerifyEmail(ctx context.Context, req *dtos.VerifyEmailRequest) *exception.AppError
+		SendVerificationEmail(ctx context.Context, authUser *dtos.UserSession) *exception.AppError
+		// UnlockAccount lifts a sign-in lockout with the link emailed when it started
+		UnlockAccount(ctx context.Context, req *dtos.UnlockAccountRequest) *exception.AppError
+		GetJWKS() *jwtkeys.JSONWebKeySet
 	}
 
 	authService struct {
-		authRepo     repositories.AuthRepository
-		tokenService TokenService
-		logger       *logger.Logger
+		pool                *pgxpool.Pool
+		authRepo            repositories.AuthRepository
+		apiKeyRepo          repositories.APIKeyRepository
+		tokenService        TokenService
+		loginAttemptService LoginAttemptService
+		mfaService          MFAService
+		mailer              mailer.Mailer
+		config              *config.Config
+		logger              *logger.Logger
 	}
 )
 
 var (
 	authServiceOnce     sync.Once
 	authServiceInstance AuthService
+
+	// dummyPasswordHash is checked against when the email is unknown
+	dummyPasswordHash = sync.OnceValue(func() string {
+		hash, _ := utils.EncryptToHash(uuid.NewString())
+		return hash
+	})
 )
 
 func ProvideAuthService(
+	pool *pgxpool.Pool,
 	authRepo repositories.AuthRepository,
+	apiKeyRepo repositories.APIKeyRepository,
 	tokenService TokenService,
+	loginAttemptService LoginAttemptService,
+	mfaService MFAService,
+	mailer mailer.Mailer,
+	config *config.Config,
 	logger *logger.Logger,
 ) AuthService {
 	authServiceOnce.Do(func() {
 		authServiceInstance = &authService{
-			authRepo:     authRepo,
-			tokenService: tokenService,
-			logger:       logger,
+			pool:                pool,
+			authRepo:            authRepo,
+			apiKeyRepo:          apiKeyRepo,
+			tokenService:        tokenService,
+			loginAttemptService: loginAttemptService,
+			mfaService:          mfaService,
+			mailer:              mailer,
+			config:              config,
+			logger:              logger,
 		}
 	})
 	return authServiceInstance
@@ -84,8 +136,12 @@ func (s *authService) SignUp(ctx context.Context, req *dtos.SignUpRequest) (*dto
 		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
+	if appErr := s.sendVerificationEmail(createdUser); appErr != nil {
+		s.logger.Error("[SIGN UP] Failed to send verification email", appErr.Error())
+	}
+
 	// Generate tokens
-	tokens, appErr := s.tokenService.GenerateTokenPair(ctx, createdUser)
+	tokens, appErr := s.tokenService.GenerateTokenPair(ctx, createdUser, req.Device)
 	if appErr != nil {
 		return nil, appErr
 	}
@@ -97,32 +153,125 @@ func (s *authService) SignUp(ctx context.Context, req *dtos.SignUpRequest) (*dto
 	}, nil
 }
 
-func (s *authService) SignIn(ctx context.Context, req *dtos.SignInRequest) (*dtos.TokenResponse, *exception.AppError) {
-	s.logger.Info("[SIGN IN]", req)
+func (s *authService) SignIn(ctx context.Context, req *dtos.SignInRequest) (*dtos.SignInResponse, *exception.AppError) {
+	s.logger.Info("[SIGN IN]", req.Email, req.Device)
+
+	if appErr := s.loginAttemptService.Reserve(ctx, req.Email, req.Device.IP); appErr != nil {
+		return nil, appErr
+	}
+	failed := false
+	defer func() {
+		if !failed {
+			s.loginAttemptService.Release(ctx, req.Email, req.Device.IP)
+		}
+	}()
 
 	user, err := s.authRepo.GetUserByEmailIncludePassword(ctx, req.Email)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	if user == nil {
+		// Spend as long as a real password check so response times do not reveal unknown emails
+		utils.VerifyEncryptHash(dummyPasswordHash(), req.Password)
+		failed = true
+		return nil, s.failSignIn(ctx, req.Email, req.Device.IP, nil)
+	}
+
+	// Verify password
+	if !utils.VerifyEncryptHash(user.Password, req.Password) {
+		failed = true
+		return nil, s.failSignIn(ctx, req.Email, req.Device.IP, user)
+	}
+
+	// Only say the account is disabled to someone who knows its password
+	if !user.IsActive {
+		return nil, exception.Forbidden(errors.CodeUnauthorized, errors.ErrUserDisabled).
+			WithDetails("Your account has been disabled. Please contact support")
+	}
+
+	mfaEnabled, appErr := s.mfaService.IsEnabled(ctx, user.ID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if mfaEnabled {
+		// Failure counts are kept until the second factor passes, so signing in again with the
+		// password does not reset the limit on guessing codes
+		return newMFAChallenge(s.tokenService, user)
+	}
+
+	s.loginAttemptService.RecordSuccess(ctx, req.Email)
+
+	// Generate tokens
+	tokens, appErr := s.tokenService.GenerateTokenPair(ctx, user, req.Device)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return &dtos.SignInResponse{
+		TokenResponse: &dtos.TokenResponse{
+			AccessToken:  tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			ExpiresIn:    tokens.ExpiresIn,
+		},
+	}, nil
+}
+
+func (s *authService) VerifyMFAChallenge(ctx context.Context, req *dtos.MFAChallengeRequest) (*dtos.TokenResponse, *exception.AppError) {
+	s.logger.Info("[VERIFY MFA CHALLENGE]", req.Device)
+
+	claims, appErr := s.tokenService.ParseActionToken(req.MFAToken, dtos.ActionTokenMFAChallenge)
+	if appErr != nil {
+		return nil, exception.Unauthorized(errors.CodeTokenInvalid, errors.ErrMFAChallengeInvalid)
+	}
+
+	if appErr := s.loginAttemptService.Reserve(ctx, claims.Email, req.Device.IP); appErr != nil {
+		return nil, appErr
+	}
+	failed := false
+	defer func() {
+		if !failed {
+			s.loginAttemptService.Release(ctx, claims.Email, req.Device.IP)
+		}
+	}()
+
+	user, err := s.authRepo.GetUserByIDIncludePassword(ctx, claims.UserID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrUserNotFound)
+			return nil, exception.Unauthorized(errors.CodeTokenInvalid, errors.ErrMFAChallengeInvalid)
 		}
 		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
+	// A password change since the challenge was issued voids it
+	if claims.Fingerprint != passwordFingerprint(user.Password) {
+		return nil, exception.Unauthorized(errors.CodeTokenInvalid, errors.ErrMFAChallengeInvalid)
+	}
+
 	if !user.IsActive {
 		return nil, exception.Forbidden(errors.CodeUnauthorized, errors.ErrUserDisabled).
 			WithDetails("Your account has been disabled. Please contact support")
 	}
 
-	// Verify password
-	if !utils.VerifyEncryptHash(user.Password, req.Password) {
-		return nil, exception.Unauthorized(errors.CodeUnauthorized, errors.ErrInvalidCredentials).
-			WithDetails("Email or password is incorrect")
+	ok, appErr := s.mfaService.VerifyCode(ctx, user.ID, req.Code)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if !ok {
+		failed = true
+		if appErr := s.recordFailedSignIn(ctx, claims.Email, req.Device.IP, user); appErr != nil {
+			return nil, appErr
+		}
+		return nil, exception.Unauthorized(errors.CodeMFAInvalid, errors.ErrMFACodeInvalid)
+	}
+
+	if appErr := s.tokenService.ConsumeActionToken(ctx, claims); appErr != nil {
+		return nil, exception.Unauthorized(errors.CodeTokenInvalid, errors.ErrMFAChallengeInvalid)
 	}
 
-	// Update last login
+	s.loginAttemptService.RecordSuccess(ctx, claims.Email)
 
-	// Generate tokens
-	tokens, appErr := s.tokenService.GenerateTokenPair(ctx, user)
+	tokens, appErr := s.tokenService.GenerateTokenPair(ctx, user, req.Device)
 	if appErr != nil {
 		return nil, appErr
 	}
@@ -137,15 +286,8 @@ func (s *authService) SignIn(ctx context.Context, req *dtos.SignInRequest) (*dto
 func (s *authService) RefreshToken(ctx context.Context, authUser *dtos.UserSession) (*dtos.TokenResponse, *exception.AppError) {
 	s.logger.Info("[REFRESH TOKEN]", authUser)
 
-	s.tokenService.RevokeToken(ctx, strconv.FormatInt(authUser.UserID, 10))
-
-	// Generate new tokens
-	tokens, appErr := s.tokenService.GenerateTokenPair(ctx, &models.User{
-		ID:       authUser.UserID,
-		Username: authUser.Username,
-		Email:    authUser.Email,
-		IsActive: authUser.IsActive,
-	})
+	// Rotate tokens within the same login session
+	tokens, appErr := s.tokenService.RefreshTokenPair(ctx, authUser)
 	if appErr != nil {
 		return nil, appErr
 	}
@@ -157,8 +299,275 @@ func (s *authService) RefreshToken(ctx context.Context, authUser *dtos.UserSessi
 	}, nil
 }
 
-func (s *authService) SignOut(ctx context.Context, authUser *dtos.UserSession) {
+func (s *authService) SignOut(ctx context.Context, authUser *dtos.UserSession) *exception.AppError {
 	s.logger.Info("[SIGN OUT]", authUser)
 
-	s.tokenService.RevokeToken(ctx, strconv.FormatInt(authUser.UserID, 10))
+	return s.tokenService.RevokeSession(ctx, authUser.UserID, authUser.SessionID)
+}
+
+func (s *authService) SignOutAll(ctx context.Context, authUser *dtos.UserSession) *exception.AppError {
+	s.logger.Info("[SIGN OUT ALL]", authUser)
+
+	return s.tokenService.RevokeAllSessions(ctx, authUser.UserID)
+}
+
+func (s *authService) ListSessions(ctx context.Context, authUser *dtos.UserSession) ([]*models.AuthSession, *exception.AppError) {
+	s.logger.Info("[LIST LOGIN SESSIONS]", authUser)
+
+	return s.tokenService.ListSessions(ctx, authUser)
+}
+
+func (s *authService) RevokeSession(ctx context.Context, authUser *dtos.UserSession, req *dtos.RevokeAuthSessionRequest) *exception.AppError {
+	s.logger.Info("[REVOKE LOGIN SESSION]", authUser, req)
+
+	return s.tokenService.RevokeSession(ctx, authUser.UserID, req.SessionID)
+}
+
+func (s *authService) ForgotPassword(ctx context.Context, req *dtos.ForgotPasswordRequest) *exception.AppError {
+	s.logger.Info("[FORGOT PASSWORD]", req.Email)
+
+	user, err := s.authRepo.GetUserByEmailIncludePassword(ctx, req.Email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil
+		}
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	token, appErr := s.tokenService.GenerateActionToken(user, dtos.ActionTokenPasswordReset, passwordFingerprint(user.Password))
+	if appErr != nil {
+		return appErr
+	}
+
+	s.sendMail(&mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes.\n\n%s\n\n"+
+			"If you did not ask for this, you can ignore this email.",
+			user.Username, s.config.Auth.GetPasswordResetTTL()/60, s.actionLink("reset-password", token)),
+	})
+
+	return nil
+}
+
+func (s *authService) ResetPassword(ctx context.Context, req *dtos.ResetPasswordRequest) *exception.AppError {
+	s.logger.Info("[RESET PASSWORD]")
+
+	claims, appErr := s.tokenService.ParseActionToken(req.Token, dtos.ActionTokenPasswordReset)
+	if appErr != nil {
+		return appErr
+	}
+
+	user, err := s.authRepo.GetUserByIDIncludePassword(ctx, claims.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return exception.BadRequest(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+				WithDetails("The link is invalid or has expired")
+		}
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	// A link sent before the password last changed must not be able to change it again
+	if claims.Fingerprint != passwordFingerprint(user.Password) {
+		return exception.BadRequest(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+			WithDetails("The link is no longer valid")
+	}
+	if !user.IsActive {
+		return exception.Forbidden(errors.CodeUnauthorized, errors.ErrUserDisabled).
+			WithDetails("Your account has been disabled. Please contact support")
+	}
+
+	if appErr := s.tokenService.ConsumeActionToken(ctx, claims); appErr != nil {
+		return appErr
+	}
+
+	hashedPassword, err := utils.EncryptToHash(req.Password)
+	if err != nil {
+		return exception.InternalError(errors.CodeInternal, errors.ErrPasswordHash)
+	}
+
+	// API keys outlive login sessions, so whoever took over the account could keep using one
+	_, err = database.NewTransaction[struct{}](s.pool).Execute(ctx, func(ctx context.Context) (*struct{}, error) {
+		if err := s.authRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+			return nil, err
+		}
+
+		deletedKeys, err := s.apiKeyRepo.DeleteAPIKeysByUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if deletedKeys > 0 {
+			s.logger.Info("[RESET PASSWORD] Deleted API keys", user.ID, deletedKeys)
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	// Whoever was guessing the old password has nothing left to guess
+	if appErr := s.loginAttemptService.Unlock(ctx, user.Email); appErr != nil {
+		return appErr
+	}
+
+	return s.tokenService.RevokeAllSessions(ctx, user.ID)
+}
+
+func (s *authService) VerifyEmail(ctx context.Context, req *dtos.VerifyEmailRequest) *exception.AppError {
+	s.logger.Info("[VERIFY EMAIL]")
+
+	claims, appErr := s.tokenService.ParseActionToken(req.Token, dtos.ActionTokenEmailVerification)
+	if appErr != nil {
+		return appErr
+	}
+
+	if appErr := s.tokenService.ConsumeActionToken(ctx, claims); appErr != nil {
+		return appErr
+	}
+
+	verified, err := s.authRepo.MarkEmailVerified(ctx, claims.UserID, claims.Email)
+	if err != nil {
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	if !verified {
+		return exception.BadRequest(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+			WithDetails("The email is already verified or no longer belongs to the account")
+	}
+
+	return nil
+}
+
+func (s *authService) SendVerificationEmail(ctx context.Context, authUser *dtos.UserSession) *exception.AppError {
+	s.logger.Info("[SEND VERIFICATION EMAIL]", authUser)
+
+	user, err := s.authRepo.GetUserByIDIncludePassword(ctx, authUser.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return exception.NotFound(errors.CodeNotFound, errors.ErrUserNotFound)
+		}
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	if user.EmailVerified {
+		return exception.BadRequest(errors.CodeBadRequest, errors.ErrEmailAlreadyVerified)
+	}
+
+	return s.sendVerificationEmail(user)
+}
+
+func (s *authService) UnlockAccount(ctx context.Context, req *dtos.UnlockAccountRequest) *exception.AppError {
+	s.logger.Info("[UNLOCK ACCOUNT]")
+
+	claims, appErr := s.tokenService.ParseActionToken(req.Token, dtos.ActionTokenAccountUnlock)
+	if appErr != nil {
+		return appErr
+	}
+
+	if appErr := s.tokenService.ConsumeActionToken(ctx, claims); appErr != nil {
+		return appErr
+	}
+
+	return s.loginAttemptService.Unlock(ctx, claims.Email)
+}
+
+func (s *authService) GetJWKS() *jwtkeys.JSONWebKeySet {
+	return s.tokenService.JWKS()
+}
+
+// newMFAChallenge returns the response asking for the second factor. The token is tied to the
+// password, so it stops working when the password changes.
+func newMFAChallenge(tokenService TokenService, user *models.User) (*dtos.SignInResponse, *exception.AppError) {
+	token, appErr := tokenService.GenerateActionToken(user, dtos.ActionTokenMFAChallenge, passwordFingerprint(user.Password))
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return &dtos.SignInResponse{
+		MFARequired: true,
+		MFAToken:    token,
+	}, nil
+}
+
+// failSignIn counts the failure and returns the same error for an unknown email as for a wrong password
+func (s *authService) failSignIn(ctx context.Context, email, ip string, user *models.User) *exception.AppError {
+	if appErr := s.recordFailedSignIn(ctx, email, ip, user); appErr != nil {
+		return appErr
+	}
+
+	return exception.Unauthorized(errors.CodeUnauthorized, errors.ErrInvalidCredentials).
+		WithDetails("Email or password is incorrect")
+}
+
+// recordFailedSignIn counts a wrong password or second factor code, emailing an unlock link when
+// it locks the account
+func (s *authService) recordFailedSignIn(ctx context.Context, email, ip string, user *models.User) *exception.AppError {
+	locked, appErr := s.loginAttemptService.RecordFailure(ctx, email, ip)
+	if appErr != nil {
+		return appErr
+	}
+
+	if locked && user != nil {
+		if appErr := s.sendUnlockEmail(user); appErr != nil {
+			s.logger.Error("[SIGN IN] Failed to send unlock email", appErr.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *authService) sendUnlockEmail(user *models.User) *exception.AppError {
+	token, appErr := s.tokenService.GenerateActionToken(user, dtos.ActionTokenAccountUnlock, "")
+	if appErr != nil {
+		return appErr
+	}
+
+	s.sendMail(&mailer.Message{
+		To:      user.Email,
+		Subject: "Your account has been locked",
+		Body: fmt.Sprintf("Hi %s,\n\nThere were too many failed attempts to sign in to your account, so sign-in is blocked for %d minutes.\n\n"+
+			"If this was you, open the link below to unlock it now:\n\n%s\n\n"+
+			"If it was not, consider resetting your password.",
+			user.Username, s.config.Auth.GetLockoutDuration()/60, s.actionLink("unlock-account", token)),
+	})
+
+	return nil
+}
+
+func (s *authService) sendVerificationEmail(user *models.User) *exception.AppError {
+	token, appErr := s.tokenService.GenerateActionToken(user, dtos.ActionTokenEmailVerification, "")
+	if appErr != nil {
+		return appErr
+	}
+
+	s.sendMail(&mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm this is your email address by opening the link below. It expires in %d hours.\n\n%s",
+			user.Username, s.config.Auth.GetEmailVerificationTTL()/3600, s.actionLink("verify-email", token)),
+	})
+
+	return nil
+}
+
+// sendMail delivers in the background so slow mail servers do not hold up the request, and so
+// response times do not reveal whether an email was sent
+func (s *authService) sendMail(msg *mailer.Message) {
+	go func() {
+		if err := s.mailer.Send(context.Background(), msg); err != nil {
+			s.logger.Error("[SEND MAIL] "+msg.Subject, err)
+		}
+	}()
+}
+
+func (s *authService) actionLink(path, token string) string {
+	return s.config.Auth.GetFrontendURL() + "/" + path + "?token=" + url.QueryEscape(token)
+}
+
+// passwordFingerprint identifies the current password without putting its hash in a token
+func passwordFingerprint(hashedPassword string) string {
+	sum := sha256.Sum256([]byte(hashedPassword))
+	return hex.EncodeToString(sum[:8])
 }
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/cache"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+)
+
+const (
+	// Failed sign-ins allowed before each further attempt has to wait
+	freeLoginAttempts = 3
+	maxLoginDelay     = time.Minute
+)
+
+type (
+	// LoginAttemptService throttles password guessing. Failures are counted per email, whether or not
+	// an account exists for it, and per IP. After a few failures the email has to wait longer and longer
+	// between attempts, and enough of them lock it for a while.
+	LoginAttemptService interface {
+		// CheckAllowed rejects a sign-in while the IP is throttled or the email is delayed or locked.
+		// It only reads the counters, so it suits sign-ins that do not guess a secret.
+		CheckAllowed(ctx context.Context, email, ip string) *exception.AppError
+		// Reserve counts an attempt before the password or code is checked and rejects it while the
+		// IP is throttled or the email is delayed or locked. Each step is atomic, so parallel guesses
+		// cannot all slip past the limits. The attempt stays counted as a failure unless Release gives it back.
+		Reserve(ctx context.Context, email, ip string) *exception.AppError
+		// Release gives back an attempt taken with Reserve that did not turn out to be a failure
+		Release(ctx context.Context, email, ip string)
+		// RecordFailure keeps a reserved attempt counted and reports whether it locked the email
+		RecordFailure(ctx context.Context, email, ip string) (bool, *exception.AppError)
+		RecordSuccess(ctx context.Context, email string)
+		Unlock(ctx context.Context, email string) *exception.AppError
+	}
+
+	loginAttemptService struct {
+		cache  cache.Cache
+		config *config.Config
+	}
+)
+
+var (
+	loginAttemptServiceOnce     sync.Once
+	loginAttemptServiceInstance LoginAttemptService
+)
+
+func ProvideLoginAttemptService(
+	cache cache.Cache,
+	config *config.Config,
+) LoginAttemptService {
+	loginAttemptServiceOnce.Do(func() {
+		loginAttemptServiceInstance = &loginAttemptService{
+			cache:  cache,
+			config: config,
+		}
+	})
+	return loginAttemptServiceInstance
+}
+
+func (s *loginAttemptService) CheckAllowed(ctx context.Context, email, ip string) *exception.AppError {
+	ipFailures, appErr := s.getCount(ctx, s.ipFailureKey(ip))
+	if appErr != nil {
+		return appErr
+	}
+	if ipFailures >= int64(s.config.Auth.GetMaxFailedAttemptsPerIP()) {
+		retryAfter, appErr := s.cache.GetTTL(ctx, s.ipFailureKey(ip))
+		if appErr != nil {
+			return appErr
+		}
+		return exception.TooManyRequests(errors.CodeLoginThrottled, errors.ErrTooManyLoginAttempts).
+			WithDetails("Too many failed sign-ins from this network. Please try again later").
+			WithMetadata("retry_after", retryAfterSeconds(retryAfter))
+	}
+
+	if appErr := s.checkLocked(ctx, email); appErr != nil {
+		return appErr
+	}
+
+	delayedFor, appErr := s.cache.GetTTL(ctx, s.emailKey(constants.CachePrefixLoginDelay, email))
+	if appErr != nil {
+		return appErr
+	}
+	if delayedFor > 0 {
+		return exception.TooManyRequests(errors.CodeLoginThrottled, errors.ErrTooManyLoginAttempts).
+			WithDetails(fmt.Sprintf("Please wait %d seconds before trying again", retryAfterSeconds(delayedFor))).
+			WithMetadata("retry_after", retryAfterSeconds(delayedFor))
+	}
+
+	return nil
+}
+
+func (s *loginAttemptService) Reserve(ctx context.Context, email, ip string) *exception.AppError {
+	if appErr := s.checkLocked(ctx, email); appErr != nil {
+		return appErr
+	}
+
+	ipKey := s.ipFailureKey(ip)
+	_, reserved, appErr := s.cache.ReserveSlot(ctx, ipKey, int64(s.config.Auth.GetMaxFailedAttemptsPerIP()))
+	if appErr != nil {
+		return appErr
+	}
+	if !reserved {
+		retryAfter, appErr := s.cache.GetTTL(ctx, ipKey)
+		if appErr != nil {
+			return appErr
+		}
+		return exception.TooManyRequests(errors.CodeLoginThrottled, errors.ErrTooManyLoginAttempts).
+			WithDetails("Too many failed sign-ins from this network. Please try again later").
+			WithMetadata("retry_after", retryAfterSeconds(retryAfter))
+	}
+
+	failures, reserved, appErr := s.cache.ReserveSlot(ctx, s.failureKey(email), int64(s.config.Auth.GetMaxFailedAttempts()))
+	if appErr != nil {
+		s.cache.ReleaseSlot(ctx, ipKey)
+		return appErr
+	}
+	if !reserved {
+		// The attempt that used the last slot is still in flight and will lock the email if it fails
+		s.cache.ReleaseSlot(ctx, ipKey)
+		return exception.Forbidden(errors.CodeAccountLocked, errors.ErrAccountLocked).
+			WithDetails("Too many failed sign-ins. Try again later, or use the unlock link sent to your email").
+			WithMetadata("retry_after", s.config.Auth.GetLockoutDuration())
+	}
+
+	if failures > freeLoginAttempts {
+		// Claiming the delay with SET NX lets only one of several parallel attempts through
+		delayKey := s.emailKey(constants.CachePrefixLoginDelay, email)
+		delayKey.Value = true
+		delayKey.TTL = loginDelay(failures)
+		claimed, appErr := s.cache.SetIfNotExists(ctx, delayKey)
+		if appErr != nil || !claimed {
+			s.Release(ctx, email, ip)
+			if appErr != nil {
+				return appErr
+			}
+
+			delayedFor, appErr := s.cache.GetTTL(ctx, delayKey)
+			if appErr != nil {
+				return appErr
+			}
+			return exception.TooManyRequests(errors.CodeLoginThrottled, errors.ErrTooManyLoginAttempts).
+				WithDetails(fmt.Sprintf("Please wait %d seconds before trying again", retryAfterSeconds(delayedFor))).
+				WithMetadata("retry_after", retryAfterSeconds(delayedFor))
+		}
+	}
+
+	return nil
+}
+
+func (s *loginAttemptService) Release(ctx context.Context, email, ip string) {
+	s.cache.ReleaseSlot(ctx, s.ipFailureKey(ip))
+	s.cache.ReleaseSlot(ctx, s.failureKey(email))
+}
+
+func (s *loginAttemptService) RecordFailure(ctx context.Context, email, ip string) (bool, *exception.AppError) {
+	failureKey := s.failureKey(email)
+	failures, appErr := s.getCount(ctx, failureKey)
+	if appErr != nil {
+		return false, appErr
+	}
+
+	if failures >= int64(s.config.Auth.GetMaxFailedAttempts()) {
+		lockKey := s.emailKey(constants.CachePrefixAccountLock, email)
+		lockKey.Value = true
+		lockKey.TTL = time.Duration(s.config.Auth.GetLockoutDuration()) * time.Second
+		if appErr := s.cache.Set(ctx, lockKey); appErr != nil {
+			return false, appErr
+		}
+
+		// Start counting afresh once the lock runs out
+		s.cache.Del(ctx, failureKey)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (s *loginAttemptService) RecordSuccess(ctx context.Context, email string) {
+	s.cache.Del(ctx, s.emailKey(constants.CachePrefixLoginFailure, email))
+	s.cache.Del(ctx, s.emailKey(constants.CachePrefixLoginDelay, email))
+}
+
+func (s *loginAttemptService) Unlock(ctx context.Context, email string) *exception.AppError {
+	for _, prefix := range []constants.CachePrefix{
+		constants.CachePrefixAccountLock,
+		constants.CachePrefixLoginFailure,
+		constants.CachePrefixLoginDelay,
+	} {
+		if appErr := s.cache.Del(ctx, s.emailKey(prefix, email)); appErr != nil {
+			return appErr
+		}
+	}
+	return nil
+}
+
+func (s *loginAttemptService) checkLocked(ctx context.Context, email string) *exception.AppError {
+	lockedFor, appErr := s.cache.GetTTL(ctx, s.emailKey(constants.CachePrefixAccountLock, email))
+	if appErr != nil {
+		return appErr
+	}
+	if lockedFor > 0 {
+		return exception.Forbidden(errors.CodeAccountLocked, errors.ErrAccountLocked).
+			WithDetails("Too many failed sign-ins. Try again later, or use the unlock link sent to your email").
+			WithMetadata("retry_after", retryAfterSeconds(lockedFor))
+	}
+
+	return nil
+}
+
+func (s *loginAttemptService) getCount(ctx context.Context, opt cache.CacheKeyOption) (int64, *exception.AppError) {
+	var count int64
+	if appErr := s.cache.GetObject(ctx, opt, &count); appErr != nil {
+		if appErr.Code == errors.CodeCacheNotFound {
+			return 0, nil
+		}
+		return 0, appErr
+	}
+	return count, nil
+}
+
+func (s *loginAttemptService) emailKey(prefix constants.CachePrefix, email string) cache.CacheKeyOption {
+	return cache.CacheKeyOption{
+		Module:    string(constants.CacheModuleAuth),
+		Prefix:    string(prefix),
+		UniqueKey: strings.ToLower(strings.TrimSpace(email)),
+	}
+}
+
+func (s *loginAttemptService) failureKey(email string) cache.CacheKeyOption {
+	opt := s.emailKey(constants.CachePrefixLoginFailure, email)
+	opt.TTL = time.Duration(s.config.Auth.GetFailedAttemptWindow()) * time.Second
+	return opt
+}
+
+func (s *loginAttemptService) ipFailureKey(ip string) cache.CacheKeyOption {
+	return cache.CacheKeyOption{
+		Module:    string(constants.CacheModuleAuth),
+		Prefix:    string(constants.CachePrefixLoginFailureIP),
+		UniqueKey: ip,
+		TTL:       time.Duration(s.config.Auth.GetFailedAttemptWindow()) * time.Second,
+	}
+}
+
+// loginDelay doubles with every failure past the free ones: 1s, 2s, 4s and so on up to maxLoginDelay
+func loginDelay(failures int64) time.Duration {
+	delay := time.Second
+	for i := int64(freeLoginAttempts + 1); i < failures; i++ {
+		delay *= 2
+		if delay >= maxLoginDelay {
+			return maxLoginDelay
+		}
+	}
+	return delay
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
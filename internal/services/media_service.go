@@ -0,0 +1,165 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	helpers "github.com/nghiavan0610/btaskee-quiz-service/helpers/media"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/storage"
+	"github.com/samber/lo"
+)
+
+type (
+	MediaService interface {
+		UploadMedia(ctx context.Context, authUser *dtos.UserSession, req *dtos.UploadMediaRequest) (*models.Media, *exception.AppError)
+		OpenMediaFile(ctx context.Context, key string) (io.ReadCloser, *exception.AppError)
+
+		// AttachMedia fills the question and answer attachments from the media they reference.
+		// Media must belong to ownerID unless it is already attached to one of the existing questions,
+		// which is the case when a collaborator edits a question the owner illustrated.
+		AttachMedia(ctx context.Context, ownerID int64, question *models.Question, existing ...models.Question) *exception.AppError
+	}
+
+	mediaService struct {
+		config    *config.Config
+		logger    *logger.Logger
+		storage   storage.Storage
+		mediaRepo repositories.MediaRepository
+	}
+)
+
+var (
+	mediaServiceOnce     sync.Once
+	mediaServiceInstance MediaService
+)
+
+func ProvideMediaService(
+	config *config.Config,
+	logger *logger.Logger,
+	storage storage.Storage,
+	mediaRepo repositories.MediaRepository,
+) MediaService {
+	mediaServiceOnce.Do(func() {
+		mediaServiceInstance = &mediaService{
+			config:    config,
+			logger:    logger,
+			storage:   storage,
+			mediaRepo: mediaRepo,
+		}
+	})
+	return mediaServiceInstance
+}
+
+func (s *mediaService) UploadMedia(ctx context.Context, authUser *dtos.UserSession, req *dtos.UploadMediaRequest) (*models.Media, *exception.AppError) {
+	s.logger.Info("[UPLOAD MEDIA]", authUser, req.FileName, len(req.Content))
+
+	if len(req.Content) == 0 {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidMediaFile).WithDetails("file is empty")
+	}
+
+	contentType, kind, ok := helpers.DetectMediaType(req.Content)
+	if !ok {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrUnsupportedMediaType).
+			WithDetails(fmt.Sprintf("%s is not an accepted image or audio type", contentType))
+	}
+
+	maxSize := s.config.Storage.GetMaxImageSize()
+	if kind == models.MediaKindAudio {
+		maxSize = s.config.Storage.GetMaxAudioSize()
+	}
+	if int64(len(req.Content)) > maxSize {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrMediaTooLarge).
+			WithDetails(fmt.Sprintf("%s files can be at most %d bytes", kind, maxSize))
+	}
+
+	key := helpers.MediaStorageKey(authUser.UserID, contentType)
+	if err := s.storage.Put(ctx, key, bytes.NewReader(req.Content), contentType); err != nil {
+		return nil, exception.InternalError(errors.CodeInternal, err.Error())
+	}
+
+	var originalName *string
+	if req.FileName != "" {
+		originalName = lo.ToPtr(lo.Substring(req.FileName, 0, 255))
+	}
+
+	media, err := s.mediaRepo.CreateMedia(ctx, &models.Media{
+		OwnerID:      authUser.UserID,
+		Kind:         kind,
+		ContentType:  contentType,
+		SizeBytes:    int64(len(req.Content)),
+		OriginalName: originalName,
+		StorageKey:   key,
+	})
+	if err != nil {
+		// Do not leave an orphaned file behind
+		if deleteErr := s.storage.Delete(context.Background(), key); deleteErr != nil {
+			s.logger.Error("Failed to delete orphaned media", map[string]interface{}{
+				"key":   key,
+				"error": deleteErr,
+			})
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	media.URL = s.storage.URL(media.StorageKey)
+
+	return media, nil
+}
+
+func (s *mediaService) OpenMediaFile(ctx context.Context, key string) (io.ReadCloser, *exception.AppError) {
+	file, err := s.storage.Open(ctx, key)
+	if err != nil {
+		if err == storage.ErrObjectNotFound {
+			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrMediaNotFound)
+		}
+		return nil, exception.InternalError(errors.CodeInternal, err.Error())
+	}
+
+	return file, nil
+}
+
+func (s *mediaService) AttachMedia(ctx context.Context, ownerID int64, question *models.Question, existing ...models.Question) *exception.AppError {
+	attachments := helpers.QuestionMediaAttachments(question)
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	allowed := make(map[int64]bool)
+	for i := range existing {
+		for _, attachment := range helpers.QuestionMediaAttachments(&existing[i]) {
+			allowed[attachment.ID] = true
+		}
+	}
+
+	ids := lo.Uniq(lo.Map(attachments, func(attachment *models.MediaAttachment, _ int) int64 { return attachment.ID }))
+
+	media, err := s.mediaRepo.GetMediaByIDs(ctx, ids)
+	if err != nil {
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	mediaByID := lo.KeyBy(media, func(m *models.Media) int64 { return m.ID })
+	for _, attachment := range attachments {
+		m, ok := mediaByID[attachment.ID]
+		if !ok || (m.OwnerID != ownerID && !allowed[m.ID]) {
+			return exception.BadRequest(errors.CodeValidation, errors.ErrMediaNotFound).
+				WithDetails(fmt.Sprintf("media %d does not exist or is not yours", attachment.ID))
+		}
+
+		attachment.Kind = m.Kind
+		attachment.ContentType = m.ContentType
+		attachment.URL = s.storage.URL(m.StorageKey)
+	}
+
+	return nil
+}
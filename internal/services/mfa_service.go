@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	goErrors "errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/totp"
+)
+
+const recoveryCodeCount = 10
+
+type (
+	// MFAService manages the TOTP second factor and its recovery codes. Sign-in asks it whether a
+	// second factor is needed and to check the code.
+	MFAService interface {
+		GetStatus(ctx context.Context, authUser *dtos.UserSession) (*dtos.MFAStatusResponse, *exception.AppError)
+		// EnrollTOTP issues a new secret. It protects sign-in once ConfirmTOTP accepts a code from it.
+		EnrollTOTP(ctx context.Context, authUser *dtos.UserSession) (*dtos.EnrollTOTPResponse, *exception.AppError)
+		ConfirmTOTP(ctx context.Context, authUser *dtos.UserSession, req *dtos.ConfirmTOTPRequest) (*dtos.RecoveryCodesResponse, *exception.AppError)
+		// RegenerateRecoveryCodes replaces every recovery code, used or not
+		RegenerateRecoveryCodes(ctx context.Context, authUser *dtos.UserSession, req *dtos.MFACodeRequest) (*dtos.RecoveryCodesResponse, *exception.AppError)
+		DisableTOTP(ctx context.Context, authUser *dtos.UserSession, req *dtos.MFACodeRequest) *exception.AppError
+		IsEnabled(ctx context.Context, userID int64) (bool, *exception.AppError)
+		// VerifyCode accepts a TOTP code or an unused recovery code. Either is used up, so the same
+		// code cannot be accepted twice.
+		VerifyCode(ctx context.Context, userID int64, code string) (bool, *exception.AppError)
+	}
+
+	mfaService struct {
+		pool                *pgxpool.Pool
+		config              *config.Config
+		logger              *logger.Logger
+		mfaRepo             repositories.MFARepository
+		loginAttemptService LoginAttemptService
+	}
+)
+
+var (
+	mfaServiceOnce     sync.Once
+	mfaServiceInstance MFAService
+)
+
+func ProvideMFAService(
+	pool *pgxpool.Pool,
+	config *config.Config,
+	logger *logger.Logger,
+	mfaRepo repositories.MFARepository,
+	loginAttemptService LoginAttemptService,
+) MFAService {
+	mfaServiceOnce.Do(func() {
+		mfaServiceInstance = &mfaService{
+			pool:                pool,
+			config:              config,
+			logger:              logger,
+			mfaRepo:             mfaRepo,
+			loginAttemptService: loginAttemptService,
+		}
+	})
+	return mfaServiceInstance
+}
+
+func (s *mfaService) GetStatus(ctx context.Context, authUser *dtos.UserSession) (*dtos.MFAStatusResponse, *exception.AppError) {
+	s.logger.Info("[GET MFA STATUS]", authUser)
+
+	enabled, appErr := s.IsEnabled(ctx, authUser.UserID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	remaining, err := s.mfaRepo.CountUnusedRecoveryCodes(ctx, authUser.UserID)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return &dtos.MFAStatusResponse{
+		TOTPEnabled:            enabled,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+func (s *mfaService) EnrollTOTP(ctx context.Context, authUser *dtos.UserSession) (*dtos.EnrollTOTPResponse, *exception.AppError) {
+	s.logger.Info("[ENROLL TOTP]", authUser)
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeTokenGenerateFailed, errors.ErrTokenGenerateFailed)
+	}
+
+	if _, err := s.mfaRepo.UpsertPendingTOTP(ctx, authUser.UserID, secret); err != nil {
+		if goErrors.Is(err, pgx.ErrNoRows) {
+			return nil, exception.Conflict(errors.CodeConflict, errors.ErrMFAAlreadyEnabled)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return &dtos.EnrollTOTPResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.KeyURI(s.config.Auth.GetTOTPIssuer(), authUser.Email, secret),
+	}, nil
+}
+
+func (s *mfaService) ConfirmTOTP(ctx context.Context, authUser *dtos.UserSession, req *dtos.ConfirmTOTPRequest) (*dtos.RecoveryCodesResponse, *exception.AppError) {
+	s.logger.Info("[CONFIRM TOTP]", authUser)
+
+	userTOTP, err := s.mfaRepo.GetUserTOTP(ctx, authUser.UserID)
+	if err != nil {
+		if goErrors.Is(err, pgx.ErrNoRows) {
+			return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrMFANotEnrolling)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	if userTOTP.IsEnabled() {
+		return nil, exception.Conflict(errors.CodeConflict, errors.ErrMFAAlreadyEnabled)
+	}
+
+	step, ok := totp.Validate(userTOTP.Secret, req.Code, time.Now())
+	if !ok {
+		return nil, exception.BadRequest(errors.CodeMFAInvalid, errors.ErrMFACodeInvalid)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeTokenGenerateFailed, errors.ErrTokenGenerateFailed)
+	}
+
+	_, err = database.NewTransaction[struct{}](s.pool).Execute(ctx, func(ctx context.Context) (*struct{}, error) {
+		enabled, err := s.mfaRepo.EnableTOTP(ctx, authUser.UserID, step)
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			return nil, exception.Conflict(errors.CodeConflict, errors.ErrMFAAlreadyEnabled)
+		}
+
+		return nil, s.replaceRecoveryCodes(ctx, authUser.UserID, hashes)
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return &dtos.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, authUser *dtos.UserSession, req *dtos.MFACodeRequest) (*dtos.RecoveryCodesResponse, *exception.AppError) {
+	s.logger.Info("[REGENERATE RECOVERY CODES]", authUser)
+
+	if appErr := s.requireCode(ctx, authUser, req); appErr != nil {
+		return nil, appErr
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeTokenGenerateFailed, errors.ErrTokenGenerateFailed)
+	}
+
+	_, err = database.NewTransaction[struct{}](s.pool).Execute(ctx, func(ctx context.Context) (*struct{}, error) {
+		return nil, s.replaceRecoveryCodes(ctx, authUser.UserID, hashes)
+	})
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return &dtos.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *mfaService) DisableTOTP(ctx context.Context, authUser *dtos.UserSession, req *dtos.MFACodeRequest) *exception.AppError {
+	s.logger.Info("[DISABLE TOTP]", authUser)
+
+	if appErr := s.requireCode(ctx, authUser, req); appErr != nil {
+		return appErr
+	}
+
+	_, err := database.NewTransaction[struct{}](s.pool).Execute(ctx, func(ctx context.Context) (*struct{}, error) {
+		if err := s.mfaRepo.DeleteTOTP(ctx, authUser.UserID); err != nil {
+			return nil, err
+		}
+		return nil, s.mfaRepo.DeleteRecoveryCodes(ctx, authUser.UserID)
+	})
+	if err != nil {
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return nil
+}
+
+func (s *mfaService) IsEnabled(ctx context.Context, userID int64) (bool, *exception.AppError) {
+	userTOTP, err := s.mfaRepo.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if goErrors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return userTOTP.IsEnabled(), nil
+}
+
+func (s *mfaService) VerifyCode(ctx context.Context, userID int64, code string) (bool, *exception.AppError) {
+	userTOTP, err := s.mfaRepo.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if goErrors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	if !userTOTP.IsEnabled() {
+		return false, nil
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(userTOTP.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		// Refuses a step already used, so a code seen over someone's shoulder cannot sign in again
+		used, err := s.mfaRepo.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			return false, exception.InternalError(errors.CodeDBError, err.Error())
+		}
+		return used, nil
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return false, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	if used {
+		s.logger.Info("[VERIFY MFA CODE] Recovery code used", userID)
+	}
+
+	return used, nil
+}
+
+// requireCode guards changes to an enabled second factor behind a fresh code. Wrong codes count
+// as failed sign-ins, so a stolen session cannot guess its way to turning the factor off.
+func (s *mfaService) requireCode(ctx context.Context, authUser *dtos.UserSession, req *dtos.MFACodeRequest) *exception.AppError {
+	enabled, appErr := s.IsEnabled(ctx, authUser.UserID)
+	if appErr != nil {
+		return appErr
+	}
+	if !enabled {
+		return exception.BadRequest(errors.CodeBadRequest, errors.ErrMFANotEnabled)
+	}
+
+	if appErr := s.loginAttemptService.Reserve(ctx, authUser.Email, req.Device.IP); appErr != nil {
+		return appErr
+	}
+
+	ok, appErr := s.VerifyCode(ctx, authUser.UserID, req.Code)
+	if appErr != nil {
+		s.loginAttemptService.Release(ctx, authUser.Email, req.Device.IP)
+		return appErr
+	}
+	if !ok {
+		if _, appErr := s.loginAttemptService.RecordFailure(ctx, authUser.Email, req.Device.IP); appErr != nil {
+			return appErr
+		}
+		return exception.BadRequest(errors.CodeMFAInvalid, errors.ErrMFACodeInvalid)
+	}
+
+	s.loginAttemptService.RecordSuccess(ctx, authUser.Email)
+	s.loginAttemptService.Release(ctx, authUser.Email, req.Device.IP)
+
+	return nil
+}
+
+func (s *mfaService) replaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error {
+	if err := s.mfaRepo.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return err
+	}
+	return s.mfaRepo.CreateRecoveryCodes(ctx, userID, hashes)
+}
+
+// generateRecoveryCodes returns codes like "k7m2q-x9d4a" with the hashes to store
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case and separators, so codes can be typed back however they were copied
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	goErrors "errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/samber/lo"
+	"golang.org/x/oauth2"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/cache"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+	"github.com/nghiavan0610/btaskee-quiz-service/utils"
+)
+
+const (
+	oidcUsernameMaxLength = 40
+	oidcUsernameAttempts  = 5
+	oidcHTTPTimeout       = 10 * time.Second
+)
+
+var oidcUsernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+type (
+	// OIDCService signs users in through external OpenID Connect providers with the authorization
+	// code flow and PKCE. The provider account is linked to a user through an identity, which is
+	// created on first sign-in.
+	OIDCService interface {
+		ListProviders() []*dtos.OIDCProviderResponse
+		// StartLogin returns the provider URL to send the browser to
+		StartLogin(ctx context.Context, req *dtos.OIDCLoginRequest) (*dtos.OIDCLoginResponse, *exception.AppError)
+		// CompleteLogin exchanges the code the provider redirected back with for a token pair, or for
+		// an MFA challenge when the user has two-factor authentication on
+		CompleteLogin(ctx context.Context, req *dtos.OIDCCallbackRequest) (*dtos.SignInResponse, *exception.AppError)
+	}
+
+	oidcService struct {
+		pool         *pgxpool.Pool
+		cache        cache.Cache
+		config       *config.Config
+		logger       *logger.Logger
+		authRepo     repositories.AuthRepository
+		identityRepo repositories.IdentityRepository
+		tokenService TokenService
+
+		loginAttemptService LoginAttemptService
+		mfaService          MFAService
+
+		clientsMu sync.Mutex
+		clients   map[string]*oidcClient
+	}
+
+	// oidcClient is a provider whose discovery document has been fetched
+	oidcClient struct {
+		oauth2   *oauth2.Config
+		verifier *oidc.IDTokenVerifier
+	}
+)
+
+var (
+	oidcServiceOnce     sync.Once
+	oidcServiceInstance OIDCService
+)
+
+func ProvideOIDCService(
+	pool *pgxpool.Pool,
+	cache cache.Cache,
+	config *config.Config,
+	logger *logger.Logger,
+	authRepo repositories.AuthRepository,
+	identityRepo repositories.IdentityRepository,
+	tokenService TokenService,
+	loginAttemptService LoginAttemptService,
+	mfaService MFAService,
+) OIDCService {
+	oidcServiceOnce.Do(func() {
+		oidcServiceInstance = &oidcService{
+			pool:                pool,
+			cache:               cache,
+			config:              config,
+			logger:              logger,
+			authRepo:            authRepo,
+			identityRepo:        identityRepo,
+			tokenService:        tokenService,
+			loginAttemptService: loginAttemptService,
+			mfaService:          mfaService,
+			clients:             make(map[string]*oidcClient),
+		}
+	})
+	return oidcServiceInstance
+}
+
+func (s *oidcService) ListProviders() []*dtos.OIDCProviderResponse {
+	return lo.Map(s.config.OIDC.Providers, func(p config.OIDCProviderConfig, _ int) *dtos.OIDCProviderResponse {
+		return &dtos.OIDCProviderResponse{
+			Name:        p.Name,
+			DisplayName: p.GetDisplayName(),
+		}
+	})
+}
+
+func (s *oidcService) StartLogin(ctx context.Context, req *dtos.OIDCLoginRequest) (*dtos.OIDCLoginResponse, *exception.AppError) {
+	s.logger.Info("[OIDC START LOGIN]", req)
+
+	client, appErr := s.getClient(req.Provider)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeInternal, err.Error())
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeInternal, err.Error())
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	if appErr := s.cache.Set(ctx, cache.CacheKeyOption{
+		Prefix:    string(constants.CachePrefixOIDCState),
+		UniqueKey: state,
+		Value: &dtos.CachedOIDCLogin{
+			Provider:     req.Provider,
+			Nonce:        nonce,
+			CodeVerifier: verifier,
+		},
+		TTL: time.Duration(s.config.OIDC.GetStateTTL()) * time.Second,
+	}); appErr != nil {
+		return nil, appErr
+	}
+
+	return &dtos.OIDCLoginResponse{
+		AuthorizationURL: client.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)),
+		State:            state,
+	}, nil
+}
+
+func (s *oidcService) CompleteLogin(ctx context.Context, req *dtos.OIDCCallbackRequest) (*dtos.SignInResponse, *exception.AppError) {
+	s.logger.Info("[OIDC COMPLETE LOGIN]", req.Provider, req.Device)
+
+	client, appErr := s.getClient(req.Provider)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	pending, appErr := s.consumeState(ctx, req.State)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if pending.Provider != req.Provider {
+		return nil, s.loginFailed("The sign-in was started with a different provider")
+	}
+
+	token, err := client.oauth2.Exchange(ctx, req.Code, oauth2.VerifierOption(pending.CodeVerifier))
+	if err != nil {
+		s.logger.Error("[OIDC COMPLETE LOGIN] Code exchange failed for "+req.Provider, err)
+		return nil, s.loginFailed("The provider rejected the authorization code")
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, s.loginFailed("The provider did not return an ID token")
+	}
+
+	idToken, err := client.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		s.logger.Error("[OIDC COMPLETE LOGIN] ID token verification failed for "+req.Provider, err)
+		return nil, s.loginFailed("The ID token is invalid")
+	}
+	if idToken.Nonce != pending.Nonce {
+		return nil, s.loginFailed("The ID token was not issued for this sign-in")
+	}
+
+	var claims dtos.OIDCClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, s.loginFailed("The ID token claims could not be read")
+	}
+	claims.Subject = idToken.Subject
+
+	user, appErr := s.findOrCreateUser(ctx, req.Provider, &claims)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !user.IsActive {
+		return nil, exception.Forbidden(errors.CodeUnauthorized, errors.ErrUserDisabled).
+			WithDetails("Your account has been disabled. Please contact support")
+	}
+
+	// A locked account stays locked whichever way it signs in
+	if appErr := s.loginAttemptService.CheckAllowed(ctx, user.Email, req.Device.IP); appErr != nil {
+		return nil, appErr
+	}
+
+	mfaEnabled, appErr := s.mfaService.IsEnabled(ctx, user.ID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if mfaEnabled {
+		return newMFAChallenge(s.tokenService, user)
+	}
+
+	tokens, appErr := s.tokenService.GenerateTokenPair(ctx, user, req.Device)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return &dtos.SignInResponse{TokenResponse: tokens}, nil
+}
+
+// findOrCreateUser returns the user linked to the provider account, linking or creating one on first sign-in
+func (s *oidcService) findOrCreateUser(ctx context.Context, provider string, claims *dtos.OIDCClaims) (*models.User, *exception.AppError) {
+	var email *string
+	if claims.Email != "" {
+		email = &claims.Email
+	}
+
+	identity, err := s.identityRepo.GetIdentity(ctx, provider, claims.Subject)
+	if err != nil && !goErrors.Is(err, pgx.ErrNoRows) {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	if identity != nil {
+		if err := s.identityRepo.UpdateIdentityLogin(ctx, identity.ID, email); err != nil {
+			return nil, exception.InternalError(errors.CodeDBError, err.Error())
+		}
+
+		user, err := s.authRepo.GetUserByIDIncludePassword(ctx, identity.UserID)
+		if err != nil {
+			return nil, exception.InternalError(errors.CodeDBError, err.Error())
+		}
+		return user, nil
+	}
+
+	if email == nil {
+		return nil, exception.BadRequest(errors.CodeOIDCFailed, errors.ErrOIDCLoginFailed).
+			WithDetails("The provider did not share an email address")
+	}
+
+	user, err := database.NewTransaction[models.User](s.pool).Execute(ctx, func(ctx context.Context) (*models.User, error) {
+		user, err := s.authRepo.GetUserByEmailIncludePassword(ctx, claims.Email)
+		if err != nil && !goErrors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+
+		if user != nil {
+			// Linking hands the account to whoever controls the provider account, so both sides
+			// must have proven they own the email
+			if !claims.IsEmailVerified() || !user.EmailVerified {
+				return nil, exception.Conflict(errors.CodeConflict, errors.ErrOIDCEmailNotLinkable).
+					WithDetails("Sign in with your password and verify your email before using this provider")
+			}
+		} else {
+			if user, err = s.registerUser(ctx, claims); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := s.identityRepo.CreateIdentity(ctx, &models.Identity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    email,
+		}); err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return user, nil
+}
+
+// registerUser creates a user for a first-time provider sign-in. The password is random, so the
+// account can only be signed in to through the provider until a password is set with a reset link.
+func (s *oidcService) registerUser(ctx context.Context, claims *dtos.OIDCClaims) (*models.User, error) {
+	username, err := s.availableUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.EncryptToHash(uuid.NewString())
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.authRepo.RegisterAccount(ctx, &models.User{
+		Username: username,
+		Email:    claims.Email,
+		Password: hashedPassword,
+		IsActive: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.IsEmailVerified() {
+		if _, err := s.authRepo.MarkEmailVerified(ctx, user.ID, user.Email); err != nil {
+			return nil, err
+		}
+		user.EmailVerified = true
+	}
+
+	return user, nil
+}
+
+// availableUsername derives a username from the claims, adding a random suffix while it is taken
+func (s *oidcService) availableUsername(ctx context.Context, claims *dtos.OIDCClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = oidcUsernameInvalidChars.ReplaceAllString(base, "")
+	if len(base) > oidcUsernameMaxLength {
+		base = base[:oidcUsernameMaxLength]
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < oidcUsernameAttempts; i++ {
+		conflict, err := s.authRepo.CheckEmailOrUsernameExists(ctx, claims.Email, candidate)
+		if goErrors.Is(err, pgx.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if conflict.ConflictField == "email" {
+			return "", fmt.Errorf("email %s was registered during the sign-in", claims.Email)
+		}
+
+		candidate = fmt.Sprintf("%s_%s", base, strings.ReplaceAll(uuid.NewString(), "-", "")[:6])
+	}
+
+	return "", fmt.Errorf("no available username for %s", base)
+}
+
+func (s *oidcService) consumeState(ctx context.Context, state string) (*dtos.CachedOIDCLogin, *exception.AppError) {
+	opt := cache.CacheKeyOption{
+		Prefix:    string(constants.CachePrefixOIDCState),
+		UniqueKey: state,
+	}
+
+	// The state is single use: reading it also deletes it, so a replayed or
+	// concurrent callback finds nothing
+	var pending dtos.CachedOIDCLogin
+	if appErr := s.cache.TakeObject(ctx, opt, &pending); appErr != nil {
+		if appErr.Code == errors.CodeCacheNotFound {
+			return nil, s.loginFailed("The sign-in has expired or was already completed. Please start again")
+		}
+		return nil, appErr
+	}
+
+	return &pending, nil
+}
+
+// getClient returns the configured provider, fetching its discovery document on first use
+func (s *oidcService) getClient(name string) (*oidcClient, *exception.AppError) {
+	providerConfig, ok := s.config.OIDC.GetProvider(name)
+	if !ok {
+		return nil, exception.NotFound(errors.CodeNotFound, errors.ErrOIDCProviderNotFound)
+	}
+
+	s.clientsMu.Lock()
+	client, ok := s.clients[name]
+	s.clientsMu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	// go-oidc keeps this context for later signing key refreshes, so it must
+	// outlive the request. The HTTP client timeout bounds each fetch instead.
+	discoveryCtx := oidc.ClientContext(context.Background(), &http.Client{Timeout: oidcHTTPTimeout})
+	provider, err := oidc.NewProvider(discoveryCtx, providerConfig.Issuer)
+	if err != nil {
+		s.logger.Error("[OIDC] Discovery failed for "+name, err)
+		return nil, exception.ServiceUnavailable(errors.CodeServiceUnavailable, errors.ErrOIDCLoginFailed).
+			WithDetails("The sign-in provider is unavailable")
+	}
+
+	scopes := providerConfig.GetScopes()
+	if !lo.Contains(scopes, oidc.ScopeOpenID) {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+
+	client = &oidcClient{
+		oauth2: &oauth2.Config{
+			ClientID:     providerConfig.ClientID,
+			ClientSecret: providerConfig.ClientSecret,
+			RedirectURL:  providerConfig.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: providerConfig.ClientID}),
+	}
+
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
+	// Another request may have finished discovery first; keep a single client
+	if existing, ok := s.clients[name]; ok {
+		return existing, nil
+	}
+	s.clients[name] = client
+
+	return client, nil
+}
+
+func (s *oidcService) loginFailed(details string) *exception.AppError {
+	return exception.Unauthorized(errors.CodeOIDCFailed, errors.ErrOIDCLoginFailed).WithDetails(details)
+}
+
+// randomToken returns 32 random bytes, base64url encoded
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,651 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/cache"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+const (
+	testOIDCProvider = "mock"
+	testOIDCClientID = "quiz-client"
+	testOIDCKeyID    = "mock-key"
+)
+
+// mockIssuer is an OpenID provider serving discovery, JWKS and a token endpoint that checks PKCE
+type mockIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	codes  map[string]*issuedCode
+	issued int
+}
+
+type issuedCode struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	issuer := &mockIssuer{key: key, codes: make(map[string]*issuedCode)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/jwks", issuer.jwks)
+	mux.HandleFunc("/token", issuer.token)
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+func (m *mockIssuer) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                m.server.URL,
+		"authorization_endpoint":                m.server.URL + "/authorize",
+		"token_endpoint":                        m.server.URL + "/token",
+		"jwks_uri":                              m.server.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (m *mockIssuer) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testOIDCKeyID,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	m.mu.Lock()
+	issued, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != issued.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, issued.claims)
+	idToken.Header["kid"] = testOIDCKeyID
+	signed, err := idToken.SignedString(m.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "provider-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+// authorize plays the user approving the sign-in at the provider and returns the code it redirects
+// back with. The ID token gets the nonce from the authorization URL unless claims already set one.
+func (m *mockIssuer) authorize(t *testing.T, authorizationURL string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatalf("parse authorization URL: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("authorization URL has no S256 PKCE challenge: %s", authorizationURL)
+	}
+
+	now := time.Now()
+	idClaims := jwt.MapClaims{
+		"iss": m.server.URL,
+		"aud": testOIDCClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	if nonce := query.Get("nonce"); nonce != "" {
+		idClaims["nonce"] = nonce
+	}
+	for k, v := range claims {
+		idClaims[k] = v
+	}
+
+	m.mu.Lock()
+	m.issued++
+	code := fmt.Sprintf("code-%d", m.issued)
+	m.codes[code] = &issuedCode{challenge: query.Get("code_challenge"), claims: idClaims}
+	m.mu.Unlock()
+
+	return code
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// oidcTestEnv is an oidcService wired to the mock issuer and in-memory fakes
+type oidcTestEnv struct {
+	service    *oidcService
+	issuer     *mockIssuer
+	cache      *memoryCache
+	users      *fakeAuthRepo
+	identities *fakeIdentityRepo
+	attempts   *loginAttemptService
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+
+	issuer := newMockIssuer(t)
+	cfg := &config.Config{
+		OIDC: config.OIDCConfig{
+			Providers: []config.OIDCProviderConfig{{
+				Name:        testOIDCProvider,
+				Issuer:      issuer.server.URL,
+				ClientID:    testOIDCClientID,
+				RedirectURL: "http://localhost:3000/auth/callback",
+			}},
+		},
+	}
+
+	memCache := newMemoryCache()
+	users := &fakeAuthRepo{users: make(map[int64]*models.User)}
+	identities := &fakeIdentityRepo{}
+	attempts := &loginAttemptService{cache: memCache, config: cfg}
+
+	return &oidcTestEnv{
+		service: &oidcService{
+			cache:               memCache,
+			config:              cfg,
+			logger:              logger.NewLogger(zap.NewNop()),
+			authRepo:            users,
+			identityRepo:        identities,
+			tokenService:        &fakeTokenService{},
+			loginAttemptService: attempts,
+			mfaService:          &fakeMFAService{},
+			clients:             make(map[string]*oidcClient),
+		},
+		issuer:     issuer,
+		cache:      memCache,
+		users:      users,
+		identities: identities,
+		attempts:   attempts,
+	}
+}
+
+// testContext carries a stand-in transaction, so database.NewTransaction joins it instead of
+// opening one on a pool the fakes do not need
+func testContext() context.Context {
+	return context.WithValue(context.Background(), constants.KEY_CURRENT_TRAN, pgx.Tx(&fakeTx{}))
+}
+
+// start begins a sign-in and has the provider approve it with claims, returning the callback request
+func (e *oidcTestEnv) start(t *testing.T, claims jwt.MapClaims) *dtos.OIDCCallbackRequest {
+	t.Helper()
+	req, _ := e.startLogin(t, claims)
+	return req
+}
+
+// startLogin is start that also returns the authorization URL, so the provider can approve it again
+func (e *oidcTestEnv) startLogin(t *testing.T, claims jwt.MapClaims) (*dtos.OIDCCallbackRequest, string) {
+	t.Helper()
+
+	login, appErr := e.service.StartLogin(testContext(), &dtos.OIDCLoginRequest{Provider: testOIDCProvider})
+	if appErr != nil {
+		t.Fatalf("StartLogin: %v", appErr)
+	}
+
+	return &dtos.OIDCCallbackRequest{
+		Provider: testOIDCProvider,
+		Code:     e.issuer.authorize(t, login.AuthorizationURL, claims),
+		State:    login.State,
+		Device:   dtos.DeviceInfo{IP: "203.0.113.7", UserAgent: "test"},
+	}, login.AuthorizationURL
+}
+
+func assertAppError(t *testing.T, appErr *exception.AppError, code string) {
+	t.Helper()
+	if appErr == nil {
+		t.Fatalf("expected error %s, got success", code)
+	}
+	if appErr.Code != code {
+		t.Fatalf("error code = %s (%s), want %s", appErr.Code, appErr.Error(), code)
+	}
+}
+
+func TestOIDCCompleteLoginCreatesUser(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	req := env.start(t, jwt.MapClaims{
+		"sub":                "subject-1",
+		"email":              "new.user@example.com",
+		"email_verified":     true,
+		"preferred_username": "new.user",
+	})
+
+	res, appErr := env.service.CompleteLogin(testContext(), req)
+	if appErr != nil {
+		t.Fatalf("CompleteLogin: %v", appErr)
+	}
+	if res.TokenResponse == nil {
+		t.Fatal("no token pair returned")
+	}
+
+	user, err := env.users.GetUserByEmailIncludePassword(context.Background(), "new.user@example.com")
+	if err != nil {
+		t.Fatalf("user not created: %v", err)
+	}
+	if !user.EmailVerified {
+		t.Error("provider-verified email not marked verified")
+	}
+	if len(env.identities.identities) != 1 || env.identities.identities[0].UserID != user.ID {
+		t.Errorf("identity not linked to the new user: %+v", env.identities.identities)
+	}
+}
+
+func TestOIDCCompleteLoginRejectsReplayedState(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	claims := jwt.MapClaims{
+		"sub":            "subject-1",
+		"email":          "player@example.com",
+		"email_verified": true,
+	}
+	req, authorizationURL := env.startLogin(t, claims)
+
+	if _, appErr := env.service.CompleteLogin(testContext(), req); appErr != nil {
+		t.Fatalf("first CompleteLogin: %v", appErr)
+	}
+
+	// A fresh code from the provider, so only the used-up state can stop the replay
+	req.Code = env.issuer.authorize(t, authorizationURL, claims)
+	_, appErr := env.service.CompleteLogin(testContext(), req)
+	assertAppError(t, appErr, errors.CodeOIDCFailed)
+	if !strings.Contains(appErr.Details, "already completed") {
+		t.Errorf("replay rejected for another reason: %s", appErr.Error())
+	}
+}
+
+func TestOIDCCompleteLoginRejectsWrongNonce(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	req := env.start(t, jwt.MapClaims{
+		"sub":            "subject-1",
+		"email":          "player@example.com",
+		"email_verified": true,
+		"nonce":          "nonce-from-another-sign-in",
+	})
+
+	_, appErr := env.service.CompleteLogin(testContext(), req)
+	assertAppError(t, appErr, errors.CodeOIDCFailed)
+	if len(env.identities.identities) != 0 {
+		t.Error("identity created for an ID token with the wrong nonce")
+	}
+}
+
+func TestOIDCCompleteLoginRejectsWrongPKCEVerifier(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	req := env.start(t, jwt.MapClaims{
+		"sub":            "subject-1",
+		"email":          "player@example.com",
+		"email_verified": true,
+	})
+
+	// Swap the stored verifier, as if the callback belonged to a sign-in started elsewhere
+	stateKey := cache.CacheKeyOption{Prefix: string(constants.CachePrefixOIDCState), UniqueKey: req.State}
+	var pending dtos.CachedOIDCLogin
+	if appErr := env.cache.GetObject(context.Background(), stateKey, &pending); appErr != nil {
+		t.Fatalf("read pending login: %v", appErr)
+	}
+	pending.CodeVerifier = strings.Repeat("x", 43)
+	stateKey.Value = &pending
+	env.cache.Set(context.Background(), stateKey)
+
+	_, appErr := env.service.CompleteLogin(testContext(), req)
+	assertAppError(t, appErr, errors.CodeOIDCFailed)
+}
+
+func TestOIDCCompleteLoginDoesNotLinkUnverifiedEmail(t *testing.T) {
+	tests := []struct {
+		name              string
+		providerVerified  interface{}
+		localUserVerified bool
+	}{
+		{"provider did not verify the email", false, true},
+		{"provider sent no verification", nil, true},
+		{"local account is not verified", true, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+			existing := env.users.add(&models.User{
+				Username:      "existing",
+				Email:         "existing@example.com",
+				IsActive:      true,
+				EmailVerified: tc.localUserVerified,
+			})
+
+			claims := jwt.MapClaims{"sub": "subject-1", "email": existing.Email}
+			if tc.providerVerified != nil {
+				claims["email_verified"] = tc.providerVerified
+			}
+			req := env.start(t, claims)
+
+			_, appErr := env.service.CompleteLogin(testContext(), req)
+			assertAppError(t, appErr, errors.CodeConflict)
+			if len(env.identities.identities) != 0 {
+				t.Errorf("identity linked to %s: %+v", existing.Email, env.identities.identities)
+			}
+		})
+	}
+}
+
+func TestOIDCCompleteLoginLinksVerifiedEmail(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	existing := env.users.add(&models.User{
+		Username:      "existing",
+		Email:         "existing@example.com",
+		IsActive:      true,
+		EmailVerified: true,
+	})
+
+	req := env.start(t, jwt.MapClaims{
+		"sub":            "subject-1",
+		"email":          existing.Email,
+		"email_verified": "true",
+	})
+
+	if _, appErr := env.service.CompleteLogin(testContext(), req); appErr != nil {
+		t.Fatalf("CompleteLogin: %v", appErr)
+	}
+	if len(env.identities.identities) != 1 || env.identities.identities[0].UserID != existing.ID {
+		t.Errorf("identity not linked to the existing user: %+v", env.identities.identities)
+	}
+}
+
+func TestOIDCCompleteLoginRejectsLockedAccount(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	existing := env.users.add(&models.User{
+		Username:      "existing",
+		Email:         "existing@example.com",
+		IsActive:      true,
+		EmailVerified: true,
+	})
+	env.identities.identities = append(env.identities.identities, &models.Identity{
+		ID:       1,
+		UserID:   existing.ID,
+		Provider: testOIDCProvider,
+		Subject:  "subject-1",
+	})
+
+	lockKey := env.attempts.emailKey(constants.CachePrefixAccountLock, existing.Email)
+	lockKey.Value = true
+	lockKey.TTL = time.Hour
+	env.cache.Set(context.Background(), lockKey)
+
+	req := env.start(t, jwt.MapClaims{
+		"sub":            "subject-1",
+		"email":          existing.Email,
+		"email_verified": true,
+	})
+
+	_, appErr := env.service.CompleteLogin(testContext(), req)
+	assertAppError(t, appErr, errors.CodeAccountLocked)
+}
+
+// memoryCache implements the parts of cache.Cache the OIDC sign-in uses
+type memoryCache struct {
+	cache.Cache
+
+	mu      sync.Mutex
+	values  map[string][]byte
+	expires map[string]time.Time
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: make(map[string][]byte), expires: make(map[string]time.Time)}
+}
+
+func memoryCacheKey(opt cache.CacheKeyOption) string {
+	return strings.Join([]string{opt.Prefix, opt.Module, opt.UniqueKey, opt.Suffix}, ":")
+}
+
+func (c *memoryCache) get(key string) ([]byte, bool) {
+	if expiry, ok := c.expires[key]; ok && time.Now().After(expiry) {
+		delete(c.values, key)
+		delete(c.expires, key)
+	}
+	value, ok := c.values[key]
+	return value, ok
+}
+
+func (c *memoryCache) Set(_ context.Context, opt cache.CacheKeyOption) *exception.AppError {
+	data, err := json.Marshal(opt.Value)
+	if err != nil {
+		return exception.InternalError(errors.CodeInternal, err.Error())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := memoryCacheKey(opt)
+	c.values[key] = data
+	if opt.TTL > 0 {
+		c.expires[key] = time.Now().Add(opt.TTL)
+	}
+	return nil
+}
+
+func (c *memoryCache) GetObject(_ context.Context, opt cache.CacheKeyOption, dest interface{}) *exception.AppError {
+	c.mu.Lock()
+	value, ok := c.get(memoryCacheKey(opt))
+	c.mu.Unlock()
+	if !ok {
+		return exception.NotFound(errors.CodeCacheNotFound, errors.ErrCacheKeyNotFound)
+	}
+	if err := json.Unmarshal(value, dest); err != nil {
+		return exception.BadRequest(errors.CodeBadRequest, err.Error())
+	}
+	return nil
+}
+
+func (c *memoryCache) TakeObject(ctx context.Context, opt cache.CacheKeyOption, dest interface{}) *exception.AppError {
+	c.mu.Lock()
+	key := memoryCacheKey(opt)
+	value, ok := c.get(key)
+	delete(c.values, key)
+	delete(c.expires, key)
+	c.mu.Unlock()
+	if !ok {
+		return exception.NotFound(errors.CodeCacheNotFound, errors.ErrCacheKeyNotFound)
+	}
+	if err := json.Unmarshal(value, dest); err != nil {
+		return exception.BadRequest(errors.CodeBadRequest, err.Error())
+	}
+	return nil
+}
+
+func (c *memoryCache) GetTTL(_ context.Context, opt cache.CacheKeyOption) (time.Duration, *exception.AppError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := memoryCacheKey(opt)
+	if _, ok := c.get(key); !ok {
+		return 0, nil
+	}
+	expiry, ok := c.expires[key]
+	if !ok {
+		return 0, nil
+	}
+	return time.Until(expiry), nil
+}
+
+type fakeTx struct {
+	pgx.Tx
+}
+
+type fakeAuthRepo struct {
+	mu     sync.Mutex
+	users  map[int64]*models.User
+	nextID int64
+}
+
+func (r *fakeAuthRepo) add(user *models.User) *models.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	user.ID = r.nextID
+	r.users[user.ID] = user
+	return user
+}
+
+func (r *fakeAuthRepo) CheckEmailOrUsernameExists(_ context.Context, email, username string) (*dtos.SignUpConflictResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		switch {
+		case strings.EqualFold(user.Email, email):
+			return &dtos.SignUpConflictResult{ID: user.ID, ConflictField: "email"}, nil
+		case user.Username == username:
+			return &dtos.SignUpConflictResult{ID: user.ID, ConflictField: "username"}, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *fakeAuthRepo) RegisterAccount(_ context.Context, user *models.User) (*models.User, error) {
+	created := *user
+	return r.add(&created), nil
+}
+
+func (r *fakeAuthRepo) GetUserByEmailIncludePassword(_ context.Context, email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *fakeAuthRepo) GetUserByIDIncludePassword(_ context.Context, id int64) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *fakeAuthRepo) UpdatePassword(_ context.Context, id int64, hashedPassword string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].Password = hashedPassword
+	return nil
+}
+
+func (r *fakeAuthRepo) MarkEmailVerified(_ context.Context, id int64, email string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || user.EmailVerified || user.Email != email {
+		return false, nil
+	}
+	user.EmailVerified = true
+	return true, nil
+}
+
+type fakeIdentityRepo struct {
+	identities []*models.Identity
+}
+
+func (r *fakeIdentityRepo) GetIdentity(_ context.Context, provider, subject string) (*models.Identity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *fakeIdentityRepo) CreateIdentity(_ context.Context, identity *models.Identity) (*models.Identity, error) {
+	created := *identity
+	created.ID = int64(len(r.identities) + 1)
+	r.identities = append(r.identities, &created)
+	return &created, nil
+}
+
+func (r *fakeIdentityRepo) UpdateIdentityLogin(_ context.Context, id int64, email *string) error {
+	return nil
+}
+
+type fakeTokenService struct {
+	TokenService
+}
+
+func (s *fakeTokenService) GenerateTokenPair(_ context.Context, user *models.User, _ dtos.DeviceInfo) (*dtos.TokenResponse, *exception.AppError) {
+	return &dtos.TokenResponse{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil
+}
+
+type fakeMFAService struct {
+	MFAService
+}
+
+func (s *fakeMFAService) IsEnabled(context.Context, int64) (bool, *exception.AppError) {
+	return false, nil
+}
@@ -3,11 +3,21 @@ package services
 import "github.com/google/wire"
 
 var ServiceProviderSet = wire.NewSet(
+	ProvideAuditService,
 	ProvideTokenService,
+	ProvideAPIKeyService,
+	ProvideMFAService,
+	ProvideLoginAttemptService,
 	ProvideAuthService,
+	ProvideOIDCService,
 	ProvideUserService,
 	ProvideValidationService,
+	ProvideQuizCollaboratorService,
+	ProvideQuizVersionService,
 	ProvideQuizService,
+	ProvideTagService,
+	ProvideMediaService,
 	ProvideQuestionService,
 	ProvideSessionService,
+	ProvideAdminService,
 )
@@ -2,9 +2,16 @@ package services
 
 import (
 	"context"
+	goErrors "errors"
+	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	helpers "github.com/nghiavan0610/btaskee-quiz-service/helpers/quiz"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
@@ -12,6 +19,7 @@ import (
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+	"github.com/nghiavan0610/btaskee-quiz-service/utils"
 )
 
 type (
@@ -20,13 +28,18 @@ type (
 		UpdateQuestion(ctx context.Context, authUser *dtos.UserSession, req *dtos.UpdateQuestionRequest) (*models.Question, *exception.AppError)
 		UpdateQuestionIndex(ctx context.Context, authUser *dtos.UserSession, req *dtos.UpdateQuestionIndexRequest) *exception.AppError
 		DeleteQuestion(ctx context.Context, authUser *dtos.UserSession, req *dtos.DeleteQuestionRequest) *exception.AppError
+		BulkUpsertQuestions(ctx context.Context, authUser *dtos.UserSession, req *dtos.BulkUpsertQuestionsRequest) ([]*models.Question, *exception.AppError)
 	}
 
 	questionService struct {
-		logger            *logger.Logger
-		questionRepo      repositories.QuestionRepository
-		quizRepo          repositories.QuizRepository
-		validationService ValidationService
+		config             *config.Config
+		pool               *pgxpool.Pool
+		logger             *logger.Logger
+		questionRepo       repositories.QuestionRepository
+		quizRepo           repositories.QuizRepository
+		validationService  ValidationService
+		quizVersionService QuizVersionService
+		mediaService       MediaService
 	}
 )
 
@@ -36,17 +49,25 @@ var (
 )
 
 func ProvideQuestionService(
+	config *config.Config,
+	pool *pgxpool.Pool,
 	logger *logger.Logger,
 	questionRepo repositories.QuestionRepository,
 	quizRepo repositories.QuizRepository,
 	validationService ValidationService,
+	quizVersionService QuizVersionService,
+	mediaService MediaService,
 ) QuestionService {
 	questionServiceOnce.Do(func() {
 		questionServiceInstance = &questionService{
-			logger:            logger,
-			questionRepo:      questionRepo,
-			quizRepo:          quizRepo,
-			validationService: validationService,
+			config:             config,
+			pool:               pool,
+			logger:             logger,
+			questionRepo:       questionRepo,
+			quizRepo:           quizRepo,
+			validationService:  validationService,
+			quizVersionService: quizVersionService,
+			mediaService:       mediaService,
 		}
 	})
 	return questionServiceInstance
@@ -55,7 +76,7 @@ func ProvideQuestionService(
 func (s *questionService) CreateQuestion(ctx context.Context, authUser *dtos.UserSession, req *dtos.CreateQuestionRequest) (*models.Question, *exception.AppError) {
 	s.logger.Info("[CREATE QUESTION]", authUser, req)
 
-	_, appErr := s.validationService.ValidateQuizOwnership(ctx, req.QuizID, authUser.UserID, false)
+	quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleEditor, true)
 	if appErr != nil {
 		return nil, appErr
 	}
@@ -66,38 +87,72 @@ func (s *questionService) CreateQuestion(ctx context.Context, authUser *dtos.Use
 			WithDetails("Invalid question answers format")
 	}
 
-	// Get next order index
-	lastIndex, err := s.questionRepo.GetMaxQuestionIndexByQuiz(ctx, req.QuizID)
+	timeLimit, err := helpers.ResolveTimeLimit(req.TimeLimit, &s.config.Question)
 	if err != nil {
-		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+		return nil, exception.BadRequest(errors.CodeValidation, err.Error())
 	}
 
 	question := &models.Question{
-		QuizID:    req.QuizID,
-		Question:  req.Question,
-		Index:     lastIndex + 1,
-		Type:      models.QuestionType(req.Type),
-		Answers:   req.Answers,
-		TimeLimit: models.TimeLimitType(req.TimeLimit),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		QuizID:           req.QuizID,
+		Question:         req.Question,
+		ContentFormat:    req.ContentFormat,
+		Type:             models.QuestionType(req.Type),
+		Answers:          req.Answers,
+		TimeLimit:        timeLimit,
+		PointsMultiplier: helpers.ResolvePointsMultiplier(req.PointsMultiplier),
+		Media:            req.Media,
+		Explanation:      req.Explanation,
+		ReferenceURL:     req.ReferenceURL,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+
+	if appErr := s.mediaService.AttachMedia(ctx, authUser.UserID, question, quiz.Questions...); appErr != nil {
+		return nil, appErr
 	}
 
-	createdQuestion, err := s.questionRepo.CreateQuestion(ctx, question)
+	createdQuestion, err := database.NewTransaction[models.Question](s.pool).Execute(ctx, func(ctx context.Context) (*models.Question, error) {
+		// Get next order index
+		lastIndex, err := s.questionRepo.GetMaxQuestionIndexByQuiz(ctx, req.QuizID)
+		if err != nil {
+			return nil, err
+		}
+		question.Index = lastIndex + 1
+
+		createdQuestion, err := s.questionRepo.CreateQuestion(ctx, question)
+		if err != nil {
+			return nil, err
+		}
+
+		// Update the quiz's total_questions count
+		if err := s.updateQuizTotalQuestions(ctx, req.QuizID); err != nil {
+			return nil, err
+		}
+
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, req.QuizID, &authUser.UserID, models.QuizVersionReasonSave); appErr != nil {
+			return nil, appErr
+		}
+
+		return createdQuestion, nil
+	})
 	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
 		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
-	// Update the quiz's total_questions count
-	s.updateQuizTotalQuestions(ctx, req.QuizID)
-
 	return createdQuestion, nil
 }
 
 func (s *questionService) UpdateQuestion(ctx context.Context, authUser *dtos.UserSession, req *dtos.UpdateQuestionRequest) (*models.Question, *exception.AppError) {
 	s.logger.Info("[UPDATE QUESTION]", authUser, req)
 
-	s.validationService.ValidateQuestionOwnership(ctx, req.QuestionID, authUser.UserID)
+	existingQuestion, _, appErr := s.validationService.ValidateQuestionAccess(ctx, req.QuestionID, authUser.UserID, models.QuizRoleEditor)
+	if appErr != nil {
+		return nil, appErr
+	}
 
 	// Validate question answers based on type
 	if err := transformers.ValidateAnswersFormat(req.Answers, req.Type); err != nil {
@@ -105,17 +160,46 @@ func (s *questionService) UpdateQuestion(ctx context.Context, authUser *dtos.Use
 			WithDetails("Invalid question answers format")
 	}
 
+	timeLimit, err := helpers.ResolveTimeLimit(req.TimeLimit, &s.config.Question)
+	if err != nil {
+		return nil, exception.BadRequest(errors.CodeValidation, err.Error())
+	}
+
 	question := &models.Question{
-		ID:        req.QuestionID,
-		Question:  req.Question,
-		Type:      models.QuestionType(req.Type),
-		Answers:   req.Answers,
-		TimeLimit: models.TimeLimitType(req.TimeLimit),
-		UpdatedAt: time.Now(),
+		ID:               req.QuestionID,
+		Question:         req.Question,
+		ContentFormat:    req.ContentFormat,
+		Type:             models.QuestionType(req.Type),
+		Answers:          req.Answers,
+		TimeLimit:        timeLimit,
+		PointsMultiplier: helpers.ResolvePointsMultiplier(req.PointsMultiplier),
+		Media:            req.Media,
+		Explanation:      req.Explanation,
+		ReferenceURL:     req.ReferenceURL,
+		UpdatedAt:        time.Now(),
 	}
 
-	updatedQuestion, err := s.questionRepo.UpdateQuestion(ctx, question)
+	if appErr := s.mediaService.AttachMedia(ctx, authUser.UserID, question, *existingQuestion); appErr != nil {
+		return nil, appErr
+	}
+
+	updatedQuestion, err := database.NewTransaction[models.Question](s.pool).Execute(ctx, func(ctx context.Context) (*models.Question, error) {
+		updatedQuestion, err := s.questionRepo.UpdateQuestion(ctx, question)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, existingQuestion.QuizID, &authUser.UserID, models.QuizVersionReasonSave); appErr != nil {
+			return nil, appErr
+		}
+
+		return updatedQuestion, nil
+	})
 	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
 		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
@@ -129,11 +213,6 @@ func (s *questionService) UpdateQuestionIndex(ctx context.Context, authUser *dto
 		return exception.BadRequest(errors.CodeBadRequest, "No question indexes to update")
 	}
 
-	_, _, appErr := s.validationService.ValidateQuestionOwnership(ctx, req.Indexes[0].QuestionID, authUser.UserID)
-	if appErr != nil {
-		return appErr
-	}
-
 	questionIDs := make([]int64, len(req.Indexes))
 	indexes := make([]int32, len(req.Indexes))
 
@@ -142,7 +221,51 @@ func (s *questionService) UpdateQuestionIndex(ctx context.Context, authUser *dto
 		indexes[i] = indexPayload.Index
 	}
 
-	if err := s.questionRepo.UpdateQuestionIndexesBatch(ctx, questionIDs, indexes); err != nil {
+	_, err := database.NewTransaction[struct{}](s.pool).Execute(ctx, func(ctx context.Context) (*struct{}, error) {
+		if err := s.quizRepo.LockQuiz(ctx, req.QuizID); err != nil {
+			return nil, err
+		}
+
+		quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleEditor, true)
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		// Every question must belong to the quiz that was checked, or an editor of one quiz could
+		// reorder another's
+		existingIDs := make(map[int64]bool, len(quiz.Questions))
+		for _, question := range quiz.Questions {
+			existingIDs[question.ID] = true
+		}
+		seenIDs := make(map[int64]bool, len(questionIDs))
+		for _, questionID := range questionIDs {
+			if !existingIDs[questionID] {
+				return nil, exception.BadRequest(errors.CodeValidation, errors.ErrQuestionNotInQuiz).
+					WithDetails(fmt.Sprintf("Question %d does not belong to quiz %d", questionID, req.QuizID))
+			}
+			if seenIDs[questionID] {
+				return nil, exception.BadRequest(errors.CodeValidation, errors.ErrDuplicateQuestionInList).
+					WithDetails(fmt.Sprintf("Question %d appears more than once", questionID))
+			}
+			seenIDs[questionID] = true
+		}
+
+		if err := s.questionRepo.UpdateQuestionIndexesBatch(ctx, questionIDs, indexes); err != nil {
+			return nil, err
+		}
+
+		// The order is part of a version, so reordering is recorded too
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, req.QuizID, &authUser.UserID, models.QuizVersionReasonSave); appErr != nil {
+			return nil, appErr
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return appErr
+		}
 		return exception.InternalError(errors.CodeDBError, err.Error()).
 			WithDetails("Failed to update question indexes")
 	}
@@ -153,22 +276,170 @@ func (s *questionService) UpdateQuestionIndex(ctx context.Context, authUser *dto
 func (s *questionService) DeleteQuestion(ctx context.Context, authUser *dtos.UserSession, req *dtos.DeleteQuestionRequest) *exception.AppError {
 	s.logger.Info("[DELETE QUESTION]", authUser, req)
 
-	question, _, appErr := s.validationService.ValidateQuestionOwnership(ctx, req.QuestionID, authUser.UserID)
+	question, _, appErr := s.validationService.ValidateQuestionAccess(ctx, req.QuestionID, authUser.UserID, models.QuizRoleEditor)
 	if appErr != nil {
 		return appErr
 	}
 
-	err := s.questionRepo.DeleteQuestion(ctx, req.QuestionID)
+	_, err := database.NewTransaction[struct{}](s.pool).Execute(ctx, func(ctx context.Context) (*struct{}, error) {
+		if err := s.questionRepo.DeleteQuestion(ctx, req.QuestionID); err != nil {
+			return nil, err
+		}
+
+		// Update the quiz's total_questions count
+		if err := s.updateQuizTotalQuestions(ctx, question.QuizID); err != nil {
+			return nil, err
+		}
+
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, question.QuizID, &authUser.UserID, models.QuizVersionReasonSave); appErr != nil {
+			return nil, appErr
+		}
+
+		return nil, nil
+	})
 	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return appErr
+		}
 		return exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
-	// Update the quiz's total_questions count
-	s.updateQuizTotalQuestions(ctx, question.QuizID)
-
 	return nil
 }
 
+func (s *questionService) BulkUpsertQuestions(ctx context.Context, authUser *dtos.UserSession, req *dtos.BulkUpsertQuestionsRequest) ([]*models.Question, *exception.AppError) {
+	s.logger.Info("[BULK UPSERT QUESTIONS]", authUser, req.QuizID, len(req.Questions))
+
+	result, err := database.NewTransaction[[]*models.Question](s.pool).Execute(ctx, func(ctx context.Context) (*[]*models.Question, error) {
+		// Access and the current question list are read under the quiz lock, so a concurrent save or a
+		// revoked collaborator cannot slip in between the check and the writes
+		if err := s.quizRepo.LockQuiz(ctx, req.QuizID); err != nil {
+			return nil, err
+		}
+
+		quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleEditor, true)
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		existingIDs := make(map[int64]bool, len(quiz.Questions))
+		for _, question := range quiz.Questions {
+			existingIDs[question.ID] = true
+		}
+
+		// Validate every item up front so nothing is written unless the whole list is valid
+		itemErrors := make([]dtos.BulkQuestionError, 0)
+		seenIDs := make(map[int64]bool, len(req.Questions))
+		questions := make([]*models.Question, len(req.Questions))
+		for i := range req.Questions {
+			item := &req.Questions[i]
+
+			if err := utils.ValidateStruct(item); err != nil {
+				itemErrors = append(itemErrors, dtos.BulkQuestionError{Index: i, Errors: utils.ParseValidationError(err)})
+				continue
+			}
+			if err := transformers.ValidateAnswersFormat(item.Answers, item.Type); err != nil {
+				itemErrors = append(itemErrors, dtos.BulkQuestionError{Index: i, Errors: map[string]string{"Answers": err.Error()}})
+				continue
+			}
+			timeLimit, err := helpers.ResolveTimeLimit(item.TimeLimit, &s.config.Question)
+			if err != nil {
+				itemErrors = append(itemErrors, dtos.BulkQuestionError{Index: i, Errors: map[string]string{"TimeLimit": err.Error()}})
+				continue
+			}
+			if item.QuestionID != nil {
+				if !existingIDs[*item.QuestionID] {
+					itemErrors = append(itemErrors, dtos.BulkQuestionError{Index: i, Errors: map[string]string{"QuestionID": errors.ErrQuestionNotInQuiz}})
+					continue
+				}
+				if seenIDs[*item.QuestionID] {
+					itemErrors = append(itemErrors, dtos.BulkQuestionError{Index: i, Errors: map[string]string{"QuestionID": errors.ErrDuplicateQuestionInList}})
+					continue
+				}
+				seenIDs[*item.QuestionID] = true
+			}
+
+			questions[i] = &models.Question{
+				QuizID:           req.QuizID,
+				Question:         item.Question,
+				ContentFormat:    item.ContentFormat,
+				Type:             item.Type,
+				Answers:          item.Answers,
+				TimeLimit:        timeLimit,
+				PointsMultiplier: helpers.ResolvePointsMultiplier(item.PointsMultiplier),
+				Media:            item.Media,
+				Explanation:      item.Explanation,
+				ReferenceURL:     item.ReferenceURL,
+				Index:            int32(i + 1),
+			}
+			if appErr := s.mediaService.AttachMedia(ctx, authUser.UserID, questions[i], quiz.Questions...); appErr != nil {
+				if appErr.Status >= http.StatusInternalServerError {
+					return nil, appErr
+				}
+				itemErrors = append(itemErrors, dtos.BulkQuestionError{Index: i, Errors: map[string]string{"Media": appErr.Details}})
+			}
+		}
+
+		if len(itemErrors) > 0 {
+			return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidBulkQuestions).
+				WithMetadata("item_errors", itemErrors)
+		}
+
+		keepIDs := make([]int64, 0, len(seenIDs))
+		for id := range seenIDs {
+			keepIDs = append(keepIDs, id)
+		}
+		if err := s.questionRepo.DeleteQuestionsByQuizExcept(ctx, req.QuizID, keepIDs); err != nil {
+			return nil, err
+		}
+
+		for i, item := range req.Questions {
+			question := questions[i]
+
+			if item.QuestionID == nil {
+				created, err := s.questionRepo.CreateQuestion(ctx, question)
+				if err != nil {
+					return nil, err
+				}
+				questions[i] = created
+				continue
+			}
+
+			question.ID = *item.QuestionID
+			updated, err := s.questionRepo.UpdateQuestion(ctx, question)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.questionRepo.UpdateQuestionIndex(ctx, question.ID, question.Index); err != nil {
+				return nil, err
+			}
+			updated.Index = question.Index
+			questions[i] = updated
+		}
+
+		if err := s.quizRepo.UpdateTotalQuestions(ctx, req.QuizID, int32(len(questions))); err != nil {
+			return nil, err
+		}
+
+		// A bulk save replaces the whole question list, so it is recorded as a version
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, req.QuizID, &authUser.UserID, models.QuizVersionReasonSave); appErr != nil {
+			return nil, appErr
+		}
+
+		return &questions, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return *result, nil
+}
+
 func (s *questionService) updateQuizTotalQuestions(ctx context.Context, quizID int64) error {
 	totalQuestions, err := s.questionRepo.CountQuestionsByQuiz(ctx, quizID)
 	if err != nil {
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+type (
+	QuizCollaboratorService interface {
+		ListCollaborators(ctx context.Context, authUser *dtos.UserSession, req *dtos.ListQuizCollaboratorsRequest) ([]*models.QuizCollaborator, *exception.AppError)
+		// InviteCollaborator shares a quiz with a user found by username or email. Inviting someone again changes their role.
+		InviteCollaborator(ctx context.Context, authUser *dtos.UserSession, req *dtos.InviteQuizCollaboratorRequest) (*models.QuizCollaborator, *exception.AppError)
+		UpdateCollaborator(ctx context.Context, authUser *dtos.UserSession, req *dtos.UpdateQuizCollaboratorRequest) (*models.QuizCollaborator, *exception.AppError)
+		// RemoveCollaborator is open to the owner, and to collaborators removing themselves
+		RemoveCollaborator(ctx context.Context, authUser *dtos.UserSession, req *dtos.RemoveQuizCollaboratorRequest) *exception.AppError
+		// TransferOwnership hands the quiz to a collaborator. The previous owner stays on as an editor.
+		TransferOwnership(ctx context.Context, authUser *dtos.UserSession, req *dtos.TransferQuizOwnershipRequest) (*models.Quiz, *exception.AppError)
+	}
+
+	quizCollaboratorService struct {
+		pool                 *pgxpool.Pool
+		logger               *logger.Logger
+		quizCollaboratorRepo repositories.QuizCollaboratorRepository
+		quizRepo             repositories.QuizRepository
+		userRepo             repositories.UserRepository
+		validationService    ValidationService
+	}
+)
+
+var (
+	quizCollaboratorServiceOnce     sync.Once
+	quizCollaboratorServiceInstance QuizCollaboratorService
+)
+
+func ProvideQuizCollaboratorService(
+	pool *pgxpool.Pool,
+	logger *logger.Logger,
+	quizCollaboratorRepo repositories.QuizCollaboratorRepository,
+	quizRepo repositories.QuizRepository,
+	userRepo repositories.UserRepository,
+	validationService ValidationService,
+) QuizCollaboratorService {
+	quizCollaboratorServiceOnce.Do(func() {
+		quizCollaboratorServiceInstance = &quizCollaboratorService{
+			pool:                 pool,
+			logger:               logger,
+			quizCollaboratorRepo: quizCollaboratorRepo,
+			quizRepo:             quizRepo,
+			userRepo:             userRepo,
+			validationService:    validationService,
+		}
+	})
+	return quizCollaboratorServiceInstance
+}
+
+func (s *quizCollaboratorService) ListCollaborators(ctx context.Context, authUser *dtos.UserSession, req *dtos.ListQuizCollaboratorsRequest) ([]*models.QuizCollaborator, *exception.AppError) {
+	s.logger.Info("[LIST QUIZ COLLABORATORS]", authUser, req)
+
+	if _, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleViewer, false); appErr != nil {
+		return nil, appErr
+	}
+
+	collaborators, err := s.quizCollaboratorRepo.ListCollaborators(ctx, req.QuizID)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return collaborators, nil
+}
+
+func (s *quizCollaboratorService) InviteCollaborator(ctx context.Context, authUser *dtos.UserSession, req *dtos.InviteQuizCollaboratorRequest) (*models.QuizCollaborator, *exception.AppError) {
+	s.logger.Info("[INVITE QUIZ COLLABORATOR]", authUser, req)
+
+	quiz, appErr := s.validationService.ValidateQuizOwnership(ctx, req.QuizID, authUser.UserID, false)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	user, err := s.userRepo.FindUserByUsernameOrEmail(ctx, req.Identifier)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrUserNotFound)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	if user.ID == quiz.OwnerID {
+		return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrInvalidCollaborator).
+			WithDetails("The quiz owner cannot be added as a collaborator")
+	}
+	if !user.IsActive {
+		return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrUserDisabled)
+	}
+
+	collaborator, err := s.quizCollaboratorRepo.UpsertCollaborator(ctx, &models.QuizCollaborator{
+		QuizID:    quiz.ID,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		AvatarURL: user.AvatarURL,
+		Role:      req.Role,
+		InvitedBy: &authUser.UserID,
+	})
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return collaborator, nil
+}
+
+func (s *quizCollaboratorService) UpdateCollaborator(ctx context.Context, authUser *dtos.UserSession, req *dtos.UpdateQuizCollaboratorRequest) (*models.QuizCollaborator, *exception.AppError) {
+	s.logger.Info("[UPDATE QUIZ COLLABORATOR]", authUser, req)
+
+	quiz, appErr := s.validationService.ValidateQuizOwnership(ctx, req.QuizID, authUser.UserID, false)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	collaborator, appErr := s.getCollaborator(ctx, quiz.ID, req.UserID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	collaborator.Role = req.Role
+	updatedCollaborator, err := s.quizCollaboratorRepo.UpsertCollaborator(ctx, collaborator)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return updatedCollaborator, nil
+}
+
+func (s *quizCollaboratorService) RemoveCollaborator(ctx context.Context, authUser *dtos.UserSession, req *dtos.RemoveQuizCollaboratorRequest) *exception.AppError {
+	s.logger.Info("[REMOVE QUIZ COLLABORATOR]", authUser, req)
+
+	quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleViewer, false)
+	if appErr != nil {
+		return appErr
+	}
+
+	if quiz.Role != models.QuizRoleOwner && req.UserID != authUser.UserID {
+		return exception.Forbidden(errors.CodeUnauthorized, errors.ErrUnauthorizedAccess).
+			WithDetails("Only the quiz owner can remove other collaborators")
+	}
+
+	removed, err := s.quizCollaboratorRepo.DeleteCollaborator(ctx, quiz.ID, req.UserID)
+	if err != nil {
+		return exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	if !removed {
+		return exception.NotFound(errors.CodeNotFound, errors.ErrCollaboratorNotFound)
+	}
+
+	return nil
+}
+
+func (s *quizCollaboratorService) TransferOwnership(ctx context.Context, authUser *dtos.UserSession, req *dtos.TransferQuizOwnershipRequest) (*models.Quiz, *exception.AppError) {
+	s.logger.Info("[TRANSFER QUIZ OWNERSHIP]", authUser, req)
+
+	quiz, appErr := s.validationService.ValidateQuizOwnership(ctx, req.QuizID, authUser.UserID, false)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if _, appErr := s.getCollaborator(ctx, quiz.ID, req.UserID); appErr != nil {
+		return nil, appErr
+	}
+
+	transferredQuiz, err := database.NewTransaction[models.Quiz](s.pool).Execute(ctx, func(ctx context.Context) (*models.Quiz, error) {
+		if _, err := s.quizCollaboratorRepo.DeleteCollaborator(ctx, quiz.ID, req.UserID); err != nil {
+			return nil, err
+		}
+
+		if err := s.quizRepo.UpdateOwner(ctx, quiz.ID, req.UserID); err != nil {
+			return nil, err
+		}
+
+		if _, err := s.quizCollaboratorRepo.UpsertCollaborator(ctx, &models.QuizCollaborator{
+			QuizID:    quiz.ID,
+			UserID:    authUser.UserID,
+			Role:      models.QuizRoleEditor,
+			InvitedBy: &req.UserID,
+		}); err != nil {
+			return nil, err
+		}
+
+		return s.quizRepo.GetQuizDetail(ctx, quiz.ID, false)
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	transferredQuiz.Role = models.QuizRoleEditor
+
+	return transferredQuiz, nil
+}
+
+func (s *quizCollaboratorService) getCollaborator(ctx context.Context, quizID int64, userID int64) (*models.QuizCollaborator, *exception.AppError) {
+	collaborators, err := s.quizCollaboratorRepo.ListCollaborators(ctx, quizID)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	for _, collaborator := range collaborators {
+		if collaborator.UserID == userID {
+			return collaborator, nil
+		}
+	}
+
+	return nil, exception.NotFound(errors.CodeNotFound, errors.ErrCollaboratorNotFound)
+}
@@ -2,20 +2,31 @@ package services
 
 import (
 	"context"
+	goErrors "errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	mediaHelpers "github.com/nghiavan0610/btaskee-quiz-service/helpers/media"
 	helpers "github.com/nghiavan0610/btaskee-quiz-service/helpers/quiz"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/response"
 	"github.com/nghiavan0610/btaskee-quiz-service/utils"
+	"github.com/samber/lo"
 )
 
 type (
@@ -28,6 +39,17 @@ type (
 		GetMyQuizList(ctx context.Context, authUser *dtos.UserSession, req *dtos.GetMyQuizListRequest) (*models.MyQuizListResponse, *exception.AppError)
 		GetQuizList(ctx context.Context, req *dtos.GetQuizListRequest) (*dtos.GetQuizListResponse, *exception.AppError)
 		GetQuizDetail(ctx context.Context, authUser *dtos.UserSession, req *dtos.GetQuizDetailRequest) (*models.Quiz, *exception.AppError)
+		GetQuizBySlug(ctx context.Context, authUser *dtos.UserSession, req *dtos.GetQuizBySlugRequest) (*models.Quiz, *exception.AppError)
+
+		ForkQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.ForkQuizRequest) (*models.Quiz, *exception.AppError)
+
+		// Publishing
+		LintQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.LintQuizRequest) (*models.QuizLintReport, *exception.AppError)
+		PublishQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.PublishQuizRequest) (*dtos.PublishQuizResponse, *exception.AppError)
+
+		// Import / export
+		ExportQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.ExportQuizRequest) (*dtos.ExportQuizResponse, *exception.AppError)
+		ImportQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.ImportQuizRequest) (*models.Quiz, *exception.AppError)
 
 		// GetUserQuizzes(ctx context.Context, authUser *dtos.UserSession) ([]*models.Quiz, *exception.AppError)
 
@@ -63,10 +85,14 @@ type (
 	}
 
 	quizService struct {
-		logger            *logger.Logger
-		quizRepo          repositories.QuizRepository
-		questionRepo      repositories.QuestionRepository
-		validationService ValidationService
+		config               *config.Config
+		pool                 *pgxpool.Pool
+		logger               *logger.Logger
+		quizRepo             repositories.QuizRepository
+		questionRepo         repositories.QuestionRepository
+		quizCollaboratorRepo repositories.QuizCollaboratorRepository
+		validationService    ValidationService
+		quizVersionService   QuizVersionService
 	}
 )
 
@@ -84,17 +110,25 @@ var (
 )
 
 func ProvideQuizService(
+	config *config.Config,
+	pool *pgxpool.Pool,
 	logger *logger.Logger,
 	quizRepo repositories.QuizRepository,
 	questionRepo repositories.QuestionRepository,
+	quizCollaboratorRepo repositories.QuizCollaboratorRepository,
 	validationService ValidationService,
+	quizVersionService QuizVersionService,
 ) QuizService {
 	quizServiceOnce.Do(func() {
 		quizServiceInstance = &quizService{
-			logger:            logger,
-			quizRepo:          quizRepo,
-			questionRepo:      questionRepo,
-			validationService: validationService,
+			config:               config,
+			pool:                 pool,
+			logger:               logger,
+			quizRepo:             quizRepo,
+			questionRepo:         questionRepo,
+			quizCollaboratorRepo: quizCollaboratorRepo,
+			validationService:    validationService,
+			quizVersionService:   quizVersionService,
 		}
 	})
 	return quizServiceInstance
@@ -103,17 +137,38 @@ func ProvideQuizService(
 func (s *quizService) CreateQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.CreateQuizRequest) (*models.Quiz, *exception.AppError) {
 	s.logger.Info("[CREATE QUIZ]", authUser, req)
 
-	quiz := &models.Quiz{
-		Title:           req.Title,
-		Description:     req.Description,
-		Visibility:      models.QuizVisibilityPrivate,
-		OwnerID:         authUser.UserID,
-		MaxParticipants: &req.MaxParticipants,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
-	}
-	createdQuiz, err := s.quizRepo.CreateQuiz(ctx, quiz)
+	createdQuiz, err := database.NewTransaction[models.Quiz](s.pool).Execute(ctx, func(ctx context.Context) (*models.Quiz, error) {
+		slug, err := s.quizRepo.GenerateSlug(ctx, req.Title)
+		if err != nil {
+			return nil, exception.InternalError(errors.CodeInternal, err.Error())
+		}
+
+		quiz, err := s.quizRepo.CreateQuiz(ctx, &models.Quiz{
+			Title:           req.Title,
+			Description:     req.Description,
+			Visibility:      models.QuizVisibilityPrivate,
+			Slug:            &slug,
+			OwnerID:         authUser.UserID,
+			MaxParticipants: &req.MaxParticipants,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Every quiz starts its history on creation, as forks and imports do
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, quiz.ID, &authUser.UserID, models.QuizVersionReasonSave); appErr != nil {
+			return nil, appErr
+		}
+
+		return quiz, nil
+	})
 	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
 		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
@@ -123,14 +178,47 @@ func (s *quizService) CreateQuiz(ctx context.Context, authUser *dtos.UserSession
 func (s *quizService) UpdateQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.UpdateQuizRequest) (*models.Quiz, *exception.AppError) {
 	s.logger.Info("[UPDATE QUIZ]", authUser, req)
 
-	quiz, err := s.validationService.ValidateQuizOwnership(ctx, req.QuizID, authUser.UserID, false)
-	if err != nil {
-		return nil, err
+	// Questions are only needed to lint a quiz that is about to be published
+	publishing := req.Visibility == models.QuizVisibilityPublished
+	quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleEditor, publishing)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// Editors change the content, who can see the quiz and where it lives stay with the owner
+	if quiz.Role != models.QuizRoleOwner {
+		slugChanged := req.Slug != nil && helpers.NormalizeQuizSlug(*req.Slug) != lo.FromPtr(quiz.Slug)
+		if req.Visibility != quiz.Visibility || slugChanged {
+			return nil, exception.Forbidden(errors.CodeUnauthorized, errors.ErrUnauthorizedAccess).
+				WithDetails("Only the quiz owner can change its visibility or slug")
+		}
+	}
+
+	// Slugs stay stable across renames, they only change when the owner picks a new one
+	var newSlug *string
+	if req.Slug != nil {
+		slug := helpers.NormalizeQuizSlug(*req.Slug)
+		if slug != lo.FromPtr(quiz.Slug) {
+			slugQuizID, err := s.quizRepo.ResolveSlug(ctx, slug)
+			if err != nil && err != pgx.ErrNoRows {
+				return nil, exception.InternalError(errors.CodeDBError, err.Error())
+			}
+			if err == nil && slugQuizID != quiz.ID {
+				return nil, exception.Conflict(errors.CodeConflict, errors.ErrQuizSlugTaken)
+			}
+			newSlug = &slug
+		}
 	}
 
 	quiz.Title = req.Title
 	quiz.Description = req.Description
 
+	if publishing && quiz.Visibility != models.QuizVisibilityPublished {
+		if _, appErr := s.checkPublishable(quiz); appErr != nil {
+			return nil, appErr
+		}
+	}
+
 	if req.Visibility == models.QuizVisibilityPublished && quiz.Visibility != models.QuizVisibilityPublished {
 		now := time.Now()
 		quiz.PublishedAt = &now
@@ -138,25 +226,122 @@ func (s *quizService) UpdateQuiz(ctx context.Context, authUser *dtos.UserSession
 		quiz.PublishedAt = nil
 	}
 
+	versionReason := models.QuizVersionReasonSave
+	if req.Visibility == models.QuizVisibilityPublished && quiz.Visibility != models.QuizVisibilityPublished {
+		versionReason = models.QuizVersionReasonPublish
+	}
+
 	quiz.Visibility = req.Visibility
 	quiz.MaxParticipants = &req.MaxParticipants
 
-	updatedQuiz, appErr := s.quizRepo.UpdateQuiz(ctx, quiz)
-	if appErr != nil {
-		if appErr == pgx.ErrNoRows {
+	// Every save is recorded as an immutable version alongside the update
+	updatedQuiz, err := database.NewTransaction[models.Quiz](s.pool).Execute(ctx, func(ctx context.Context) (*models.Quiz, error) {
+		updatedQuiz, err := s.quizRepo.UpdateQuiz(ctx, quiz)
+		if err != nil {
+			return nil, err
+		}
+
+		if newSlug != nil {
+			if err := s.quizRepo.UpdateSlug(ctx, quiz.ID, quiz.Slug, *newSlug); err != nil {
+				return nil, err
+			}
+			updatedQuiz.Slug = newSlug
+		}
+
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, quiz.ID, &authUser.UserID, versionReason); appErr != nil {
+			return nil, appErr
+		}
+
+		return updatedQuiz, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		if err == pgx.ErrNoRows {
 			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
 		}
-		return nil, exception.InternalError(errors.CodeDBError, appErr.Error())
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
 	return updatedQuiz, nil
 }
 
+func (s *quizService) LintQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.LintQuizRequest) (*models.QuizLintReport, *exception.AppError) {
+	s.logger.Info("[LINT QUIZ]", authUser, req)
+
+	quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleViewer, true)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return helpers.LintQuiz(quiz, s.config.Quiz.GetMinPublishQuestions()), nil
+}
+
+func (s *quizService) PublishQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.PublishQuizRequest) (*dtos.PublishQuizResponse, *exception.AppError) {
+	s.logger.Info("[PUBLISH QUIZ]", authUser, req)
+
+	quiz, appErr := s.validationService.ValidateQuizOwnership(ctx, req.QuizID, authUser.UserID, true)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	report, appErr := s.checkPublishable(quiz)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// Publishing again keeps the original publish date
+	if quiz.Visibility != models.QuizVisibilityPublished {
+		now := time.Now()
+		quiz.Visibility = models.QuizVisibilityPublished
+		quiz.PublishedAt = &now
+	}
+
+	publishedQuiz, err := database.NewTransaction[models.Quiz](s.pool).Execute(ctx, func(ctx context.Context) (*models.Quiz, error) {
+		publishedQuiz, err := s.quizRepo.UpdateQuiz(ctx, quiz)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, quiz.ID, &authUser.UserID, models.QuizVersionReasonPublish); appErr != nil {
+			return nil, appErr
+		}
+
+		return publishedQuiz, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	publishedQuiz.Role = quiz.Role
+
+	return &dtos.PublishQuizResponse{
+		Quiz: publishedQuiz,
+		Lint: report,
+	}, nil
+}
+
+// checkPublishable lints a quiz loaded with its questions and refuses it when the linter finds errors
+func (s *quizService) checkPublishable(quiz *models.Quiz) (*models.QuizLintReport, *exception.AppError) {
+	report := helpers.LintQuiz(quiz, s.config.Quiz.GetMinPublishQuestions())
+	if !report.Publishable {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrQuizNotPublishable).
+			WithMetadata("lint", report)
+	}
+
+	return report, nil
+}
+
 func (s *quizService) GetMyQuizDetail(ctx context.Context, authUser *dtos.UserSession, req *dtos.GetMyQuizDetailRequest) (*models.Quiz, *exception.AppError) {
 	s.logger.Info("[GET MY QUIZ DETAIL]", req)
 
-	// Validate ownership and get quiz with questions
-	quiz, appErr := s.validationService.ValidateQuizOwnership(ctx, req.QuizID, authUser.UserID, true)
+	// Validate access and get quiz with questions
+	quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleViewer, true)
 	if appErr != nil {
 		return nil, appErr
 	}
@@ -168,6 +353,7 @@ func (s *quizService) GetMyQuizList(ctx context.Context, authUser *dtos.UserSess
 	s.logger.Info("[GET MY QUIZ LIST]", authUser, req)
 
 	offset, limit := utils.CalculateOffset(req.Page, req.Limit)
+	tag := newTagFilter(req.Tag)
 
 	queries := []func(context.Context) (any, error){
 		func(ctx context.Context) (any, error) {
@@ -176,6 +362,7 @@ func (s *quizService) GetMyQuizList(ctx context.Context, authUser *dtos.UserSess
 				authUser.UserID,
 				req.Query,
 				req.Visibility,
+				tag,
 				limit,
 				offset,
 			)
@@ -186,6 +373,7 @@ func (s *quizService) GetMyQuizList(ctx context.Context, authUser *dtos.UserSess
 				authUser.UserID,
 				req.Query,
 				req.Visibility,
+				tag,
 			)
 		},
 	}
@@ -198,6 +386,21 @@ func (s *quizService) GetMyQuizList(ctx context.Context, authUser *dtos.UserSess
 	quizzes := results[0].([]*models.Quiz)
 	totalItems := results[1].(int64)
 
+	// Shared quizzes are listed alongside owned ones, each with the caller's role on it
+	sharedQuizIDs := lo.FilterMap(quizzes, func(quiz *models.Quiz, _ int) (int64, bool) {
+		return quiz.ID, quiz.OwnerID != authUser.UserID
+	})
+	roles, err := s.quizCollaboratorRepo.GetCollaboratorRolesByUser(ctx, authUser.UserID, sharedQuizIDs)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+	for _, quiz := range quizzes {
+		quiz.Role = models.QuizRoleOwner
+		if quiz.OwnerID != authUser.UserID {
+			quiz.Role = roles[quiz.ID]
+		}
+	}
+
 	return &models.MyQuizListResponse{
 		Quizzes: quizzes,
 		Pagination: response.OffsetPagination{
@@ -230,7 +433,17 @@ func (s *quizService) GetQuizList(ctx context.Context, req *dtos.GetQuizListRequ
 	limit := utils.CalculateLimit(req.Limit)
 	sortBy := helpers.ValidateQuizSortBy(req.SortBy)
 
-	quizzes, err := s.quizRepo.GetPublicQuizList(ctx, req.Query, req.Cursor, limit+1, &sortBy)
+	filter, appErr := newPublicQuizFilter(req)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// Relevance only means something for a search
+	if sortBy == "relevance" && filter.Query == nil {
+		sortBy = "time_newest"
+	}
+
+	quizzes, err := s.quizRepo.GetPublicQuizList(ctx, filter, req.Cursor, limit+1, &sortBy)
 	if err != nil {
 		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
@@ -266,10 +479,66 @@ func (s *quizService) GetQuizList(ctx context.Context, req *dtos.GetQuizListRequ
 	}, nil
 }
 
+func newPublicQuizFilter(req *dtos.GetQuizListRequest) (*models.PublicQuizFilter, *exception.AppError) {
+	filter := &models.PublicQuizFilter{
+		OwnerID:      req.OwnerID,
+		MinQuestions: req.MinQuestions,
+		MaxQuestions: req.MaxQuestions,
+		Tag:          newTagFilter(req.Tag),
+	}
+
+	if query := strings.TrimSpace(lo.FromPtr(req.Query)); query != "" {
+		filter.Query = &query
+	}
+
+	if filter.MinQuestions != nil && filter.MaxQuestions != nil && *filter.MinQuestions > *filter.MaxQuestions {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizListFilter).
+			WithDetails("min_questions cannot be greater than max_questions")
+	}
+
+	if req.CreatedFrom != nil {
+		createdFrom, err := time.Parse(time.DateOnly, *req.CreatedFrom)
+		if err != nil {
+			return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizListFilter).WithDetails(err.Error())
+		}
+		filter.CreatedFrom = &createdFrom
+	}
+
+	if req.CreatedTo != nil {
+		createdTo, err := time.Parse(time.DateOnly, *req.CreatedTo)
+		if err != nil {
+			return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizListFilter).WithDetails(err.Error())
+		}
+		// The filter is exclusive, so move to the start of the following day to include the whole date
+		createdTo = createdTo.AddDate(0, 0, 1)
+		filter.CreatedTo = &createdTo
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizListFilter).
+			WithDetails("created_from cannot be after created_to")
+	}
+
+	return filter, nil
+}
+
+func newTagFilter(tag *string) *string {
+	if slug := helpers.NormalizeTagSlug(lo.FromPtr(tag)); slug != "" {
+		return &slug
+	}
+	return nil
+}
+
 func (s *quizService) GetQuizDetail(ctx context.Context, authUser *dtos.UserSession, req *dtos.GetQuizDetailRequest) (*models.Quiz, *exception.AppError) {
 	s.logger.Info("[GET QUIZ DETAIL]", authUser, req)
 
-	quiz, err := s.quizRepo.GetQuizDetail(ctx, req.QuizID, true)
+	return s.getViewableQuiz(ctx, authUser, req.QuizID)
+}
+
+func (s *quizService) GetQuizBySlug(ctx context.Context, authUser *dtos.UserSession, req *dtos.GetQuizBySlugRequest) (*models.Quiz, *exception.AppError) {
+	s.logger.Info("[GET QUIZ BY SLUG]", authUser, req)
+
+	quizID, err := s.quizRepo.ResolveSlug(ctx, helpers.NormalizeQuizSlug(req.Slug))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
@@ -277,19 +546,42 @@ func (s *quizService) GetQuizDetail(ctx context.Context, authUser *dtos.UserSess
 		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
-	if quiz.Visibility != models.QuizVisibilityPublished {
-		// If quiz is private/unlisted, check if user is the owner
-		if authUser == nil || quiz.OwnerID != authUser.UserID {
+	return s.getViewableQuiz(ctx, authUser, quizID)
+}
+
+// getViewableQuiz loads a quiz with its questions if authUser is allowed to see it
+func (s *quizService) getViewableQuiz(ctx context.Context, authUser *dtos.UserSession, quizID int64) (*models.Quiz, *exception.AppError) {
+	quiz, err := s.quizRepo.GetQuizDetail(ctx, quizID, true)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	// Published and unlisted quizzes are viewable by anyone with the link, private ones by the owner and
+	// collaborators. Anyone else gets a 404, so private quizzes cannot be probed for.
+	if quiz.Visibility == models.QuizVisibilityPrivate {
+		if authUser == nil {
 			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
 		}
+		if quiz.OwnerID != authUser.UserID {
+			role, err := s.quizCollaboratorRepo.GetCollaboratorRole(ctx, quiz.ID, authUser.UserID)
+			if err != nil && err != pgx.ErrNoRows {
+				return nil, exception.InternalError(errors.CodeDBError, err.Error())
+			}
+			if !role.Includes(models.QuizRoleViewer) {
+				return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
+			}
+		}
 	}
 
 	// Increment view count if user is not the owner
 	if authUser == nil || quiz.OwnerID != authUser.UserID {
 		go func() {
-			if err := s.quizRepo.IncrementViewCount(context.Background(), req.QuizID); err != nil {
+			if err := s.quizRepo.IncrementViewCount(context.Background(), quiz.ID); err != nil {
 				s.logger.Error("Failed to increment view count", map[string]interface{}{
-					"quiz_id": req.QuizID,
+					"quiz_id": quiz.ID,
 					"error":   err,
 				})
 			}
@@ -298,3 +590,258 @@ func (s *quizService) GetQuizDetail(ctx context.Context, authUser *dtos.UserSess
 
 	return quiz, nil
 }
+
+func (s *quizService) ForkQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.ForkQuizRequest) (*models.Quiz, *exception.AppError) {
+	s.logger.Info("[FORK QUIZ]", authUser, req)
+
+	source, err := s.quizRepo.GetQuizDetail(ctx, req.QuizID, true)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	// Published quizzes can be forked by anyone, everything else only by its owner
+	isOwner := source.OwnerID == authUser.UserID
+	if source.Visibility != models.QuizVisibilityPublished && !isOwner {
+		return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
+	}
+
+	title := source.Title
+	if req.Title != nil {
+		title = *req.Title
+	} else if isOwner {
+		title = fmt.Sprintf("%s (copy)", source.Title)
+		if utf8.RuneCountInString(title) > 255 {
+			title = source.Title
+		}
+	}
+
+	forkedQuiz, err := database.NewTransaction[models.Quiz](s.pool).Execute(ctx, func(ctx context.Context) (*models.Quiz, error) {
+		slug, err := s.quizRepo.GenerateSlug(ctx, title)
+		if err != nil {
+			return nil, err
+		}
+
+		quiz, err := s.quizRepo.CreateQuiz(ctx, &models.Quiz{
+			Title:            title,
+			Description:      source.Description,
+			Slug:             &slug,
+			OwnerID:          authUser.UserID,
+			MaxParticipants:  source.MaxParticipants,
+			ForkedFromQuizID: &source.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		quiz.Questions = make([]models.Question, len(source.Questions))
+		for i, sourceQuestion := range source.Questions {
+			question := &models.Question{
+				QuizID:           quiz.ID,
+				Question:         sourceQuestion.Question,
+				ContentFormat:    sourceQuestion.ContentFormat,
+				Index:            sourceQuestion.Index,
+				Type:             sourceQuestion.Type,
+				Answers:          sourceQuestion.Answers,
+				TimeLimit:        sourceQuestion.TimeLimit,
+				PointsMultiplier: sourceQuestion.PointsMultiplier,
+				Media:            sourceQuestion.Media,
+				Explanation:      sourceQuestion.Explanation,
+				ReferenceURL:     sourceQuestion.ReferenceURL,
+			}
+			// Media stays with the author who uploaded it, so only a copy of your own quiz keeps it
+			if !isOwner {
+				mediaHelpers.StripQuestionMedia(question)
+			}
+
+			createdQuestion, err := s.questionRepo.CreateQuestion(ctx, question)
+			if err != nil {
+				return nil, err
+			}
+			quiz.Questions[i] = *createdQuestion
+		}
+
+		totalQuestions := int32(len(quiz.Questions))
+		if err := s.quizRepo.UpdateTotalQuestions(ctx, quiz.ID, totalQuestions); err != nil {
+			return nil, err
+		}
+		quiz.TotalQuestions = &totalQuestions
+
+		// The fork starts its own history with the copied content
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, quiz.ID, &authUser.UserID, models.QuizVersionReasonSave); appErr != nil {
+			return nil, appErr
+		}
+
+		return quiz, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	forkedQuiz.ForkedFrom = &models.ForkedFrom{QuizID: source.ID}
+	if source.Visibility == models.QuizVisibilityPublished {
+		forkedQuiz.ForkedFrom.Title = &source.Title
+	}
+	if source.Owner != nil {
+		forkedQuiz.ForkedFrom.OwnerUsername = &source.Owner.Username
+	}
+
+	return forkedQuiz, nil
+}
+
+func (s *quizService) ExportQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.ExportQuizRequest) (*dtos.ExportQuizResponse, *exception.AppError) {
+	s.logger.Info("[EXPORT QUIZ]", authUser, req)
+
+	quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleViewer, true)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	content, err := helpers.EncodeQuizTransfer(req.Format, helpers.NewQuizTransferData(quiz))
+	if err != nil {
+		return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrQuizExportFailed).WithDetails(err.Error())
+	}
+
+	return &dtos.ExportQuizResponse{
+		FileName:    fmt.Sprintf("%s.%s", utils.TextToSlug(quiz.Title), helpers.QuizFormatFileExtension(req.Format)),
+		ContentType: helpers.QuizFormatContentType(req.Format),
+		Content:     content,
+	}, nil
+}
+
+func (s *quizService) ImportQuiz(ctx context.Context, authUser *dtos.UserSession, req *dtos.ImportQuizRequest) (*models.Quiz, *exception.AppError) {
+	s.logger.Info("[IMPORT QUIZ]", authUser, req.Format, req.Title)
+
+	data, err := helpers.DecodeQuizTransfer(req.Format, []byte(req.Content))
+	if err != nil {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizImportFile).WithDetails(err.Error())
+	}
+
+	quizReq := &dtos.CreateQuizRequest{
+		Title:           data.Title,
+		Description:     data.Description,
+		MaxParticipants: constants.DefaultMaxParticipants,
+	}
+	if req.Title != nil {
+		quizReq.Title = *req.Title
+	}
+	if quizReq.Title == "" {
+		quizReq.Title = "Imported quiz"
+	}
+	if data.MaxParticipants != nil {
+		quizReq.MaxParticipants = *data.MaxParticipants
+	}
+
+	if err := utils.ValidateStruct(quizReq); err != nil {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizImportFile).
+			WithMetadata("validation_errors", utils.ParseValidationError(err))
+	}
+
+	importedQuiz, err := database.NewTransaction[models.Quiz](s.pool).Execute(ctx, func(ctx context.Context) (*models.Quiz, error) {
+		slug, err := s.quizRepo.GenerateSlug(ctx, quizReq.Title)
+		if err != nil {
+			return nil, exception.InternalError(errors.CodeInternal, err.Error())
+		}
+
+		quiz, err := s.quizRepo.CreateQuiz(ctx, &models.Quiz{
+			Title:           quizReq.Title,
+			Description:     quizReq.Description,
+			Slug:            &slug,
+			OwnerID:         authUser.UserID,
+			MaxParticipants: &quizReq.MaxParticipants,
+		})
+		if err != nil {
+			return nil, exception.InternalError(errors.CodeDBError, err.Error())
+		}
+
+		// Validate every row against the same rules as CreateQuestion before writing any of them
+		questionReqs := make([]*dtos.CreateQuestionRequest, len(data.Questions))
+		rowErrors := make([]dtos.ImportQuestionError, 0)
+		for i, row := range data.Questions {
+			// Media ids only mean something on the instance that exported them
+			for j := range row.Answers {
+				row.Answers[j].Media = nil
+			}
+
+			questionReqs[i] = &dtos.CreateQuestionRequest{
+				QuizID:           quiz.ID,
+				Question:         row.Question,
+				ContentFormat:    row.ContentFormat,
+				Type:             row.Type,
+				Answers:          row.Answers,
+				TimeLimit:        int32(row.TimeLimit),
+				PointsMultiplier: row.PointsMultiplier,
+				Explanation:      row.Explanation,
+				ReferenceURL:     row.ReferenceURL,
+			}
+
+			if err := utils.ValidateStruct(questionReqs[i]); err != nil {
+				rowErrors = append(rowErrors, dtos.ImportQuestionError{Row: row.Row, Errors: utils.ParseValidationError(err)})
+				continue
+			}
+			if err := transformers.ValidateAnswersFormat(row.Answers, row.Type); err != nil {
+				rowErrors = append(rowErrors, dtos.ImportQuestionError{Row: row.Row, Errors: map[string]string{"Answers": err.Error()}})
+				continue
+			}
+			timeLimit, err := helpers.ResolveTimeLimit(questionReqs[i].TimeLimit, &s.config.Question)
+			if err != nil {
+				rowErrors = append(rowErrors, dtos.ImportQuestionError{Row: row.Row, Errors: map[string]string{"TimeLimit": err.Error()}})
+				continue
+			}
+			questionReqs[i].TimeLimit = timeLimit
+		}
+
+		if len(rowErrors) > 0 {
+			return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizImportRows).
+				WithMetadata("row_errors", rowErrors)
+		}
+
+		quiz.Questions = make([]models.Question, len(questionReqs))
+		for i, questionReq := range questionReqs {
+			question, err := s.questionRepo.CreateQuestion(ctx, &models.Question{
+				QuizID:           quiz.ID,
+				Question:         questionReq.Question,
+				ContentFormat:    questionReq.ContentFormat,
+				Index:            int32(i + 1),
+				Type:             questionReq.Type,
+				Answers:          questionReq.Answers,
+				TimeLimit:        questionReq.TimeLimit,
+				PointsMultiplier: helpers.ResolvePointsMultiplier(questionReq.PointsMultiplier),
+				Explanation:      questionReq.Explanation,
+				ReferenceURL:     questionReq.ReferenceURL,
+			})
+			if err != nil {
+				return nil, exception.InternalError(errors.CodeDBError, err.Error())
+			}
+			quiz.Questions[i] = *question
+		}
+
+		totalQuestions := int32(len(quiz.Questions))
+		if err := s.quizRepo.UpdateTotalQuestions(ctx, quiz.ID, totalQuestions); err != nil {
+			return nil, exception.InternalError(errors.CodeDBError, err.Error())
+		}
+		quiz.TotalQuestions = &totalQuestions
+
+		// The imported content is the first version of the quiz
+		if _, appErr := s.quizVersionService.SnapshotQuiz(ctx, quiz.ID, &authUser.UserID, models.QuizVersionReasonSave); appErr != nil {
+			return nil, appErr
+		}
+
+		return quiz, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return importedQuiz, nil
+}
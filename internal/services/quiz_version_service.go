@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	helpers "github.com/nghiavan0610/btaskee-quiz-service/helpers/quiz"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+type (
+	QuizVersionService interface {
+		// SnapshotQuiz records the current state of a quiz. It returns the latest version unchanged when nothing differs.
+		SnapshotQuiz(ctx context.Context, quizID int64, createdBy *int64, reason models.QuizVersionReason) (*models.QuizVersion, *exception.AppError)
+
+		ListQuizVersions(ctx context.Context, authUser *dtos.UserSession, req *dtos.ListQuizVersionsRequest) ([]*models.QuizVersion, *exception.AppError)
+		GetQuizVersion(ctx context.Context, authUser *dtos.UserSession, req *dtos.GetQuizVersionRequest) (*models.QuizVersion, *exception.AppError)
+		DiffQuizVersions(ctx context.Context, authUser *dtos.UserSession, req *dtos.DiffQuizVersionsRequest) (*models.QuizVersionDiff, *exception.AppError)
+		RestoreQuizVersion(ctx context.Context, authUser *dtos.UserSession, req *dtos.RestoreQuizVersionRequest) (*models.Quiz, *exception.AppError)
+	}
+
+	quizVersionService struct {
+		config            *config.Config
+		pool              *pgxpool.Pool
+		logger            *logger.Logger
+		quizVersionRepo   repositories.QuizVersionRepository
+		quizRepo          repositories.QuizRepository
+		questionRepo      repositories.QuestionRepository
+		validationService ValidationService
+	}
+)
+
+var (
+	quizVersionServiceOnce     sync.Once
+	quizVersionServiceInstance QuizVersionService
+)
+
+func ProvideQuizVersionService(
+	config *config.Config,
+	pool *pgxpool.Pool,
+	logger *logger.Logger,
+	quizVersionRepo repositories.QuizVersionRepository,
+	quizRepo repositories.QuizRepository,
+	questionRepo repositories.QuestionRepository,
+	validationService ValidationService,
+) QuizVersionService {
+	quizVersionServiceOnce.Do(func() {
+		quizVersionServiceInstance = &quizVersionService{
+			config:            config,
+			pool:              pool,
+			logger:            logger,
+			quizVersionRepo:   quizVersionRepo,
+			quizRepo:          quizRepo,
+			questionRepo:      questionRepo,
+			validationService: validationService,
+		}
+	})
+	return quizVersionServiceInstance
+}
+
+func (s *quizVersionService) SnapshotQuiz(ctx context.Context, quizID int64, createdBy *int64, reason models.QuizVersionReason) (*models.QuizVersion, *exception.AppError) {
+	// The quiz row is locked first, so concurrent snapshots of one quiz cannot pick the same version
+	// number. Callers already in a transaction hold the lock until theirs ends.
+	version, err := database.NewTransaction[models.QuizVersion](s.pool).Execute(ctx, func(ctx context.Context) (*models.QuizVersion, error) {
+		if err := s.quizRepo.LockQuiz(ctx, quizID); err != nil {
+			return nil, err
+		}
+
+		quiz, err := s.quizRepo.GetQuizDetail(ctx, quizID, true)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
+			}
+			return nil, err
+		}
+
+		questions := helpers.NewQuizVersionQuestions(quiz.Questions)
+
+		contentHash, err := helpers.ComputeQuizContentHash(quiz, questions)
+		if err != nil {
+			return nil, exception.InternalError(errors.CodeInternal, err.Error())
+		}
+
+		latest, err := s.quizVersionRepo.GetLatestQuizVersion(ctx, quizID)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, err
+		}
+		if latest != nil && latest.ContentHash == contentHash {
+			return latest, nil
+		}
+
+		return s.quizVersionRepo.CreateQuizVersion(ctx, &models.QuizVersion{
+			QuizID:          quiz.ID,
+			Title:           quiz.Title,
+			Description:     quiz.Description,
+			Visibility:      quiz.Visibility,
+			MaxParticipants: quiz.MaxParticipants,
+			ContentHash:     contentHash,
+			Reason:          reason,
+			CreatedBy:       createdBy,
+			Questions:       questions,
+		})
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return version, nil
+}
+
+func (s *quizVersionService) ListQuizVersions(ctx context.Context, authUser *dtos.UserSession, req *dtos.ListQuizVersionsRequest) ([]*models.QuizVersion, *exception.AppError) {
+	s.logger.Info("[LIST QUIZ VERSIONS]", authUser, req)
+
+	if _, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleViewer, false); appErr != nil {
+		return nil, appErr
+	}
+
+	versions, err := s.quizVersionRepo.ListQuizVersions(ctx, req.QuizID)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return versions, nil
+}
+
+func (s *quizVersionService) GetQuizVersion(ctx context.Context, authUser *dtos.UserSession, req *dtos.GetQuizVersionRequest) (*models.QuizVersion, *exception.AppError) {
+	s.logger.Info("[GET QUIZ VERSION]", authUser, req)
+
+	if _, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleViewer, false); appErr != nil {
+		return nil, appErr
+	}
+
+	return s.getQuizVersion(ctx, req.QuizID, req.Version)
+}
+
+func (s *quizVersionService) DiffQuizVersions(ctx context.Context, authUser *dtos.UserSession, req *dtos.DiffQuizVersionsRequest) (*models.QuizVersionDiff, *exception.AppError) {
+	s.logger.Info("[DIFF QUIZ VERSIONS]", authUser, req)
+
+	if _, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleViewer, false); appErr != nil {
+		return nil, appErr
+	}
+
+	from, appErr := s.getQuizVersion(ctx, req.QuizID, req.From)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	to, appErr := s.getQuizVersion(ctx, req.QuizID, req.To)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return helpers.DiffQuizVersions(from, to), nil
+}
+
+func (s *quizVersionService) RestoreQuizVersion(ctx context.Context, authUser *dtos.UserSession, req *dtos.RestoreQuizVersionRequest) (*models.Quiz, *exception.AppError) {
+	s.logger.Info("[RESTORE QUIZ VERSION]", authUser, req)
+
+	quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleEditor, false)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	version, appErr := s.getQuizVersion(ctx, req.QuizID, req.Version)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// Restoring brings back content only, the quiz keeps its current visibility
+	restoredQuiz, err := database.NewTransaction[models.Quiz](s.pool).Execute(ctx, func(ctx context.Context) (*models.Quiz, error) {
+		quiz.Title = version.Title
+		quiz.Description = version.Description
+		quiz.MaxParticipants = version.MaxParticipants
+
+		if _, err := s.quizRepo.UpdateQuiz(ctx, quiz); err != nil {
+			return nil, err
+		}
+
+		if err := s.questionRepo.DeleteQuestionsByQuizExcept(ctx, quiz.ID, []int64{}); err != nil {
+			return nil, err
+		}
+
+		for i, versionQuestion := range version.Questions {
+			_, err := s.questionRepo.CreateQuestion(ctx, &models.Question{
+				QuizID:           quiz.ID,
+				Question:         versionQuestion.Question,
+				ContentFormat:    versionQuestion.ContentFormat,
+				Index:            int32(i + 1),
+				Type:             versionQuestion.Type,
+				Answers:          versionQuestion.Answers,
+				TimeLimit:        versionQuestion.TimeLimit,
+				PointsMultiplier: versionQuestion.PointsMultiplier,
+				Media:            versionQuestion.Media,
+				Explanation:      versionQuestion.Explanation,
+				ReferenceURL:     versionQuestion.ReferenceURL,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if err := s.quizRepo.UpdateTotalQuestions(ctx, quiz.ID, int32(len(version.Questions))); err != nil {
+			return nil, err
+		}
+
+		restoredQuiz, err := s.quizRepo.GetQuizDetail(ctx, quiz.ID, true)
+		if err != nil {
+			return nil, err
+		}
+
+		// A published quiz must stay playable, so an old version that no longer passes the linter is
+		// refused like a publish would be
+		if restoredQuiz.Visibility == models.QuizVisibilityPublished {
+			report := helpers.LintQuiz(restoredQuiz, s.config.Quiz.GetMinPublishQuestions())
+			if !report.Publishable {
+				return nil, exception.BadRequest(errors.CodeValidation, errors.ErrQuizNotPublishable).
+					WithDetails("Make the quiz private or unlisted before restoring this version").
+					WithMetadata("lint", report)
+			}
+		}
+
+		if _, appErr := s.SnapshotQuiz(ctx, quiz.ID, &authUser.UserID, models.QuizVersionReasonRestore); appErr != nil {
+			return nil, appErr
+		}
+
+		return restoredQuiz, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return restoredQuiz, nil
+}
+
+func (s *quizVersionService) getQuizVersion(ctx context.Context, quizID int64, version int32) (*models.QuizVersion, *exception.AppError) {
+	quizVersion, err := s.quizVersionRepo.GetQuizVersion(ctx, quizID, version)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizVersionNotFound)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return quizVersion, nil
+}
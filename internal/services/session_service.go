@@ -2,14 +2,18 @@ package services
 
 import (
 	"context"
+	goErrors "errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/transformers"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
@@ -19,13 +23,18 @@ type (
 	SessionService interface {
 		CreateSession(ctx context.Context, authUser *dtos.UserSession, req *dtos.CreateSessionRequest) (*dtos.CreateSessionResponse, *exception.AppError)
 		JoinSession(ctx context.Context, authUser *dtos.UserSession, req *dtos.JoinSessionRequest) (*models.SessionParticipant, *exception.AppError)
+		GetSessionReview(ctx context.Context, req *dtos.GetSessionReviewRequest) (*dtos.SessionReviewResponse, *exception.AppError)
 	}
 
 	sessionService struct {
-		sessionRepo  repositories.SessionRepository
-		quizRepo     repositories.QuizRepository
-		questionRepo repositories.QuestionRepository
-		logger       *logger.Logger
+		pool               *pgxpool.Pool
+		sessionRepo        repositories.SessionRepository
+		quizRepo           repositories.QuizRepository
+		questionRepo       repositories.QuestionRepository
+		quizVersionRepo    repositories.QuizVersionRepository
+		quizVersionService QuizVersionService
+		validationService  ValidationService
+		logger             *logger.Logger
 	}
 )
 
@@ -35,17 +44,25 @@ var (
 )
 
 func ProvideSessionService(
+	pool *pgxpool.Pool,
 	sessionRepo repositories.SessionRepository,
 	quizRepo repositories.QuizRepository,
 	questionRepo repositories.QuestionRepository,
+	quizVersionRepo repositories.QuizVersionRepository,
+	quizVersionService QuizVersionService,
+	validationService ValidationService,
 	logger *logger.Logger,
 ) SessionService {
 	sessionServiceOnce.Do(func() {
 		sessionServiceInstance = &sessionService{
-			sessionRepo:  sessionRepo,
-			quizRepo:     quizRepo,
-			questionRepo: questionRepo,
-			logger:       logger,
+			pool:               pool,
+			sessionRepo:        sessionRepo,
+			quizRepo:           quizRepo,
+			questionRepo:       questionRepo,
+			quizVersionRepo:    quizVersionRepo,
+			quizVersionService: quizVersionService,
+			validationService:  validationService,
+			logger:             logger,
 		}
 	})
 	return sessionServiceInstance
@@ -60,9 +77,14 @@ func (s *sessionService) CreateSession(ctx context.Context, authUser *dtos.UserS
 		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
-	// Check if quiz is published (public quizzes can be hosted by anyone)
-	if quiz.Visibility != models.QuizVisibilityPublished && (authUser == nil || quiz.OwnerID != authUser.UserID) {
-		return nil, exception.Forbidden(errors.CodeForbidden, errors.ErrForbidden).WithDetails("You don't have permission to host this quiz")
+	// Published and unlisted quizzes can be hosted by anyone, private ones by the owner and collaborators
+	if quiz.Visibility == models.QuizVisibilityPrivate {
+		if authUser == nil {
+			return nil, exception.Forbidden(errors.CodeForbidden, errors.ErrForbidden).WithDetails("You don't have permission to host this quiz")
+		}
+		if _, appErr := s.validationService.ValidateQuizAccess(ctx, quiz.ID, authUser.UserID, models.QuizRoleViewer, false); appErr != nil {
+			return nil, appErr
+		}
 	}
 
 	// Generate unique join code
@@ -92,28 +114,45 @@ func (s *sessionService) CreateSession(ctx context.Context, authUser *dtos.UserS
 		ParticipantCount:     0,
 	}
 
-	createdSession, err := s.sessionRepo.CreateSession(ctx, session)
-	if err != nil {
-		return nil, exception.InternalError(errors.CodeInternal, err.Error())
-	}
+	// Session, host participant and participant count are written together
+	createdSession, err := database.NewTransaction[models.QuizSession](s.pool).Execute(ctx, func(ctx context.Context) (*models.QuizSession, error) {
+		// Pin the session to the quiz content it is played with
+		var createdBy *int64
+		if authUser != nil {
+			createdBy = &authUser.UserID
+		}
+		version, appErr := s.quizVersionService.SnapshotQuiz(ctx, quiz.ID, createdBy, models.QuizVersionReasonSession)
+		if appErr != nil {
+			return nil, appErr
+		}
+		session.QuizVersionID = &version.ID
 
-	hostParticipant := &models.SessionParticipant{
-		SessionID: createdSession.ID,
-		UserID:    hostID,
-		Nickname:  hostName,
-		Score:     0,
-		IsHost:    true,
-	}
+		createdSession, err := s.sessionRepo.CreateSession(ctx, session)
+		if err != nil {
+			return nil, err
+		}
+
+		hostParticipant := &models.SessionParticipant{
+			SessionID: createdSession.ID,
+			UserID:    hostID,
+			Nickname:  hostName,
+			Score:     0,
+			IsHost:    true,
+		}
+
+		if _, err := s.sessionRepo.AddParticipant(ctx, hostParticipant); err != nil {
+			return nil, err
+		}
 
-	_, err = s.sessionRepo.AddParticipant(ctx, hostParticipant)
-	if err != nil {
-		s.logger.Error("Failed to add host as participant", err)
-	} else {
 		createdSession.ParticipantCount = 1
-		_, updateErr := s.sessionRepo.UpdateSession(ctx, createdSession)
-		if updateErr != nil {
-			s.logger.Error("Failed to update participant count after adding host", updateErr)
+		return s.sessionRepo.UpdateSession(ctx, createdSession)
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
 		}
+		return nil, exception.InternalError(errors.CodeInternal, err.Error())
 	}
 
 	response := &dtos.CreateSessionResponse{
@@ -186,16 +225,73 @@ func (s *sessionService) JoinSession(ctx context.Context, authUser *dtos.UserSes
 		IsHost:    false,
 	}
 
-	createdParticipant, err := s.sessionRepo.AddParticipant(ctx, participant)
+	createdParticipant, err := database.NewTransaction[models.SessionParticipant](s.pool).Execute(ctx, func(ctx context.Context) (*models.SessionParticipant, error) {
+		createdParticipant, err := s.sessionRepo.AddParticipant(ctx, participant)
+		if err != nil {
+			return nil, err
+		}
+
+		session.ParticipantCount += 1
+		if _, err := s.sessionRepo.UpdateSession(ctx, session); err != nil {
+			return nil, err
+		}
+
+		return createdParticipant, nil
+	})
 	if err != nil {
 		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
-	session.ParticipantCount += 1
-	_, err = s.sessionRepo.UpdateSession(ctx, session)
+	return createdParticipant, nil
+}
+
+func (s *sessionService) GetSessionReview(ctx context.Context, req *dtos.GetSessionReviewRequest) (*dtos.SessionReviewResponse, *exception.AppError) {
+	session, err := s.sessionRepo.GetSessionByJoinCode(ctx, req.JoinCode)
 	if err != nil {
-		s.logger.Error("Failed to update participant count", err)
+		if err == pgx.ErrNoRows {
+			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrSessionNotFound)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
-	return createdParticipant, nil
+	// Correct answers and explanations stay hidden until the game is over
+	if session.Status != models.SessionStatusCompleted {
+		return nil, exception.BadRequest(errors.CodeBadRequest, errors.ErrSessionNotEnded)
+	}
+
+	response := &dtos.SessionReviewResponse{
+		SessionID: session.ID,
+		QuizID:    session.QuizID,
+		EndedAt:   session.EndedAt,
+	}
+
+	// Review the content the session was pinned to, even if the quiz has been edited since
+	if session.QuizVersionID != nil {
+		version, err := s.quizVersionRepo.GetQuizVersionByID(ctx, *session.QuizVersionID)
+		if err != nil {
+			return nil, exception.InternalError(errors.CodeDBError, err.Error())
+		}
+
+		response.QuizTitle = version.Title
+		response.Questions = make([]*models.QuestionReview, len(version.Questions))
+		for i, versionQuestion := range version.Questions {
+			question := transformers.ConvertVersionQuestionToModel(version.QuizID, versionQuestion)
+			response.Questions[i] = transformers.ConvertToQuestionReviewModel(question)
+		}
+
+		return response, nil
+	}
+
+	quiz, err := s.quizRepo.GetQuizDetail(ctx, session.QuizID, true)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	response.QuizTitle = quiz.Title
+	response.Questions = make([]*models.QuestionReview, len(quiz.Questions))
+	for i := range quiz.Questions {
+		response.Questions[i] = transformers.ConvertToQuestionReviewModel(&quiz.Questions[i])
+	}
+
+	return response, nil
 }
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	helpers "github.com/nghiavan0610/btaskee-quiz-service/helpers/quiz"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+const defaultPopularTagsLimit = 20
+
+type (
+	TagService interface {
+		SetQuizTags(ctx context.Context, authUser *dtos.UserSession, req *dtos.SetQuizTagsRequest) ([]models.Tag, *exception.AppError)
+
+		// Public browsing
+		ListCategories(ctx context.Context) ([]*models.Tag, *exception.AppError)
+		GetCategoryQuizzes(ctx context.Context, req *dtos.GetCategoryQuizzesRequest) (*dtos.GetCategoryQuizzesResponse, *exception.AppError)
+		GetPopularTags(ctx context.Context, req *dtos.GetPopularTagsRequest) ([]*models.Tag, *exception.AppError)
+	}
+
+	tagService struct {
+		pool              *pgxpool.Pool
+		logger            *logger.Logger
+		tagRepo           repositories.TagRepository
+		validationService ValidationService
+		quizService       QuizService
+	}
+)
+
+var (
+	tagServiceOnce     sync.Once
+	tagServiceInstance TagService
+)
+
+func ProvideTagService(
+	pool *pgxpool.Pool,
+	logger *logger.Logger,
+	tagRepo repositories.TagRepository,
+	validationService ValidationService,
+	quizService QuizService,
+) TagService {
+	tagServiceOnce.Do(func() {
+		tagServiceInstance = &tagService{
+			pool:              pool,
+			logger:            logger,
+			tagRepo:           tagRepo,
+			validationService: validationService,
+			quizService:       quizService,
+		}
+	})
+	return tagServiceInstance
+}
+
+func (s *tagService) SetQuizTags(ctx context.Context, authUser *dtos.UserSession, req *dtos.SetQuizTagsRequest) ([]models.Tag, *exception.AppError) {
+	s.logger.Info("[SET QUIZ TAGS]", authUser, req)
+
+	quiz, appErr := s.validationService.ValidateQuizAccess(ctx, req.QuizID, authUser.UserID, models.QuizRoleEditor, false)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	tags, ok := helpers.NewQuizTags(req.Tags)
+	if !ok {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizTags).
+			WithDetails("tag names must contain at least one letter or digit")
+	}
+	if len(tags) > helpers.MaxQuizTags {
+		return nil, exception.BadRequest(errors.CodeValidation, errors.ErrInvalidQuizTags).
+			WithDetails(fmt.Sprintf("a quiz can have at most %d tags", helpers.MaxQuizTags))
+	}
+
+	quizTags, err := database.NewTransaction[[]models.Tag](s.pool).Execute(ctx, func(ctx context.Context) (*[]models.Tag, error) {
+		quizTags, err := s.tagRepo.SetQuizTags(ctx, quiz.ID, tags)
+		if err != nil {
+			return nil, err
+		}
+		return &quizTags, nil
+	})
+	if err != nil {
+		var appErr *exception.AppError
+		if goErrors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	if *quizTags == nil {
+		return []models.Tag{}, nil
+	}
+
+	return *quizTags, nil
+}
+
+func (s *tagService) ListCategories(ctx context.Context) ([]*models.Tag, *exception.AppError) {
+	s.logger.Info("[LIST CATEGORIES]")
+
+	categories, err := s.tagRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return categories, nil
+}
+
+func (s *tagService) GetCategoryQuizzes(ctx context.Context, req *dtos.GetCategoryQuizzesRequest) (*dtos.GetCategoryQuizzesResponse, *exception.AppError) {
+	s.logger.Info("[GET CATEGORY QUIZZES]", req)
+
+	category, err := s.tagRepo.GetCategoryBySlug(ctx, helpers.NormalizeTagSlug(req.Slug))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrCategoryNotFound)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	// Browsing a category is the public catalogue filtered on its tag
+	quizList, appErr := s.quizService.GetQuizList(ctx, &dtos.GetQuizListRequest{
+		Query:  req.Query,
+		Cursor: req.Cursor,
+		Limit:  req.Limit,
+		SortBy: req.SortBy,
+		Tag:    &category.Slug,
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return &dtos.GetCategoryQuizzesResponse{
+		Category:            category,
+		GetQuizListResponse: quizList,
+	}, nil
+}
+
+func (s *tagService) GetPopularTags(ctx context.Context, req *dtos.GetPopularTagsRequest) ([]*models.Tag, *exception.AppError) {
+	s.logger.Info("[GET POPULAR TAGS]", req)
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultPopularTagsLimit
+	}
+
+	tags, err := s.tagRepo.GetPopularTags(ctx, limit)
+	if err != nil {
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	return tags, nil
+}
@@ -3,31 +3,59 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/nghiavan0610/btaskee-quiz-service/config"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/dtos"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/repositories"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/cache"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/jwtkeys"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
 )
 
 type (
 	TokenService interface {
-		GenerateTokenPair(ctx context.Context, user *models.User) (*dtos.TokenResponse, *exception.AppError)
+		// GenerateTokenPair starts a new login session for the device
+		GenerateTokenPair(ctx context.Context, user *models.User, device dtos.DeviceInfo) (*dtos.TokenResponse, *exception.AppError)
+		// RefreshTokenPair exchanges the refresh token the request was authenticated with for a new pair
+		// within the same session. Each refresh token can be exchanged once.
+		RefreshTokenPair(ctx context.Context, authUser *dtos.UserSession) (*dtos.TokenResponse, *exception.AppError)
 		ValidateAccessToken(ctx context.Context, tokenString string) (*dtos.JWTClaims, *exception.AppError)
+		// ReloadUserSession replaces the role and active flag copied into the session at sign-in with
+		// the stored ones, rejecting users who have since been disabled or deleted
+		ReloadUserSession(ctx context.Context, authUser *dtos.UserSession) *exception.AppError
 		ValidateRefreshToken(ctx context.Context, tokenString string) (*dtos.JWTClaims, *exception.AppError)
-		RevokeToken(ctx context.Context, userID string)
+		ListSessions(ctx context.Context, authUser *dtos.UserSession) ([]*models.AuthSession, *exception.AppError)
+		RevokeSession(ctx context.Context, userID int64, sessionID string) *exception.AppError
+		RevokeAllSessions(ctx context.Context, userID int64) *exception.AppError
+		// GenerateActionToken signs a token for an emailed link, such as email verification or password reset
+		GenerateActionToken(user *models.User, purpose dtos.ActionTokenPurpose, fingerprint string) (string, *exception.AppError)
+		// ParseActionToken checks the signature, expiry and purpose without using the token up
+		ParseActionToken(tokenString string, purpose dtos.ActionTokenPurpose) (*dtos.ActionTokenClaims, *exception.AppError)
+		// ConsumeActionToken marks the token as used, failing if it already was
+		ConsumeActionToken(ctx context.Context, claims *dtos.ActionTokenClaims) *exception.AppError
+		// JWKS lists the public keys tokens are verified with, for other services to check our tokens
+		JWKS() *jwtkeys.JSONWebKeySet
 	}
 
 	tokenService struct {
 		cache           cache.Cache
 		config          *config.Config
+		keySet          jwtkeys.KeySet
+		logger          *logger.Logger
+		userRepo        repositories.UserRepository
+		auditService    AuditService
 		accessTokenTTL  time.Duration
 		refreshTokenTTL time.Duration
 	}
@@ -41,11 +69,19 @@ var (
 func ProvideTokenService(
 	cache cache.Cache,
 	config *config.Config,
+	keySet jwtkeys.KeySet,
+	logger *logger.Logger,
+	userRepo repositories.UserRepository,
+	auditService AuditService,
 ) TokenService {
 	tokenServiceOnce.Do(func() {
 		tokenServiceInstance = &tokenService{
 			cache:           cache,
 			config:          config,
+			keySet:          keySet,
+			logger:          logger,
+			userRepo:        userRepo,
+			auditService:    auditService,
 			accessTokenTTL:  time.Duration(config.JWT.AccessTokenExpiration) * time.Second,
 			refreshTokenTTL: time.Duration(config.JWT.RefreshTokenExpiration) * time.Second,
 		}
@@ -53,92 +89,114 @@ func ProvideTokenService(
 	return tokenServiceInstance
 }
 
-func (s *tokenService) GenerateTokenPair(ctx context.Context, user *models.User) (*dtos.TokenResponse, *exception.AppError) {
+func (s *tokenService) GenerateTokenPair(ctx context.Context, user *models.User, device dtos.DeviceInfo) (*dtos.TokenResponse, *exception.AppError) {
 	now := time.Now()
+	sessionID := uuid.NewString()
 
-	userSession := dtos.UserSession{
-		UserID:   user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		IsActive: user.IsActive,
+	session := &dtos.CachedAuthSession{
+		Session: models.AuthSession{
+			ID:        sessionID,
+			IP:        device.IP,
+			UserAgent: device.UserAgent,
+			CreatedAt: now,
+		},
+		UserSession: dtos.UserSession{
+			UserID:    user.ID,
+			SessionID: sessionID,
+			Username:  user.Username,
+			Email:     user.Email,
+			IsActive:  user.IsActive,
+			Role:      user.Role,
+			LoginIP:   device.IP,
+			UserAgent: device.UserAgent,
+		},
 	}
 
-	// Generate access token
-	accessClaims := &dtos.JWTClaims{
-		UserSession: userSession,
-		Type:        string(constants.CachePrefixAccessToken),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    s.config.Server.ServiceName,
-			Subject:   fmt.Sprintf("%d", user.ID),
-			ID:        fmt.Sprintf("access_%d_%d", user.ID, now.Unix()),
-		},
+	tokens, appErr := s.issueTokens(ctx, session, now)
+	if appErr != nil {
+		return nil, appErr
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString([]byte(s.config.JWT.AccessTokenSecret))
-	if err != nil {
-		return nil, exception.InternalError(errors.CodeTokenGenerateFailed, errors.ErrTokenGenerateFailed).
-			WithDetails("Error occurred while generating access token").
+	if err := s.cache.AddToSet(ctx, s.sessionIndexKey(user.ID), sessionID); err != nil {
+		return nil, exception.InternalError(errors.CodeCacheSetFailed, errors.ErrFailedToSetCache).
+			WithDetails("Error occurred while caching login session").
 			WithMetadata("error", err.Error())
 	}
 
-	// Generate refresh token
-	refreshClaims := &dtos.JWTClaims{
-		UserSession: userSession,
-		Type:        string(constants.CachePrefixRefreshToken),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.refreshTokenTTL)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    s.config.Server.ServiceName,
-			Subject:   fmt.Sprintf("%d", user.ID),
-			ID:        fmt.Sprintf("refresh_%d_%d", user.ID, now.Unix()),
-		},
+	return tokens, nil
+}
+
+func (s *tokenService) RefreshTokenPair(ctx context.Context, authUser *dtos.UserSession) (*dtos.TokenResponse, *exception.AppError) {
+	session, appErr := s.getSession(ctx, authUser.UserID, authUser.SessionID)
+	if appErr != nil {
+		return nil, appErr
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(s.config.JWT.RefreshTokenSecret))
-	if err != nil {
-		return nil, exception.InternalError(errors.CodeTokenGenerateFailed, errors.ErrTokenGenerateFailed).
-			WithDetails("Error occurred while generating refresh token").
-			WithMetadata("error", err.Error())
+	// Claim the refresh token before rotating, so two requests racing with the same token
+	// cannot both get a new pair
+	claimed, appErr := s.cache.SetIfNotExists(ctx, cache.CacheKeyOption{
+		Module:    string(constants.CacheModuleAuth),
+		Prefix:    string(constants.CachePrefixUsedRefresh),
+		UniqueKey: strconv.FormatInt(authUser.UserID, 10),
+		Suffix:    authUser.TokenID,
+		Value:     session.Session.ID,
+		TTL:       s.refreshTokenTTL,
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+	if !claimed || authUser.TokenID != session.RefreshTokenID {
+		return nil, s.revokeReusedFamily(ctx, session, authUser.TokenID)
 	}
 
-	// Cache both tokens
-	if err := s.cacheToken(ctx, userSession, string(constants.CachePrefixAccessToken), now.Add(s.accessTokenTTL)); err != nil {
-		return nil, err
+	// The session lives as long as the refresh chain, so pick up role changes and deactivation
+	// before handing out the next pair
+	if appErr := s.ReloadUserSession(ctx, &session.UserSession); appErr != nil {
+		if appErr.Code == errors.CodeUnauthorized {
+			if revokeErr := s.RevokeSession(ctx, session.UserSession.UserID, session.Session.ID); revokeErr != nil {
+				s.logger.Error("[REFRESH TOKEN PAIR] Failed to revoke session", revokeErr.Error())
+			}
+		}
+		return nil, appErr
 	}
 
-	if err := s.cacheToken(ctx, userSession, string(constants.CachePrefixRefreshToken), now.Add(s.refreshTokenTTL)); err != nil {
-		return nil, err
+	return s.issueTokens(ctx, session, time.Now())
+}
+
+func (s *tokenService) ReloadUserSession(ctx context.Context, authUser *dtos.UserSession) *exception.AppError {
+	user, err := s.userRepo.GetUserDetail(ctx, authUser.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return exception.Unauthorized(errors.CodeUnauthorized, errors.ErrUserNotFound).
+				WithDetails("The account no longer exists")
+		}
+		return exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
-	return &dtos.TokenResponse{
-		AccessToken:  accessTokenString,
-		RefreshToken: refreshTokenString,
-		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
-	}, nil
+	if !user.IsActive {
+		return exception.Unauthorized(errors.CodeUnauthorized, errors.ErrUserDisabled).
+			WithDetails("Your account has been disabled. Please contact support")
+	}
+
+	authUser.Username = user.Username
+	authUser.Email = user.Email
+	authUser.IsActive = user.IsActive
+	authUser.Role = user.Role
+
+	return nil
 }
 
 func (s *tokenService) ValidateAccessToken(ctx context.Context, tokenString string) (*dtos.JWTClaims, *exception.AppError) {
-	return s.validateToken(ctx, tokenString, s.config.JWT.AccessTokenSecret, string(constants.CachePrefixAccessToken))
+	return s.validateToken(ctx, tokenString, string(constants.CachePrefixAccessToken))
 }
 
 func (s *tokenService) ValidateRefreshToken(ctx context.Context, tokenString string) (*dtos.JWTClaims, *exception.AppError) {
-	return s.validateToken(ctx, tokenString, s.config.JWT.RefreshTokenSecret, string(constants.CachePrefixRefreshToken))
+	return s.validateToken(ctx, tokenString, string(constants.CachePrefixRefreshToken))
 }
 
-func (s *tokenService) validateToken(ctx context.Context, tokenString, secret, expectedType string) (*dtos.JWTClaims, *exception.AppError) {
+func (s *tokenService) validateToken(ctx context.Context, tokenString, expectedType string) (*dtos.JWTClaims, *exception.AppError) {
 	// Parse and validate JWT
-	token, err := jwt.ParseWithClaims(tokenString, &dtos.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &dtos.JWTClaims{}, s.keySet.Keyfunc, jwt.WithValidMethods(s.keySet.ValidMethods()))
 
 	if err != nil {
 		return nil, exception.Unauthorized(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
@@ -157,40 +215,299 @@ func (s *tokenService) validateToken(ctx context.Context, tokenString, secret, e
 			WithDetails(fmt.Sprintf("Expected %s token but got %s", expectedType, claims.Type))
 	}
 
+	session, appErr := s.getSession(ctx, claims.UserSession.UserID, claims.UserSession.SessionID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	switch {
+	case expectedType == string(constants.CachePrefixRefreshToken) && claims.ID != session.RefreshTokenID:
+		// A genuine refresh token of this session that was already exchanged: someone is replaying it
+		return nil, s.revokeReusedFamily(ctx, session, claims.ID)
+	case expectedType == string(constants.CachePrefixAccessToken) && claims.ID != session.AccessTokenID:
+		return nil, exception.Unauthorized(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+			WithDetails("Token has been replaced by a newer one")
+	}
+
+	// The cached copy is reloaded from the user on every refresh, the token only carries what was
+	// true when it was issued. RoleGuard checks the stored role again for admin routes.
+	claims.UserSession = session.UserSession
+	claims.UserSession.TokenID = claims.ID
+
 	return claims, nil
 }
 
-func (s *tokenService) RevokeToken(ctx context.Context, userID string) {
-	accessOpt := cache.CacheKeyOption{
-		Module:    string(constants.CacheModuleAuth),
-		Prefix:    string(constants.CachePrefixAccessToken),
-		UniqueKey: userID,
+func (s *tokenService) ListSessions(ctx context.Context, authUser *dtos.UserSession) ([]*models.AuthSession, *exception.AppError) {
+	sessionIDs, appErr := s.cache.GetSetMembers(ctx, s.sessionIndexKey(authUser.UserID))
+	if appErr != nil {
+		return nil, appErr
 	}
 
-	refreshOpt := cache.CacheKeyOption{
-		Module:    string(constants.CacheModuleAuth),
-		Prefix:    string(constants.CachePrefixRefreshToken),
-		UniqueKey: userID,
+	sessions := make([]*models.AuthSession, 0, len(sessionIDs))
+	var expiredIDs []string
+	for _, sessionID := range sessionIDs {
+		var cached dtos.CachedAuthSession
+		if appErr := s.cache.GetObject(ctx, s.sessionKey(authUser.UserID, sessionID), &cached); appErr != nil {
+			if appErr.Code == errors.CodeCacheNotFound {
+				expiredIDs = append(expiredIDs, sessionID)
+				continue
+			}
+			return nil, appErr
+		}
+
+		session := cached.Session
+		session.Current = session.ID == authUser.SessionID
+		sessions = append(sessions, &session)
+	}
+
+	// Sessions expire on their own, drop them from the index as we come across them
+	if len(expiredIDs) > 0 {
+		s.cache.RemoveFromSet(ctx, s.sessionIndexKey(authUser.UserID), expiredIDs...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastRefreshedAt.After(sessions[j].LastRefreshedAt)
+	})
+
+	return sessions, nil
+}
+
+func (s *tokenService) RevokeSession(ctx context.Context, userID int64, sessionID string) *exception.AppError {
+	exists, appErr := s.cache.Exists(ctx, s.sessionKey(userID, sessionID))
+	if appErr != nil {
+		return appErr
+	}
+	if !exists {
+		return exception.NotFound(errors.CodeNotFound, errors.ErrLoginSessionNotFound)
+	}
+
+	if appErr := s.cache.Del(ctx, s.sessionKey(userID, sessionID)); appErr != nil {
+		return appErr
+	}
+
+	return s.cache.RemoveFromSet(ctx, s.sessionIndexKey(userID), sessionID)
+}
+
+func (s *tokenService) RevokeAllSessions(ctx context.Context, userID int64) *exception.AppError {
+	sessionIDs, appErr := s.cache.GetSetMembers(ctx, s.sessionIndexKey(userID))
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, sessionID := range sessionIDs {
+		if appErr := s.cache.Del(ctx, s.sessionKey(userID, sessionID)); appErr != nil {
+			return appErr
+		}
+	}
+
+	return s.cache.Del(ctx, s.sessionIndexKey(userID))
+}
+
+func (s *tokenService) GenerateActionToken(user *models.User, purpose dtos.ActionTokenPurpose, fingerprint string) (string, *exception.AppError) {
+	now := time.Now()
+
+	ttl := time.Duration(s.config.Auth.GetEmailVerificationTTL()) * time.Second
+	switch purpose {
+	case dtos.ActionTokenPasswordReset:
+		ttl = time.Duration(s.config.Auth.GetPasswordResetTTL()) * time.Second
+	case dtos.ActionTokenAccountUnlock:
+		ttl = time.Duration(s.config.Auth.GetLockoutDuration()) * time.Second
+	case dtos.ActionTokenMFAChallenge:
+		ttl = time.Duration(s.config.Auth.GetMFAChallengeTTL()) * time.Second
+	}
+
+	claims := &dtos.ActionTokenClaims{
+		UserID:      user.ID,
+		Email:       user.Email,
+		Purpose:     purpose,
+		Fingerprint: fingerprint,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    s.config.Server.ServiceName,
+			Subject:   strconv.FormatInt(user.ID, 10),
+			ID:        uuid.NewString(),
+		},
+	}
+
+	tokenString, err := s.keySet.Sign(claims)
+	if err != nil {
+		return "", exception.InternalError(errors.CodeTokenGenerateFailed, errors.ErrTokenGenerateFailed).
+			WithDetails(fmt.Sprintf("Error occurred while generating %s token", purpose)).
+			WithMetadata("error", err.Error())
 	}
 
-	s.cache.Del(ctx, accessOpt)
-	s.cache.Del(ctx, refreshOpt)
+	return tokenString, nil
 }
 
-func (s *tokenService) cacheToken(ctx context.Context, userSession dtos.UserSession, tokenType string, expiresAt time.Time) *exception.AppError {
-	cacheOpt := cache.CacheKeyOption{
+func (s *tokenService) ParseActionToken(tokenString string, purpose dtos.ActionTokenPurpose) (*dtos.ActionTokenClaims, *exception.AppError) {
+	token, err := jwt.ParseWithClaims(tokenString, &dtos.ActionTokenClaims{}, s.keySet.Keyfunc, jwt.WithValidMethods(s.keySet.ValidMethods()))
+	if err != nil {
+		return nil, exception.BadRequest(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+			WithDetails("The link is invalid or has expired").
+			WithMetadata("error", err.Error())
+	}
+
+	claims, ok := token.Claims.(*dtos.ActionTokenClaims)
+	if !ok || !token.Valid || claims.Purpose != purpose {
+		return nil, exception.BadRequest(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+			WithDetails("The link is invalid or has expired")
+	}
+
+	return claims, nil
+}
+
+func (s *tokenService) ConsumeActionToken(ctx context.Context, claims *dtos.ActionTokenClaims) *exception.AppError {
+	ttl := s.refreshTokenTTL
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
+	if ttl <= 0 {
+		return exception.BadRequest(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+			WithDetails("The link is invalid or has expired")
+	}
+
+	claimed, appErr := s.cache.SetIfNotExists(ctx, cache.CacheKeyOption{
 		Module:    string(constants.CacheModuleAuth),
-		Prefix:    tokenType,
-		UniqueKey: fmt.Sprintf("%d", userSession.UserID),
-		Value:     userSession,
-		TTL:       time.Until(expiresAt),
+		Prefix:    string(constants.CachePrefixUsedAction),
+		UniqueKey: strconv.FormatInt(claims.UserID, 10),
+		Suffix:    claims.ID,
+		Value:     claims.Purpose,
+		TTL:       ttl,
+	})
+	if appErr != nil {
+		return appErr
+	}
+	if !claimed {
+		return exception.BadRequest(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+			WithDetails("The link has already been used")
+	}
+
+	return nil
+}
+
+func (s *tokenService) JWKS() *jwtkeys.JSONWebKeySet {
+	return s.keySet.JWKS()
+}
+
+// revokeReusedFamily ends a session whose rotated refresh token was presented again. Either the
+// legitimate client or an attacker holds a stolen copy, and we cannot tell which, so both lose it.
+func (s *tokenService) revokeReusedFamily(ctx context.Context, session *dtos.CachedAuthSession, tokenID string) *exception.AppError {
+	userID := session.UserSession.UserID
+
+	if appErr := s.RevokeSession(ctx, userID, session.Session.ID); appErr != nil {
+		s.logger.Error("[REVOKE REUSED TOKEN FAMILY]", appErr.Error())
+	}
+
+	targetType := models.AuditTargetUser
+	s.auditService.Record(ctx, &models.AuditLog{
+		ActorID:    &userID,
+		Action:     models.AuditActionRefreshTokenReused,
+		TargetType: &targetType,
+		TargetID:   &userID,
+		Metadata: map[string]interface{}{
+			"session_id":         session.Session.ID,
+			"reused_token_id":    tokenID,
+			"session_ip":         session.Session.IP,
+			"session_user_agent": session.Session.UserAgent,
+		},
+	})
+
+	return exception.Unauthorized(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+		WithDetails("Refresh token has already been used. The session has been signed out")
+}
+
+// issueTokens signs a new token pair for the session and caches the session with the new token ids
+func (s *tokenService) issueTokens(ctx context.Context, session *dtos.CachedAuthSession, now time.Time) (*dtos.TokenResponse, *exception.AppError) {
+	session.AccessTokenID = uuid.NewString()
+	session.RefreshTokenID = uuid.NewString()
+	session.Session.LastRefreshedAt = now
+	session.Session.ExpiresAt = now.Add(s.refreshTokenTTL)
+
+	accessTokenString, appErr := s.signToken(session, constants.CachePrefixAccessToken, session.AccessTokenID, now, s.accessTokenTTL)
+	if appErr != nil {
+		return nil, appErr
 	}
 
+	refreshTokenString, appErr := s.signToken(session, constants.CachePrefixRefreshToken, session.RefreshTokenID, now, s.refreshTokenTTL)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	cacheOpt := s.sessionKey(session.UserSession.UserID, session.Session.ID)
+	cacheOpt.Value = session
+	cacheOpt.TTL = s.refreshTokenTTL
 	if err := s.cache.Set(ctx, cacheOpt); err != nil {
-		return exception.InternalError(errors.CodeCacheSetFailed, errors.ErrFailedToSetCache).
-			WithDetails("Error occurred while caching token").
+		return nil, exception.InternalError(errors.CodeCacheSetFailed, errors.ErrFailedToSetCache).
+			WithDetails("Error occurred while caching login session").
 			WithMetadata("error", err.Error())
 	}
 
-	return nil
+	return &dtos.TokenResponse{
+		AccessToken:  accessTokenString,
+		RefreshToken: refreshTokenString,
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *tokenService) signToken(session *dtos.CachedAuthSession, tokenType constants.CachePrefix, tokenID string, now time.Time, ttl time.Duration) (string, *exception.AppError) {
+	claims := &dtos.JWTClaims{
+		UserSession: session.UserSession,
+		Type:        string(tokenType),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    s.config.Server.ServiceName,
+			Subject:   strconv.FormatInt(session.UserSession.UserID, 10),
+			ID:        tokenID,
+		},
+	}
+
+	tokenString, err := s.keySet.Sign(claims)
+	if err != nil {
+		return "", exception.InternalError(errors.CodeTokenGenerateFailed, errors.ErrTokenGenerateFailed).
+			WithDetails(fmt.Sprintf("Error occurred while generating %s", tokenType)).
+			WithMetadata("error", err.Error())
+	}
+
+	return tokenString, nil
+}
+
+// getSession loads a login session, treating a missing one as signed out
+func (s *tokenService) getSession(ctx context.Context, userID int64, sessionID string) (*dtos.CachedAuthSession, *exception.AppError) {
+	if sessionID == "" {
+		return nil, exception.Unauthorized(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+			WithDetails("Token does not belong to a login session")
+	}
+
+	var session dtos.CachedAuthSession
+	if appErr := s.cache.GetObject(ctx, s.sessionKey(userID, sessionID), &session); appErr != nil {
+		if appErr.Code == errors.CodeCacheNotFound {
+			return nil, exception.Unauthorized(errors.CodeTokenInvalid, errors.ErrTokenInvalid).
+				WithDetails("Login session has been signed out or has expired")
+		}
+		return nil, appErr
+	}
+
+	return &session, nil
+}
+
+func (s *tokenService) sessionKey(userID int64, sessionID string) cache.CacheKeyOption {
+	return cache.CacheKeyOption{
+		Module:    string(constants.CacheModuleAuth),
+		Prefix:    string(constants.CachePrefixAuthSession),
+		UniqueKey: strconv.FormatInt(userID, 10),
+		Suffix:    sessionID,
+	}
+}
+
+func (s *tokenService) sessionIndexKey(userID int64) cache.CacheKeyOption {
+	return cache.CacheKeyOption{
+		Module:    string(constants.CacheModuleAuth),
+		Prefix:    string(constants.CachePrefixAuthSessions),
+		UniqueKey: strconv.FormatInt(userID, 10),
+		TTL:       s.refreshTokenTTL,
+	}
 }
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/jackc/pgx/v5"
@@ -14,12 +15,15 @@ import (
 type (
 	ValidationService interface {
 		ValidateQuizOwnership(ctx context.Context, quizID int64, userID int64, includeQuestions bool) (*models.Quiz, *exception.AppError)
-		ValidateQuestionOwnership(ctx context.Context, questionID int64, userID int64) (*models.Question, *models.Quiz, *exception.AppError)
+		// ValidateQuizAccess lets the owner and collaborators whose role includes the required one through
+		ValidateQuizAccess(ctx context.Context, quizID int64, userID int64, required models.QuizRole, includeQuestions bool) (*models.Quiz, *exception.AppError)
+		ValidateQuestionAccess(ctx context.Context, questionID int64, userID int64, required models.QuizRole) (*models.Question, *models.Quiz, *exception.AppError)
 	}
 
 	validationService struct {
-		quizRepo     repositories.QuizRepository
-		questionRepo repositories.QuestionRepository
+		quizRepo             repositories.QuizRepository
+		questionRepo         repositories.QuestionRepository
+		quizCollaboratorRepo repositories.QuizCollaboratorRepository
 	}
 )
 
@@ -31,11 +35,13 @@ var (
 func ProvideValidationService(
 	quizRepo repositories.QuizRepository,
 	questionRepo repositories.QuestionRepository,
+	quizCollaboratorRepo repositories.QuizCollaboratorRepository,
 ) ValidationService {
 	validationServiceOnce.Do(func() {
 		validationServiceInstance = &validationService{
-			quizRepo:     quizRepo,
-			questionRepo: questionRepo,
+			quizRepo:             quizRepo,
+			questionRepo:         questionRepo,
+			quizCollaboratorRepo: quizCollaboratorRepo,
 		}
 	})
 	return validationServiceInstance
@@ -54,11 +60,38 @@ func (s *validationService) ValidateQuizOwnership(ctx context.Context, quizID in
 		return nil, exception.Forbidden(errors.CodeUnauthorized, errors.ErrUnauthorizedAccess).
 			WithDetails("Only the quiz owner can perform this action")
 	}
+	quiz.Role = models.QuizRoleOwner
 
 	return quiz, nil
 }
 
-func (s *validationService) ValidateQuestionOwnership(ctx context.Context, questionID int64, userID int64) (*models.Question, *models.Quiz, *exception.AppError) {
+func (s *validationService) ValidateQuizAccess(ctx context.Context, quizID int64, userID int64, required models.QuizRole, includeQuestions bool) (*models.Quiz, *exception.AppError) {
+	quiz, err := s.quizRepo.GetQuizDetail(ctx, quizID, includeQuestions)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, exception.NotFound(errors.CodeNotFound, errors.ErrQuizNotFound)
+		}
+		return nil, exception.InternalError(errors.CodeDBError, err.Error())
+	}
+
+	role := models.QuizRoleOwner
+	if quiz.OwnerID != userID {
+		role, err = s.quizCollaboratorRepo.GetCollaboratorRole(ctx, quiz.ID, userID)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, exception.InternalError(errors.CodeDBError, err.Error())
+		}
+	}
+
+	if !role.Includes(required) {
+		return nil, exception.Forbidden(errors.CodeUnauthorized, errors.ErrUnauthorizedAccess).
+			WithDetails(fmt.Sprintf("This action needs %s access to the quiz", required))
+	}
+	quiz.Role = role
+
+	return quiz, nil
+}
+
+func (s *validationService) ValidateQuestionAccess(ctx context.Context, questionID int64, userID int64, required models.QuizRole) (*models.Question, *models.Quiz, *exception.AppError) {
 	question, err := s.questionRepo.GetQuestionByID(ctx, questionID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -67,7 +100,7 @@ func (s *validationService) ValidateQuestionOwnership(ctx context.Context, quest
 		return nil, nil, exception.InternalError(errors.CodeDBError, err.Error())
 	}
 
-	quiz, appErr := s.ValidateQuizOwnership(ctx, question.QuizID, userID, false)
+	quiz, appErr := s.ValidateQuizAccess(ctx, question.QuizID, userID, required, false)
 	if appErr != nil {
 		return nil, nil, appErr
 	}
@@ -0,0 +1,30 @@
+package transformers
+
+import (
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+func ConvertToAPIKeyModel(result sqlc.ApiKey) *models.APIKey {
+	scopes := make([]models.APIKeyScope, len(result.Scopes))
+	for i, scope := range result.Scopes {
+		scopes[i] = models.APIKeyScope(scope)
+	}
+
+	apiKey := &models.APIKey{
+		ID:        result.ID,
+		UserID:    result.UserID,
+		Name:      result.Name,
+		Prefix:    result.Prefix,
+		Scopes:    scopes,
+		CreatedAt: result.CreatedAt.Time,
+	}
+	if result.ExpiresAt.Valid {
+		apiKey.ExpiresAt = &result.ExpiresAt.Time
+	}
+	if result.LastUsedAt.Valid {
+		apiKey.LastUsedAt = &result.LastUsedAt.Time
+	}
+
+	return apiKey
+}
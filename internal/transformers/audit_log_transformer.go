@@ -0,0 +1,25 @@
+package transformers
+
+import (
+	"encoding/json"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+func ConvertToAuditLogModel(result sqlc.AuditLog) *models.AuditLog {
+	metadata := map[string]interface{}{}
+	if len(result.Metadata) > 0 {
+		_ = json.Unmarshal(result.Metadata, &metadata)
+	}
+
+	return &models.AuditLog{
+		ID:         result.ID,
+		ActorID:    result.ActorID,
+		Action:     result.Action,
+		TargetType: result.TargetType,
+		TargetID:   result.TargetID,
+		Metadata:   metadata,
+		CreatedAt:  result.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,22 @@
+package transformers
+
+import (
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+func ConvertToIdentityModel(result sqlc.Identity) *models.Identity {
+	identity := &models.Identity{
+		ID:        result.ID,
+		UserID:    result.UserID,
+		Provider:  result.Provider,
+		Subject:   result.Subject,
+		Email:     result.Email,
+		CreatedAt: result.CreatedAt.Time,
+	}
+	if result.LastLoginAt.Valid {
+		identity.LastLoginAt = &result.LastLoginAt.Time
+	}
+
+	return identity
+}
@@ -0,0 +1,20 @@
+package transformers
+
+import (
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+// ConvertToMediaModel leaves URL empty: it depends on the storage backend and is set by the media service
+func ConvertToMediaModel(result sqlc.Medium) *models.Media {
+	return &models.Media{
+		ID:           result.ID,
+		OwnerID:      result.OwnerID,
+		Kind:         models.MediaKind(result.Kind),
+		ContentType:  result.ContentType,
+		SizeBytes:    result.SizeBytes,
+		OriginalName: result.OriginalName,
+		StorageKey:   result.StorageKey,
+		CreatedAt:    result.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,20 @@
+package transformers
+
+import (
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+func ConvertToUserTOTPModel(result sqlc.UserTotp) *models.UserTOTP {
+	userTOTP := &models.UserTOTP{
+		UserID:       result.UserID,
+		Secret:       result.Secret,
+		LastUsedStep: result.LastUsedStep,
+		CreatedAt:    result.CreatedAt.Time,
+	}
+	if result.EnabledAt.Valid {
+		userTOTP.EnabledAt = &result.EnabledAt.Time
+	}
+
+	return userTOTP
+}
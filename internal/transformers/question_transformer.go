@@ -3,21 +3,28 @@ package transformers
 import (
 	"encoding/json"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
 	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/markdown"
 )
 
 func ConvertToQuestionModel(result sqlc.Question) (*models.Question, error) {
 	question := &models.Question{
-		ID:        result.ID,
-		QuizID:    result.QuizID,
-		Question:  result.Question,
-		Type:      models.QuestionType(result.Type),
-		Index:     result.Index,
-		TimeLimit: models.TimeLimitType(result.TimeLimit),
-		CreatedAt: result.CreatedAt.Time,
-		UpdatedAt: result.UpdatedAt.Time,
+		ID:               result.ID,
+		QuizID:           result.QuizID,
+		Question:         result.Question,
+		ContentFormat:    models.ContentFormat(result.ContentFormat),
+		Explanation:      result.Explanation,
+		ReferenceURL:     result.ReferenceUrl,
+		Type:             models.QuestionType(result.Type),
+		Index:            result.Index,
+		TimeLimit:        result.TimeLimit,
+		PointsMultiplier: result.PointsMultiplier,
+		CreatedAt:        result.CreatedAt.Time,
+		UpdatedAt:        result.UpdatedAt.Time,
 	}
 
 	if len(result.Answers) > 0 {
@@ -30,15 +37,48 @@ func ConvertToQuestionModel(result sqlc.Question) (*models.Question, error) {
 		question.Answers = []models.AnswerData{}
 	}
 
+	media, err := ParseMediaAttachmentFromJSON(result.Media)
+	if err != nil {
+		return nil, err
+	}
+	question.Media = media
+
+	RenderQuestionContent(question)
+
 	return question, nil
 }
 
+// RenderQuestionContent fills the sanitized HTML variants of the question, answer and explanation text.
+// Explanations are always markdown.
+func RenderQuestionContent(question *models.Question) {
+	render := markdown.RenderPlain
+	if question.ContentFormat == models.ContentFormatMarkdown {
+		render = markdown.Render
+	}
+
+	question.QuestionHTML = render(question.Question)
+	for i := range question.Answers {
+		question.Answers[i].TextHTML = render(question.Answers[i].Text)
+	}
+
+	if question.Explanation != nil {
+		question.ExplanationHTML = markdown.Render(*question.Explanation)
+	}
+}
+
 func ConvertAnswersToJSON(answers []models.AnswerData) ([]byte, error) {
-	if answers == nil {
-		return json.Marshal([]models.AnswerData{})
+	return json.Marshal(StripAnswersHTML(answers))
+}
+
+// StripAnswersHTML copies answers without their rendered HTML, which is derived on read and never persisted
+func StripAnswersHTML(answers []models.AnswerData) []models.AnswerData {
+	stripped := make([]models.AnswerData, len(answers))
+	for i, answer := range answers {
+		answer.TextHTML = ""
+		stripped[i] = answer
 	}
 
-	return json.Marshal(answers)
+	return stripped
 }
 
 func ParseAnswersFromJSON(jsonData []byte) ([]models.AnswerData, error) {
@@ -54,6 +94,27 @@ func ParseAnswersFromJSON(jsonData []byte) ([]models.AnswerData, error) {
 	return answers, nil
 }
 
+func ConvertMediaAttachmentToJSON(media *models.MediaAttachment) ([]byte, error) {
+	if media == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(media)
+}
+
+func ParseMediaAttachmentFromJSON(jsonData []byte) (*models.MediaAttachment, error) {
+	if len(jsonData) == 0 || string(jsonData) == "null" {
+		return nil, nil
+	}
+
+	var media models.MediaAttachment
+	if err := json.Unmarshal(jsonData, &media); err != nil {
+		return nil, fmt.Errorf("failed to parse media JSON: %w", err)
+	}
+
+	return &media, nil
+}
+
 func ValidateAnswersFormat(answers []models.AnswerData, questionType models.QuestionType) error {
 	switch questionType {
 	case models.QuestionTypeSingleChoice:
@@ -89,5 +150,27 @@ func ValidateAnswersFormat(answers []models.AnswerData, questionType models.Ques
 	case models.QuestionTypeTextInput:
 	}
 
+	for _, answer := range answers {
+		if utf8.RuneCountInString(answer.Text) > constants.MaxAnswerTextLength {
+			return fmt.Errorf("answer text must be at most %d characters", constants.MaxAnswerTextLength)
+		}
+	}
+
 	return nil
 }
+
+func ConvertToQuestionReviewModel(question *models.Question) *models.QuestionReview {
+	return &models.QuestionReview{
+		QuestionID:      question.ID,
+		Index:           question.Index,
+		Question:        question.Question,
+		QuestionHTML:    question.QuestionHTML,
+		ContentFormat:   question.ContentFormat,
+		Type:            question.Type,
+		Answers:         question.Answers,
+		Media:           question.Media,
+		Explanation:     question.Explanation,
+		ExplanationHTML: question.ExplanationHTML,
+		ReferenceURL:    question.ReferenceURL,
+	}
+}
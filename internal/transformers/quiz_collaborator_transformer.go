@@ -0,0 +1,20 @@
+package transformers
+
+import (
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+func ConvertToQuizCollaboratorModel(result sqlc.ListQuizCollaboratorsRow) *models.QuizCollaborator {
+	return &models.QuizCollaborator{
+		QuizID:    result.QuizID,
+		UserID:    result.UserID,
+		Username:  result.Username,
+		Email:     result.Email,
+		AvatarURL: result.AvatarUrl,
+		Role:      models.QuizRole(result.Role),
+		InvitedBy: result.InvitedBy,
+		CreatedAt: result.CreatedAt.Time,
+		UpdatedAt: result.UpdatedAt.Time,
+	}
+}
@@ -31,13 +31,17 @@ func convertToQuizModel(result sqlc.Quiz) *models.Quiz {
 		Title:                result.Title,
 		Description:          result.Description,
 		Visibility:           models.QuizVisibility(result.Visibility),
+		Slug:                 result.Slug,
 		OwnerID:              result.OwnerID,
+		ViewCount:            result.ViewCount,
+		PlayCount:            result.PlayCount,
 		MaxParticipants:      result.MaxParticipants,
 		CurrentQuestionIndex: result.CurrentQuestionIndex,
 		TotalQuestions:       result.TotalQuestions,
 		CreatedAt:            result.CreatedAt.Time,
 		UpdatedAt:            result.UpdatedAt.Time,
 		PublishedAt:          publishedAt,
+		ForkedFromQuizID:     result.ForkedFromQuizID,
 	}
 }
 
@@ -52,13 +56,17 @@ func convertToQuizWithOwnerModel(result sqlc.GetQuizWithOwnerRow) *models.Quiz {
 		Title:                result.Title,
 		Description:          result.Description,
 		Visibility:           models.QuizVisibility(result.Visibility),
+		Slug:                 result.Slug,
 		OwnerID:              result.OwnerID,
+		ViewCount:            result.ViewCount,
+		PlayCount:            result.PlayCount,
 		MaxParticipants:      result.MaxParticipants,
 		CurrentQuestionIndex: result.CurrentQuestionIndex,
 		TotalQuestions:       result.TotalQuestions,
 		CreatedAt:            result.CreatedAt.Time,
 		UpdatedAt:            result.UpdatedAt.Time,
 		PublishedAt:          publishedAt,
+		ForkedFromQuizID:     result.ForkedFromQuizID,
 	}
 
 	if result.OwnerUserID != nil {
@@ -70,6 +78,16 @@ func convertToQuizWithOwnerModel(result sqlc.GetQuizWithOwnerRow) *models.Quiz {
 		}
 	}
 
+	if result.ForkedFromQuizID != nil {
+		quiz.ForkedFrom = &models.ForkedFrom{
+			QuizID:        *result.ForkedFromQuizID,
+			OwnerUsername: result.ForkedFromOwnerUsername,
+		}
+		if result.ForkedFromVisibility.Valid && result.ForkedFromVisibility.QuizVisibility == sqlc.QuizVisibilityPublished {
+			quiz.ForkedFrom.Title = result.ForkedFromTitle
+		}
+	}
+
 	return quiz
 }
 
@@ -84,13 +102,21 @@ func convertToQuizWithOwnerFromPublicQuizzes(result sqlc.GetPublicQuizzesRow) *m
 		Title:                result.Title,
 		Description:          result.Description,
 		Visibility:           models.QuizVisibility(result.Visibility),
+		Slug:                 result.Slug,
 		OwnerID:              result.OwnerID,
+		ViewCount:            result.ViewCount,
+		PlayCount:            result.PlayCount,
 		MaxParticipants:      result.MaxParticipants,
 		CurrentQuestionIndex: result.CurrentQuestionIndex,
 		TotalQuestions:       result.TotalQuestions,
 		CreatedAt:            result.CreatedAt.Time,
 		UpdatedAt:            result.UpdatedAt.Time,
 		PublishedAt:          publishedAt,
+		ForkedFromQuizID:     result.ForkedFromQuizID,
+	}
+
+	if result.Relevance > 0 {
+		quiz.Relevance = &result.Relevance
 	}
 
 	if result.OwnerUserID != nil {
@@ -0,0 +1,95 @@
+package transformers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+func ConvertToQuizVersionModel(result sqlc.QuizVersion) (*models.QuizVersion, error) {
+	questions, err := ParseQuizVersionQuestionsFromJSON(result.Questions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.QuizVersion{
+		ID:              result.ID,
+		QuizID:          result.QuizID,
+		Version:         result.Version,
+		Title:           result.Title,
+		Description:     result.Description,
+		Visibility:      models.QuizVisibility(result.Visibility),
+		MaxParticipants: result.MaxParticipants,
+		ContentHash:     result.ContentHash,
+		Reason:          models.QuizVersionReason(result.Reason),
+		CreatedBy:       result.CreatedBy,
+		CreatedAt:       result.CreatedAt.Time,
+		QuestionCount:   int32(len(questions)),
+		Questions:       questions,
+	}, nil
+}
+
+func ConvertToQuizVersionSummaryModel(result sqlc.ListQuizVersionsRow) *models.QuizVersion {
+	return &models.QuizVersion{
+		ID:              result.ID,
+		QuizID:          result.QuizID,
+		Version:         result.Version,
+		Title:           result.Title,
+		Description:     result.Description,
+		Visibility:      models.QuizVisibility(result.Visibility),
+		MaxParticipants: result.MaxParticipants,
+		ContentHash:     result.ContentHash,
+		Reason:          models.QuizVersionReason(result.Reason),
+		CreatedBy:       result.CreatedBy,
+		CreatedAt:       result.CreatedAt.Time,
+		QuestionCount:   result.QuestionCount,
+	}
+}
+
+func ConvertQuizVersionQuestionsToJSON(questions []models.QuizVersionQuestion) ([]byte, error) {
+	if questions == nil {
+		return json.Marshal([]models.QuizVersionQuestion{})
+	}
+
+	return json.Marshal(questions)
+}
+
+func ParseQuizVersionQuestionsFromJSON(jsonData []byte) ([]models.QuizVersionQuestion, error) {
+	if len(jsonData) == 0 {
+		return []models.QuizVersionQuestion{}, nil
+	}
+
+	var questions []models.QuizVersionQuestion
+	if err := json.Unmarshal(jsonData, &questions); err != nil {
+		return nil, fmt.Errorf("failed to parse version questions JSON: %w", err)
+	}
+
+	return questions, nil
+}
+
+// ConvertVersionQuestionToModel turns a frozen version question back into a rendered question
+func ConvertVersionQuestionToModel(quizID int64, versionQuestion models.QuizVersionQuestion) *models.Question {
+	question := &models.Question{
+		ID:               versionQuestion.QuestionID,
+		QuizID:           quizID,
+		Question:         versionQuestion.Question,
+		ContentFormat:    versionQuestion.ContentFormat,
+		Type:             versionQuestion.Type,
+		Answers:          versionQuestion.Answers,
+		TimeLimit:        versionQuestion.TimeLimit,
+		PointsMultiplier: versionQuestion.PointsMultiplier,
+		Media:            versionQuestion.Media,
+		Explanation:      versionQuestion.Explanation,
+		ReferenceURL:     versionQuestion.ReferenceURL,
+		Index:            versionQuestion.Index,
+	}
+	if question.ContentFormat == "" {
+		question.ContentFormat = models.ContentFormatPlain
+	}
+
+	RenderQuestionContent(question)
+
+	return question
+}
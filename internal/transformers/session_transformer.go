@@ -19,6 +19,7 @@ func ConvertSQLCSessionToModel(session sqlc.QuizSession) *models.QuizSession {
 		EndedAt:              session.EndedAt.Time,
 		CreatedAt:            session.CreatedAt.Time,
 		UpdatedAt:            session.UpdatedAt.Time,
+		QuizVersionID:        session.QuizVersionID,
 	}
 }
 
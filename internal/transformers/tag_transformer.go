@@ -0,0 +1,52 @@
+package transformers
+
+import (
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/database/sqlc"
+	"github.com/nghiavan0610/btaskee-quiz-service/internal/models"
+)
+
+func ConvertToTagModel(result sqlc.Tag) *models.Tag {
+	return &models.Tag{
+		ID:          result.ID,
+		Name:        result.Name,
+		Slug:        result.Slug,
+		IsCategory:  result.IsCategory,
+		Description: result.Description,
+	}
+}
+
+func ConvertToCategoryModel(result sqlc.ListCategoriesRow) *models.Tag {
+	return &models.Tag{
+		ID:          result.ID,
+		Name:        result.Name,
+		Slug:        result.Slug,
+		IsCategory:  result.IsCategory,
+		Description: result.Description,
+		QuizCount:   &result.QuizCount,
+	}
+}
+
+func ConvertToPopularTagModel(result sqlc.GetPopularTagsRow) *models.Tag {
+	return &models.Tag{
+		ID:         result.ID,
+		Name:       result.Name,
+		Slug:       result.Slug,
+		IsCategory: result.IsCategory,
+		QuizCount:  &result.QuizCount,
+	}
+}
+
+// GroupTagsByQuizID keeps the query order, categories first
+func GroupTagsByQuizID(results []sqlc.GetTagsByQuizIDsRow) map[int64][]models.Tag {
+	tags := make(map[int64][]models.Tag)
+	for _, result := range results {
+		tags[result.QuizID] = append(tags[result.QuizID], models.Tag{
+			ID:         result.ID,
+			Name:       result.Name,
+			Slug:       result.Slug,
+			IsCategory: result.IsCategory,
+		})
+	}
+
+	return tags
+}
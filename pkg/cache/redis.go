@@ -12,6 +12,7 @@ import (
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
 	"github.com/redis/go-redis/v9"
+	"github.com/samber/lo"
 )
 
 type CacheKeyOption struct {
@@ -27,11 +28,28 @@ type (
 	Cache interface {
 		SetGlobal(ctx context.Context, key string, value interface{}, ttl time.Duration) *exception.AppError
 		Set(ctx context.Context, opt CacheKeyOption) *exception.AppError
+		// SetIfNotExists sets the key only when it is missing and reports whether it did
+		SetIfNotExists(ctx context.Context, opt CacheKeyOption) (bool, *exception.AppError)
 		Get(ctx context.Context, opt CacheKeyOption) (string, *exception.AppError)
 		GetObject(ctx context.Context, opt CacheKeyOption, dest interface{}) *exception.AppError
+		// TakeObject reads and deletes the key in one step, so only one caller can claim it
+		TakeObject(ctx context.Context, opt CacheKeyOption, dest interface{}) *exception.AppError
 		Exists(ctx context.Context, opt CacheKeyOption) (bool, *exception.AppError)
+		// Increment adds one to the counter at the key. The TTL starts when the counter is created.
+		Increment(ctx context.Context, opt CacheKeyOption) (int64, *exception.AppError)
+		// ReserveSlot increments the counter at the key unless that would take it past limit, starting
+		// its TTL when the counter is created. It reports the count and whether the slot was taken.
+		ReserveSlot(ctx context.Context, opt CacheKeyOption, limit int64) (int64, bool, *exception.AppError)
+		// ReleaseSlot gives back a slot taken with ReserveSlot. A missing or empty counter is left alone.
+		ReleaseSlot(ctx context.Context, opt CacheKeyOption) *exception.AppError
+		// GetTTL returns how long the key has left, or 0 when it is missing or never expires
+		GetTTL(ctx context.Context, opt CacheKeyOption) (time.Duration, *exception.AppError)
 		Clear(ctx context.Context, password string, systemPassword string) *exception.AppError
 		Del(ctx context.Context, opt CacheKeyOption) *exception.AppError
+		// AddToSet adds members to the set at the key, resetting its TTL when one is given
+		AddToSet(ctx context.Context, opt CacheKeyOption, members ...string) *exception.AppError
+		GetSetMembers(ctx context.Context, opt CacheKeyOption) ([]string, *exception.AppError)
+		RemoveFromSet(ctx context.Context, opt CacheKeyOption, members ...string) *exception.AppError
 		Close()
 	}
 
@@ -41,6 +59,39 @@ type (
 	}
 )
 
+// incrementScript adds one and starts the window of a counter without one in the same atomic step,
+// so a counter can never be left without a TTL. It avoids EXPIRE NX, which needs Redis 7.
+var incrementScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// reserveSlotScript takes a slot and checks it against the limit in one atomic step, so callers
+// racing for the last slot cannot all get it. A rejected attempt does not keep the slot.
+var reserveSlotScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+if count > tonumber(ARGV[2]) then
+	redis.call('DECR', KEYS[1])
+	return {count, 0}
+end
+return {count, 1}
+`)
+
+// releaseSlotScript decrements a counter without creating it or taking it below zero
+var releaseSlotScript = redis.NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]))
+if count and count > 0 then
+	return redis.call('DECR', KEYS[1])
+end
+return 0
+`)
+
 var (
 	cacheOnce     sync.Once
 	cacheInstance Cache
@@ -167,6 +218,29 @@ func (c *cache) Set(ctx context.Context, opt CacheKeyOption) *exception.AppError
 	return nil
 }
 
+func (c *cache) SetIfNotExists(ctx context.Context, opt CacheKeyOption) (bool, *exception.AppError) {
+	ttl := opt.TTL
+	if ttl == 0 {
+		ttl = 24 * time.Hour
+	}
+	cacheKey := buildCacheKey(opt)
+
+	jsonData, err := json.Marshal(opt.Value)
+	if err != nil {
+		return false, exception.InternalError(errors.CodeInternal, errors.ErrFailedToMarshalJSON).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "json_marshal")
+	}
+
+	set, err := c.client.SetNX(ctx, cacheKey, jsonData, ttl).Result()
+	if err != nil {
+		return false, exception.ServiceUnavailable(errors.CodeCacheUnavailable, errors.ErrFailedToSetCache).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "set_nx")
+	}
+	return set, nil
+}
+
 func (c *cache) Get(ctx context.Context, opt CacheKeyOption) (string, *exception.AppError) {
 	cacheKey := buildCacheKey(opt)
 	result, err := c.client.Get(ctx, cacheKey).Result()
@@ -208,6 +282,35 @@ func (c *cache) GetObject(ctx context.Context, opt CacheKeyOption, dest interfac
 	return nil
 }
 
+func (c *cache) TakeObject(ctx context.Context, opt CacheKeyOption, dest interface{}) *exception.AppError {
+	cacheKey := buildCacheKey(opt)
+
+	// MULTI keeps GET and DEL together without needing GETDEL (Redis 6.2+)
+	pipe := c.client.TxPipeline()
+	get := pipe.Get(ctx, cacheKey)
+	pipe.Del(ctx, cacheKey)
+	_, err := pipe.Exec(ctx)
+
+	if err != nil {
+		if err == redis.Nil {
+			return exception.NotFound(errors.CodeCacheNotFound, errors.ErrCacheKeyNotFound).
+				WithMetadata("key", cacheKey)
+		}
+		return exception.ServiceUnavailable(errors.CodeCacheUnavailable, errors.ErrFailedToGetCache).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "take_object")
+	}
+
+	if err := json.Unmarshal([]byte(get.Val()), dest); err != nil {
+		return exception.BadRequest(errors.CodeBadRequest, "Failed to deserialize cached value").
+			WithDetails("Cached value is not a valid JSON object").
+			WithMetadata("key", cacheKey).
+			WithMetadata("error", err.Error())
+	}
+
+	return nil
+}
+
 func (c *cache) Exists(ctx context.Context, opt CacheKeyOption) (bool, *exception.AppError) {
 	cacheKey := buildCacheKey(opt)
 	result, err := c.client.Exists(ctx, cacheKey).Result()
@@ -244,6 +347,100 @@ func (c *cache) Del(ctx context.Context, opt CacheKeyOption) *exception.AppError
 	return nil
 }
 
+func (c *cache) Increment(ctx context.Context, opt CacheKeyOption) (int64, *exception.AppError) {
+	ttl := opt.TTL
+	if ttl == 0 {
+		ttl = 24 * time.Hour
+	}
+	cacheKey := buildCacheKey(opt)
+
+	count, err := incrementScript.Run(ctx, c.client, []string{cacheKey}, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, exception.ServiceUnavailable(errors.CodeCacheUnavailable, errors.ErrFailedToSetCache).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "increment")
+	}
+	return count, nil
+}
+
+func (c *cache) ReserveSlot(ctx context.Context, opt CacheKeyOption, limit int64) (int64, bool, *exception.AppError) {
+	ttl := opt.TTL
+	if ttl == 0 {
+		ttl = 24 * time.Hour
+	}
+	cacheKey := buildCacheKey(opt)
+
+	result, err := reserveSlotScript.Run(ctx, c.client, []string{cacheKey}, ttl.Milliseconds(), limit).Int64Slice()
+	if err != nil || len(result) != 2 {
+		return 0, false, exception.ServiceUnavailable(errors.CodeCacheUnavailable, errors.ErrFailedToSetCache).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "reserve_slot")
+	}
+	return result[0], result[1] == 1, nil
+}
+
+func (c *cache) ReleaseSlot(ctx context.Context, opt CacheKeyOption) *exception.AppError {
+	cacheKey := buildCacheKey(opt)
+	if err := releaseSlotScript.Run(ctx, c.client, []string{cacheKey}).Err(); err != nil {
+		return exception.ServiceUnavailable(errors.CodeCacheUnavailable, errors.ErrFailedToSetCache).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "release_slot")
+	}
+	return nil
+}
+
+func (c *cache) GetTTL(ctx context.Context, opt CacheKeyOption) (time.Duration, *exception.AppError) {
+	cacheKey := buildCacheKey(opt)
+	ttl, err := c.client.TTL(ctx, cacheKey).Result()
+	if err != nil {
+		return 0, exception.ServiceUnavailable(errors.CodeCacheUnavailable, errors.ErrFailedToGetCache).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "ttl")
+	}
+	// Redis reports missing keys and keys without expiry as negative durations
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (c *cache) AddToSet(ctx context.Context, opt CacheKeyOption, members ...string) *exception.AppError {
+	cacheKey := buildCacheKey(opt)
+
+	pipe := c.client.TxPipeline()
+	pipe.SAdd(ctx, cacheKey, lo.ToAnySlice(members)...)
+	if opt.TTL > 0 {
+		pipe.Expire(ctx, cacheKey, opt.TTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return exception.ServiceUnavailable(errors.CodeCacheUnavailable, errors.ErrFailedToSetCache).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "set_add")
+	}
+	return nil
+}
+
+func (c *cache) GetSetMembers(ctx context.Context, opt CacheKeyOption) ([]string, *exception.AppError) {
+	cacheKey := buildCacheKey(opt)
+	members, err := c.client.SMembers(ctx, cacheKey).Result()
+	if err != nil {
+		return nil, exception.ServiceUnavailable(errors.CodeCacheUnavailable, errors.ErrFailedToGetCache).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "set_members")
+	}
+	return members, nil
+}
+
+func (c *cache) RemoveFromSet(ctx context.Context, opt CacheKeyOption, members ...string) *exception.AppError {
+	cacheKey := buildCacheKey(opt)
+	if err := c.client.SRem(ctx, cacheKey, lo.ToAnySlice(members)...).Err(); err != nil {
+		return exception.ServiceUnavailable(errors.CodeCacheUnavailable, errors.ErrFailedToDeleteCache).
+			WithMetadata("key", cacheKey).
+			WithMetadata("operation", "set_remove")
+	}
+	return nil
+}
+
 func (c *cache) Close() {
 	if c.client != nil {
 		c.client.Close()
@@ -6,8 +6,17 @@ type CacheSuffix string
 
 const (
 	// Prefixes
-	CachePrefixAccessToken  CachePrefix = "ACCESS_TOKEN"
-	CachePrefixRefreshToken CachePrefix = "REFRESH_TOKEN"
+	CachePrefixAccessToken    CachePrefix = "ACCESS_TOKEN"
+	CachePrefixRefreshToken   CachePrefix = "REFRESH_TOKEN"
+	CachePrefixAuthSession    CachePrefix = "AUTH_SESSION"       // One entry per signed-in device
+	CachePrefixAuthSessions   CachePrefix = "AUTH_SESSIONS"      // Set of a user's session ids
+	CachePrefixUsedRefresh    CachePrefix = "USED_REFRESH_TOKEN" // Refresh token ids already exchanged
+	CachePrefixUsedAction     CachePrefix = "USED_ACTION_TOKEN"  // Emailed link token ids already used
+	CachePrefixLoginFailure   CachePrefix = "LOGIN_FAILURES"     // Failed sign-ins per email
+	CachePrefixLoginFailureIP CachePrefix = "LOGIN_FAILURES_IP"  // Failed sign-ins per IP
+	CachePrefixLoginDelay     CachePrefix = "LOGIN_DELAY"        // Set while an email must wait before trying again
+	CachePrefixAccountLock    CachePrefix = "ACCOUNT_LOCK"       // Set while an email is locked out
+	CachePrefixOIDCState      CachePrefix = "OIDC_STATE"         // Pending provider logins by state
 	// ...add more as needed
 
 	// Modules
@@ -4,7 +4,19 @@ package constants
 const (
 	MaxQuestionScore  = 1000
 	MinQuestionScore  = 100
-	QuestionTimeLimit = 30
+	QuestionTimeLimit = 30 // seconds, for questions stored without a usable time limit
+
+	DefaultPointsMultiplier = 1
+)
+
+// Quiz Defaults
+const (
+	DefaultMaxParticipants = 100 // Matches the quizzes.max_participants column default
+)
+
+// Question Content Limits
+const (
+	MaxAnswerTextLength = 500 // Question text is capped by the questions.question column instead
 )
 
 // WebSocket Connection Constants
@@ -0,0 +1,15 @@
+package errors
+
+const (
+	CodeAPIKeyInvalid           = "err.api_key.invalid"
+	CodeAPIKeyInsufficientScope = "err.api_key.insufficient_scope"
+)
+
+const (
+	ErrAPIKeyInvalid           = "Invalid API key"
+	ErrAPIKeyExpired           = "API key has expired"
+	ErrAPIKeyNotFound          = "API key not found"
+	ErrAPIKeyInsufficientScope = "API key does not have the required scope"
+	ErrAPIKeyLimitReached      = "API key limit reached"
+	ErrAPIKeyExpiryInPast      = "API key expiry must be in the future"
+)
@@ -1,8 +1,27 @@
 package errors
 
 const (
-	ErrUnauthorizedAccess = "Unauthorized access"
-	ErrForbidden          = "Access forbidden"
-	ErrPasswordHash       = "Failed to hash password"
-	ErrInvalidCredentials = "Invalid credentials"
+	CodeAccountLocked  = "err.auth.account_locked"
+	CodeLoginThrottled = "err.auth.login_throttled"
+	CodeOIDCFailed     = "err.auth.oidc_failed"
+	CodeMFAInvalid     = "err.auth.mfa_invalid"
+)
+
+const (
+	ErrUnauthorizedAccess   = "Unauthorized access"
+	ErrForbidden            = "Access forbidden"
+	ErrPasswordHash         = "Failed to hash password"
+	ErrInvalidCredentials   = "Invalid credentials"
+	ErrLoginSessionNotFound = "Login session not found"
+	ErrEmailAlreadyVerified = "Email is already verified"
+	ErrAccountLocked        = "Account is temporarily locked"
+	ErrTooManyLoginAttempts = "Too many sign-in attempts"
+	ErrOIDCProviderNotFound = "Sign-in provider not found"
+	ErrOIDCLoginFailed      = "Sign-in with the provider failed"
+	ErrOIDCEmailNotLinkable = "An account with this email already exists"
+	ErrMFACodeInvalid       = "Invalid two-factor code"
+	ErrMFAChallengeInvalid  = "Two-factor challenge is invalid or has expired"
+	ErrMFAAlreadyEnabled    = "Two-factor authentication is already enabled"
+	ErrMFANotEnabled        = "Two-factor authentication is not enabled"
+	ErrMFANotEnrolling      = "Start enrolment before confirming a code"
 )
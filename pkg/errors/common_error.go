@@ -31,4 +31,5 @@ const (
 	ErrInvalidPayloadParams  = "Invalid payload parameters"
 	ErrFailedToUnmarshalJSON = "Failed to unmarshal JSON"
 	ErrFailedToMarshalJSON   = "Failed to marshal JSON"
+	ErrRequestBodyTooLarge   = "Request body is too large"
 )
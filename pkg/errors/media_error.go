@@ -0,0 +1,8 @@
+package errors
+
+const (
+	ErrMediaNotFound        = "Media not found"
+	ErrInvalidMediaFile     = "Invalid media file"
+	ErrUnsupportedMediaType = "Unsupported media type"
+	ErrMediaTooLarge        = "Media file is too large"
+)
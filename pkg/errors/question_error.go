@@ -1,5 +1,8 @@
 package errors
 
 const (
-	ErrQuestionNotFound = "Question not found"
+	ErrQuestionNotFound        = "Question not found"
+	ErrInvalidBulkQuestions    = "Some questions in the list are invalid"
+	ErrQuestionNotInQuiz       = "Question does not belong to this quiz"
+	ErrDuplicateQuestionInList = "Question appears more than once in the list"
 )
@@ -1,5 +1,14 @@
 package errors
 
 const (
-	ErrQuizNotFound = "Quiz not found"
+	ErrQuizNotFound          = "Quiz not found"
+	ErrInvalidQuizImportFile = "Invalid quiz import file"
+	ErrInvalidQuizImportRows = "Some questions in the import file are invalid"
+	ErrQuizExportFailed      = "Failed to export quiz"
+	ErrQuizVersionNotFound   = "Quiz version not found"
+	ErrQuizSlugTaken         = "Quiz slug is already taken"
+	ErrInvalidQuizListFilter = "Invalid quiz list filter"
+	ErrCollaboratorNotFound  = "Collaborator not found"
+	ErrQuizNotPublishable    = "Quiz has problems that must be fixed before publishing"
+	ErrInvalidCollaborator   = "Invalid quiz collaborator"
 )
@@ -11,4 +11,5 @@ const (
 	ErrInvalidJoinCode     = "Invalid join code"
 	ErrSessionNotActive    = "Session is not active"
 	ErrSessionNotJoinable  = "Session is not joinable"
+	ErrSessionNotEnded     = "Session has not ended yet"
 )
@@ -0,0 +1,6 @@
+package errors
+
+const (
+	ErrCategoryNotFound = "Category not found"
+	ErrInvalidQuizTags  = "Invalid quiz tags"
+)
@@ -5,4 +5,5 @@ const (
 	ErrUserDisabled          = "User is disabled"
 	ErrEmailAlreadyExists    = "Email already exists"
 	ErrUsernameAlreadyExists = "Username already exists"
+	ErrCannotModerateSelf    = "Admins cannot apply this action to their own account"
 )
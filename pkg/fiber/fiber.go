@@ -1,6 +1,8 @@
 package fiber
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/favicon"
@@ -16,6 +18,8 @@ import (
 func NewFiber(log *logger.Logger, config *config.Config) *fiber.App {
 	app := fiber.New(fiber.Config{
 		ErrorHandler: middlewares.ErrorHandler(log),
+		// Bodies over the default limit are streamed, so media uploads can be larger than JSON requests
+		StreamRequestBody: true,
 	})
 
 	app.Use(helmet.New(helmet.Config{
@@ -37,6 +41,15 @@ func NewFiber(log *logger.Logger, config *config.Config) *fiber.App {
 
 	app.Use(middlewares.RecoveryHandler(log))
 
+	// Media uploads apply their own, larger limit
+	mediaUploadPath := "/api/" + config.Server.ServiceVersion + "/media"
+	app.Use(middlewares.BodyLimit(middlewares.BodyLimitConfig{
+		Limit: fiber.DefaultBodyLimit,
+		Next: func(c *fiber.Ctx) bool {
+			return c.Method() == fiber.MethodPost && strings.TrimSuffix(c.Path(), "/") == mediaUploadPath
+		},
+	}))
+
 	app.Use(middlewares.RateLimit(middlewares.RateLimitConfig{
 		RequestsPerSecond: config.RateLimit.RPS,
 		BurstSize:         config.RateLimit.Burst,
@@ -54,13 +67,16 @@ func NewFiber(log *logger.Logger, config *config.Config) *fiber.App {
 	return app
 }
 
-func SetupRoutes(app *fiber.App, handlers []handlers.AppHandler, config *config.Config) {
+func SetupRoutes(app *fiber.App, appHandlers []handlers.AppHandler, config *config.Config) {
 	apiV1 := app.Group("/api/" + config.Server.ServiceVersion)
 
-	for _, handler := range handlers {
+	for _, handler := range appHandlers {
 		if handler != nil {
 			handler.RegisterRoutes(apiV1)
 		}
+		if rootHandler, ok := handler.(handlers.RootHandler); ok {
+			rootHandler.RegisterRootRoutes(app)
+		}
 	}
 
 	app.Use(middlewares.NotFoundHandler())
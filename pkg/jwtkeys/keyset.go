@@ -0,0 +1,326 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+const (
+	privateKeySuffix = ".pem"
+	publicKeySuffix  = ".pub.pem"
+)
+
+// Key is one signing key. Retired keys only have the public half and are kept so tokens they signed
+// still verify until those expire.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// KeySet signs tokens with the active key and verifies them with any key in the directory.
+//
+// The directory holds one PEM file per key, named after its kid: "<kid>.pem" for a private key
+// (RSA or Ed25519, PKCS#1 or PKCS#8) and "<kid>.pub.pem" for the public key of a retired one.
+// To rotate, add a new private key and make it active; keep the old file, or just its public key,
+// until the tokens it signed have expired.
+type KeySet interface {
+	Sign(claims jwt.Claims) (string, error)
+	// Keyfunc finds the verification key named by the token's kid header
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	ValidMethods() []string
+	JWKS() *JSONWebKeySet
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type keySet struct {
+	active *Key
+	keys   map[string]*Key
+	jwks   *JSONWebKeySet
+}
+
+var (
+	keySetOnce     sync.Once
+	keySetInstance KeySet
+	keySetError    error
+)
+
+func ProvideKeySet(cfg *config.Config, logger *logger.Logger) (KeySet, error) {
+	keySetOnce.Do(func() {
+		keySetInstance, keySetError = LoadKeySet(cfg.JWT.GetKeyDir(), cfg.JWT.SigningKeyID, cfg.Server.IsDevelopment(), logger)
+	})
+
+	return keySetInstance, keySetError
+}
+
+// LoadKeySet reads every key in dir. activeKeyID picks the signing key; when empty the private key
+// with the greatest kid is used, so date-named kids such as "2025-08-14" rotate by adding a newer file. With
+// generateIfEmpty, as in local development, an empty directory gets a freshly generated Ed25519 key; otherwise
+// it is an error, so a deployment never signs with a key nobody provisioned.
+func LoadKeySet(dir, activeKeyID string, generateIfEmpty bool, logger *logger.Logger) (KeySet, error) {
+	if generateIfEmpty {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create key dir: %w", err)
+		}
+	}
+
+	keys, err := readKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		if !generateIfEmpty {
+			return nil, fmt.Errorf("no signing keys found in %s", dir)
+		}
+		key, err := generateKey(dir)
+		if err != nil {
+			return nil, err
+		}
+		logger.Warn("[JWT KEYS] No signing keys found, generated one", map[string]string{"kid": key.ID, "dir": dir})
+		keys[key.ID] = key
+	}
+
+	active, err := pickActiveKey(keys, activeKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &keySet{
+		active: active,
+		keys:   keys,
+	}
+	set.jwks = set.buildJWKS()
+
+	return set, nil
+}
+
+func (s *keySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	token.Header["kid"] = s.active.ID
+	return token.SignedString(s.active.Private)
+}
+
+func (s *keySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("key %q does not sign with %s", kid, token.Method.Alg())
+	}
+	return key.Public, nil
+}
+
+func (s *keySet) ValidMethods() []string {
+	return []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+}
+
+func (s *keySet) JWKS() *JSONWebKeySet {
+	return s.jwks
+}
+
+func (s *keySet) buildJWKS() *JSONWebKeySet {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(ids))}
+	for _, id := range ids {
+		key := s.keys[id]
+		jwk := JSONWebKey{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func readKeys(dir string) (map[string]*Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key dir: %w", err)
+	}
+
+	keys := make(map[string]*Key)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, privateKeySuffix) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s: %w", name, err)
+		}
+
+		var key *Key
+		if strings.HasSuffix(name, publicKeySuffix) {
+			key, err = parsePublicKey(strings.TrimSuffix(name, publicKeySuffix), data)
+		} else {
+			key, err = parsePrivateKey(strings.TrimSuffix(name, privateKeySuffix), data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", name, err)
+		}
+
+		// A private key wins over the public key of the same kid
+		if existing, ok := keys[key.ID]; ok && existing.Private != nil {
+			continue
+		}
+		keys[key.ID] = key
+	}
+
+	return keys, nil
+}
+
+func parsePrivateKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, Private: private, Public: &private.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, Private: private, Public: private.Public()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+}
+
+func parsePublicKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch public := parsed.(type) {
+	case *rsa.PublicKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, Public: public}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, Public: public}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+}
+
+func pickActiveKey(keys map[string]*Key, activeKeyID string) (*Key, error) {
+	if activeKeyID != "" {
+		key, ok := keys[activeKeyID]
+		if !ok || key.Private == nil {
+			return nil, fmt.Errorf("no private key found for signing key id %q", activeKeyID)
+		}
+		return key, nil
+	}
+
+	var active *Key
+	for _, key := range keys {
+		if key.Private != nil && (active == nil || key.ID > active.ID) {
+			active = key
+		}
+	}
+	if active == nil {
+		return nil, errors.New("no private key to sign with, only public keys were found")
+	}
+	return active, nil
+}
+
+func generateKey(dir string) (*Key, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	kid := time.Now().UTC().Format("2006-01-02T150405")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+privateKeySuffix), data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write generated key: %w", err)
+	}
+
+	return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, Private: private, Public: public}, nil
+}
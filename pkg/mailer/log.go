@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+type logMailer struct {
+	dir    string
+	logger *logger.Logger
+}
+
+// NewLogMailer logs the recipient and subject of every message and, when dir is set, writes the full
+// message there as a .eml file. Bodies hold live account links, so they never go to the log.
+func NewLogMailer(dir string, logger *logger.Logger) (Mailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mail log dir: %w", err)
+		}
+	}
+
+	return &logMailer{
+		dir:    dir,
+		logger: logger,
+	}, nil
+}
+
+func (m *logMailer) Send(ctx context.Context, msg *Message) error {
+	m.logger.Info("[MAIL]", map[string]string{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	})
+
+	if m.dir == "" {
+		return nil
+	}
+
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString()[:8])
+	return os.WriteFile(filepath.Join(m.dir, name), []byte(content), 0o644)
+}
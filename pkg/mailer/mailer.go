@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+// Mailer delivers transactional email. SMTP is used in deployed environments, the log driver
+// writes messages to a directory so local development needs no mail server.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+var (
+	mailerOnce     sync.Once
+	mailerInstance Mailer
+	mailerError    error
+)
+
+func ProvideMailer(cfg *config.Config, logger *logger.Logger) (Mailer, error) {
+	mailerOnce.Do(func() {
+		switch cfg.Mail.Driver {
+		case "", "log":
+			// The log driver sends nothing, so outside development it would silently drop every
+			// reset and verification email
+			if !cfg.Server.IsDevelopment() {
+				mailerError = fmt.Errorf("mail driver %q is only allowed in development, set MAIL_DRIVER=smtp", cfg.Mail.Driver)
+				return
+			}
+			mailerInstance, mailerError = NewLogMailer(cfg.Mail.LogDir, logger)
+		case "smtp":
+			mailerInstance, mailerError = NewSMTPMailer(cfg.Mail)
+		default:
+			mailerError = fmt.Errorf("unsupported mail driver %q", cfg.Mail.Driver)
+		}
+	})
+
+	return mailerInstance, mailerError
+}
@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+)
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from mail.Address
+}
+
+func NewSMTPMailer(cfg config.MailConfig) (Mailer, error) {
+	if cfg.SMTPHost == "" {
+		return nil, errors.New("mailer: SMTP_HOST is required for the smtp driver")
+	}
+	from, err := mail.ParseAddress(cfg.GetFrom())
+	if err != nil {
+		return nil, fmt.Errorf("mailer: invalid MAIL_FROM: %w", err)
+	}
+
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &smtpMailer{
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.GetSMTPPort())),
+		auth: auth,
+		from: *from,
+	}, nil
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("mailer: invalid recipient: %w", err)
+	}
+
+	// net/smtp has no context support, so only honour a cancellation that already happened
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, m.from.Address, []string{to.Address}, buildMessage(m.from, *to, msg))
+}
+
+func buildMessage(from, to mail.Address, msg *Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + to.String() + "\r\n")
+	b.WriteString("Subject: " + mimeHeader(msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+func mimeHeader(value string) string {
+	return mime.QEncoding.Encode("utf-8", value)
+}
@@ -0,0 +1,45 @@
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	// Raw HTML in the source is dropped by goldmark already, the sanitizer is the second line of defence
+	renderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// Keep the language of fenced code blocks so clients can highlight them
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	return p
+}
+
+// Render converts markdown to HTML that is safe to embed as is.
+// TeX math between $...$, $$...$$, \(...\) or \[...\] is passed through untouched, delimiters included,
+// inside a span with the class math-inline or math-display for the client to typeset.
+func Render(source string) string {
+	protected, spans := extractMath(source)
+
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(protected), &buf); err != nil {
+		return RenderPlain(source)
+	}
+
+	return restoreMath(policy.Sanitize(buf.String()), spans)
+}
+
+// RenderPlain escapes text that is not markdown, keeping its line breaks
+func RenderPlain(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
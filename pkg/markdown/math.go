@@ -0,0 +1,187 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Math is swapped for placeholders made of private use characters, which markdown treats as plain text
+const (
+	placeholderStart = "\uE000"
+	placeholderEnd   = "\uE001"
+)
+
+var (
+	placeholderPattern  = regexp.MustCompile(placeholderStart + `(\d+)` + placeholderEnd)
+	placeholderStripper = strings.NewReplacer(placeholderStart, "", placeholderEnd, "")
+)
+
+type mathSpan struct {
+	source  string
+	display bool
+}
+
+// extractMath replaces every math span outside code with a placeholder, so markdown does not
+// treat underscores, asterisks or backslash escapes inside formulas as formatting
+func extractMath(source string) (string, []mathSpan) {
+	source = placeholderStripper.Replace(source)
+
+	var out, prose strings.Builder
+	var spans []mathSpan
+	var fence string
+
+	flushProse := func() {
+		extractInlineMath(prose.String(), &out, &spans)
+		prose.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(source, "\n") {
+		marker := fenceMarker(line)
+
+		switch {
+		case fence != "":
+			out.WriteString(line)
+			if marker != "" && marker[0] == fence[0] && len(marker) >= len(fence) {
+				fence = ""
+			}
+		case marker != "":
+			flushProse()
+			out.WriteString(line)
+			fence = marker
+		default:
+			prose.WriteString(line)
+		}
+	}
+	flushProse()
+
+	return out.String(), spans
+}
+
+// fenceMarker returns the run of backticks or tildes that opens or closes a fenced code block on this line
+func fenceMarker(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return ""
+	}
+
+	run := countRun(trimmed, trimmed[0])
+	if run < 3 {
+		return ""
+	}
+	return trimmed[:run]
+}
+
+func extractInlineMath(text string, out *strings.Builder, spans *[]mathSpan) {
+	addSpan := func(source string, display bool) {
+		out.WriteString(placeholderStart + strconv.Itoa(len(*spans)) + placeholderEnd)
+		*spans = append(*spans, mathSpan{source: source, display: display})
+	}
+
+	for i := 0; i < len(text); {
+		switch {
+		case text[i] == '`':
+			// Inline code is copied verbatim up to the matching run of backticks
+			run := countRun(text[i:], '`')
+			if end := strings.Index(text[i+run:], text[i:i+run]); end >= 0 {
+				out.WriteString(text[i : i+run+end+run])
+				i += run + end + run
+				continue
+			}
+			out.WriteString(text[i : i+run])
+			i += run
+
+		case text[i] == '\\' && i+1 < len(text):
+			closer := map[byte]string{'(': `\)`, '[': `\]`}[text[i+1]]
+			if closer != "" {
+				if end := strings.Index(text[i+2:], closer); end >= 0 {
+					length := 2 + end + len(closer)
+					addSpan(text[i:i+length], text[i+1] == '[')
+					i += length
+					continue
+				}
+			}
+			// Any other escape, \$ included, is left for markdown to resolve
+			out.WriteString(text[i : i+2])
+			i += 2
+
+		case strings.HasPrefix(text[i:], "$$"):
+			if end := strings.Index(text[i+2:], "$$"); end > 0 {
+				length := 2 + end + 2
+				addSpan(text[i:i+length], true)
+				i += length
+				continue
+			}
+			out.WriteString("$$")
+			i += 2
+
+		case text[i] == '$':
+			if end := inlineMathEnd(text, i); end > 0 {
+				addSpan(text[i:end], false)
+				i = end
+				continue
+			}
+			out.WriteByte('$')
+			i++
+
+		default:
+			out.WriteByte(text[i])
+			i++
+		}
+	}
+}
+
+// inlineMathEnd finds the end of $...$ starting at start, using the pandoc rules that keep
+// prices such as "$5 and $10" from being read as math: no space just inside either dollar
+// and no digit right after the closing one
+func inlineMathEnd(text string, start int) int {
+	if start+1 >= len(text) || isSpace(text[start+1]) {
+		return -1
+	}
+
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '\n':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				return -1
+			}
+		case '$':
+			if i == start+1 || isSpace(text[i-1]) || (i+1 < len(text) && text[i+1] >= '0' && text[i+1] <= '9') {
+				return -1
+			}
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+func restoreMath(rendered string, spans []mathSpan) string {
+	return placeholderPattern.ReplaceAllStringFunc(rendered, func(placeholder string) string {
+		index, err := strconv.Atoi(placeholderPattern.FindStringSubmatch(placeholder)[1])
+		if err != nil || index >= len(spans) {
+			return ""
+		}
+
+		class := "math-inline"
+		if spans[index].display {
+			class = "math-display"
+		}
+		return `<span class="` + class + `">` + html.EscapeString(spans[index].source) + `</span>`
+	})
+}
+
+func countRun(text string, char byte) int {
+	run := 0
+	for run < len(text) && text[run] == char {
+		run++
+	}
+	return run
+}
+
+func isSpace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n'
+}
@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/exception"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/response"
+)
+
+type BodyLimitConfig struct {
+	Limit int                   // Largest body in bytes read into memory
+	Next  func(*fiber.Ctx) bool // Skips the check, for routes that apply their own limit
+}
+
+// BodyLimit rejects request bodies larger than the limit. With StreamRequestBody on, fasthttp hands
+// bodies over the app's BodyLimit to the handler as a stream instead of refusing them, so this is what
+// keeps them bounded. A streamed body within the limit is read into memory for the handler.
+func BodyLimit(config BodyLimitConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if config.Next != nil && config.Next(c) {
+			return c.Next()
+		}
+
+		if appErr := readBody(c, config.Limit); appErr != nil {
+			return response.Error(c, appErr)
+		}
+
+		return c.Next()
+	}
+}
+
+func readBody(c *fiber.Ctx, limit int) *exception.AppError {
+	req := c.Request()
+	if !req.IsBodyStream() {
+		if len(req.Body()) > limit {
+			return bodyTooLarge(limit)
+		}
+		return nil
+	}
+
+	// The rest of a rejected stream is never read, so the connection cannot carry another request
+	if req.Header.ContentLength() > limit {
+		c.Context().SetConnectionClose()
+		return bodyTooLarge(limit)
+	}
+	// Chunked bodies have no length up front, so the stream itself is capped
+	body, err := io.ReadAll(io.LimitReader(req.BodyStream(), int64(limit)+1))
+	if err != nil {
+		c.Context().SetConnectionClose()
+		return exception.BadRequest(errors.CodeBadRequest, errors.ErrInvalidBodyParams).WithDetails(err.Error())
+	}
+	if len(body) > limit {
+		c.Context().SetConnectionClose()
+		return bodyTooLarge(limit)
+	}
+	req.SetBody(body)
+
+	return nil
+}
+
+func bodyTooLarge(limit int) *exception.AppError {
+	return exception.NewAppError(http.StatusRequestEntityTooLarge, errors.CodeBadRequest, errors.ErrRequestBodyTooLarge).
+		WithDetails(fmt.Sprintf("Request body must be at most %d bytes", limit))
+}
@@ -5,7 +5,6 @@ import (
 	"log/slog"
 	"strings"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/constants"
 	"github.com/nghiavan0610/btaskee-quiz-service/pkg/errors"
@@ -113,30 +112,12 @@ func PayloadValidator[T interface{}]() fiber.Handler {
 func logError(c *fiber.Ctx, err error) {
 	slog.Error(c.Method(), c.OriginalURL(), slog.Any("err", err))
 
-	// Avoid log with sensitive data
-	if !strings.Contains(c.Path(), "oidc") {
+	// Avoid log with sensitive data: auth bodies carry passwords, reset and verification tokens and MFA codes
+	if !strings.Contains(c.Path(), "/auth/") {
 		fmt.Printf("Request Body %s\n", c.Body())
 	}
 }
 
 func parseValidationError(err error) map[string]string {
-	if err == nil {
-		return nil
-	}
-
-	if errs, ok := err.(validator.ValidationErrors); ok {
-		messages := make(map[string]string)
-		for _, e := range errs {
-			field := e.Field()
-			tag := e.Tag()
-
-			if fn, found := utils.ValidationMessages[tag]; found {
-				messages[field] = fn(e)
-			} else {
-				messages[field] = e.Error()
-			}
-		}
-		return messages
-	}
-	return map[string]string{"error": err.Error()}
+	return utils.ParseValidationError(err)
 }
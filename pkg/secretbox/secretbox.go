@@ -0,0 +1,120 @@
+// Package secretbox encrypts small secrets for storage with AES-256-GCM, so a copy of the database
+// alone is not enough to use them.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+	"github.com/nghiavan0610/btaskee-quiz-service/pkg/logger"
+)
+
+// sealedPrefix marks values written by Seal, so stored values from before encryption can be told apart
+const sealedPrefix = "v1:"
+
+var ErrNotSealed = errors.New("secretbox: value is not sealed")
+
+type (
+	Box interface {
+		// Seal encrypts plaintext with a random nonce
+		Seal(plaintext string) (string, error)
+		// Open decrypts a value from Seal. It returns ErrNotSealed for a value Seal did not produce.
+		Open(sealed string) (string, error)
+	}
+
+	box struct {
+		aead cipher.AEAD
+	}
+)
+
+var (
+	boxOnce     sync.Once
+	boxInstance Box
+	boxError    error
+)
+
+// ProvideBox reads the key from AUTH_TOTP_ENCRYPTION_KEY. Only in development does a missing key fall
+// back to a fixed one, so a deployment never stores secrets under a key anyone can read in this file.
+func ProvideBox(cfg *config.Config, logger *logger.Logger) (Box, error) {
+	boxOnce.Do(func() {
+		encodedKey := cfg.Auth.TOTPEncryptionKey
+		if encodedKey == "" {
+			if !cfg.Server.IsDevelopment() {
+				boxError = fmt.Errorf("AUTH_TOTP_ENCRYPTION_KEY is required outside development")
+				return
+			}
+			logger.Warn("[SECRETBOX] AUTH_TOTP_ENCRYPTION_KEY is not set, using the development key")
+			devKey := sha256.Sum256([]byte("btaskee-quiz-service development key"))
+			boxInstance, boxError = New(devKey[:])
+			return
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			boxError = fmt.Errorf("AUTH_TOTP_ENCRYPTION_KEY is not valid base64: %w", err)
+			return
+		}
+		boxInstance, boxError = New(key)
+	})
+
+	return boxInstance, boxError
+}
+
+// New returns a Box for a 32 byte AES-256 key
+func New(key []byte) (Box, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secretbox: key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &box{aead: aead}, nil
+}
+
+func (b *box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (b *box) Open(sealed string) (string, error) {
+	encoded, ok := strings.CutPrefix(sealed, sealedPrefix)
+	if !ok {
+		return "", ErrNotSealed
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("secretbox: %w", err)
+	}
+	if len(data) < b.aead.NonceSize() {
+		return "", fmt.Errorf("secretbox: sealed value is too short")
+	}
+
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("secretbox: %w", err)
+	}
+
+	return string(plaintext), nil
+}
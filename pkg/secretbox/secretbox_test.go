@@ -0,0 +1,80 @@
+package secretbox
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestBox(t *testing.T, fill byte) Box {
+	t.Helper()
+	b, err := New(bytes.Repeat([]byte{fill}, 32))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return b
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	b := newTestBox(t, 1)
+
+	sealed, err := b.Seal("GEZDGNBVGY3TQOJQ")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if strings.Contains(sealed, "GEZDGNBVGY3TQOJQ") {
+		t.Fatal("sealed value contains the plaintext")
+	}
+
+	opened, err := b.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if opened != "GEZDGNBVGY3TQOJQ" {
+		t.Errorf("Open = %q", opened)
+	}
+
+	again, err := b.Seal("GEZDGNBVGY3TQOJQ")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if again == sealed {
+		t.Error("sealing twice gave the same value, the nonce is not random")
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	b := newTestBox(t, 1)
+	sealed, err := b.Seal("secret")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	if _, err := b.Open("GEZDGNBVGY3TQOJQ"); !errors.Is(err, ErrNotSealed) {
+		t.Errorf("plain value: err = %v, want ErrNotSealed", err)
+	}
+	if _, err := newTestBox(t, 2).Open(sealed); err == nil {
+		t.Error("opened with the wrong key")
+	}
+
+	// Flip a character in the middle, where every bit is data rather than base64 padding
+	i := len(sealed) / 2
+	flipped := byte('A')
+	if sealed[i] == 'A' {
+		flipped = 'B'
+	}
+	tampered := sealed[:i] + string(flipped) + sealed[i+1:]
+	if _, err := b.Open(tampered); err == nil {
+		t.Error("opened a tampered value")
+	}
+	if _, err := b.Open(sealedPrefix + "AAAA"); err == nil {
+		t.Error("opened a value shorter than the nonce")
+	}
+}
+
+func TestNewRejectsShortKey(t *testing.T) {
+	if _, err := New(make([]byte, 16)); err == nil {
+		t.Error("accepted a 16 byte key")
+	}
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/nghiavan0610/btaskee-quiz-service/config"
+)
+
+var ErrObjectNotFound = errors.New("storage: object not found")
+
+// Storage keeps uploaded files. Keys are slash separated paths such as "media/2025/08/abc.png".
+// The local filesystem is the only driver for now; an S3-compatible one can implement the same interface.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+var (
+	storageOnce     sync.Once
+	storageInstance Storage
+	storageError    error
+)
+
+func ProvideStorage(cfg *config.Config) (Storage, error) {
+	storageOnce.Do(func() {
+		publicURL := cfg.Storage.PublicURL
+		if publicURL == "" {
+			publicURL = "/api/" + cfg.Server.ServiceVersion + "/media/files"
+		}
+		publicURL = strings.TrimSuffix(publicURL, "/")
+
+		switch cfg.Storage.Driver {
+		case "", "local":
+			root := cfg.Storage.LocalRoot
+			if root == "" {
+				root = "./uploads"
+			}
+			storageInstance, storageError = NewLocalStorage(root, publicURL)
+		default:
+			storageError = fmt.Errorf("unsupported storage driver %q", cfg.Storage.Driver)
+		}
+	})
+
+	return storageInstance, storageError
+}
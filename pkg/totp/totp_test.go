@@ -0,0 +1,143 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed from RFC 6238 Appendix B, "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfcVectors are the SHA1 rows of RFC 6238 Appendix B. The RFC lists 8 digit codes; a 6 digit code is
+// the same value truncated to its last 6 digits.
+var rfcVectors = []struct {
+	unix int64
+	step int64
+	code string
+}{
+	{59, 0x0000000000000001, "94287082"},
+	{1111111109, 0x00000000023523EC, "07081804"},
+	{1111111111, 0x00000000023523ED, "14050471"},
+	{1234567890, 0x000000000273EF07, "89005924"},
+	{2000000000, 0x0000000003F940AA, "69279037"},
+	{20000000000, 0x0000000027BC86AA, "65353130"},
+}
+
+func TestGenerateRFC6238Vectors(t *testing.T) {
+	key, err := encoding.DecodeString(rfcSecret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+
+	for _, tc := range rfcVectors {
+		if step := tc.unix / Period; step != tc.step {
+			t.Errorf("T=%d: step = %#x, want %#x", tc.unix, step, tc.step)
+		}
+
+		want := tc.code[len(tc.code)-Digits:]
+		if got := generate(key, tc.step); got != want {
+			t.Errorf("T=%d: generate = %s, want %s", tc.unix, got, want)
+		}
+	}
+}
+
+func TestValidateRFC6238Vectors(t *testing.T) {
+	for _, tc := range rfcVectors {
+		code := tc.code[len(tc.code)-Digits:]
+		step, ok := Validate(rfcSecret, code, time.Unix(tc.unix, 0))
+		if !ok {
+			t.Errorf("T=%d: code %s rejected", tc.unix, code)
+			continue
+		}
+		if step != tc.step {
+			t.Errorf("T=%d: step = %#x, want %#x", tc.unix, step, tc.step)
+		}
+	}
+}
+
+func TestValidateSkewWindow(t *testing.T) {
+	key, err := encoding.DecodeString(rfcSecret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / Period
+
+	tests := []struct {
+		name   string
+		offset int64
+		valid  bool
+	}{
+		{"two periods early", -2, false},
+		{"one period early", -1, true},
+		{"current period", 0, true},
+		{"one period late", 1, true},
+		{"two periods late", 2, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := current + tc.offset
+			code := generate(key, step)
+
+			gotStep, ok := Validate(rfcSecret, code, now)
+			if ok != tc.valid {
+				t.Fatalf("Validate(%s) ok = %v, want %v", code, ok, tc.valid)
+			}
+			if ok && gotStep != step {
+				t.Errorf("step = %d, want %d", gotStep, step)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsMalformedInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"short code", rfcSecret, "28708"},
+		{"long code", rfcSecret, "94287082"},
+		{"wrong code", rfcSecret, "287083"},
+		{"secret not base32", "not-base32!", "287082"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, ok := Validate(tc.secret, tc.code, now); ok {
+				t.Errorf("Validate(%q, %q) accepted", tc.secret, tc.code)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptsLowercaseSecret(t *testing.T) {
+	if _, ok := Validate(strings.ToLower(rfcSecret), "287082", time.Unix(59, 0)); !ok {
+		t.Error("lowercase secret rejected")
+	}
+}
+
+func TestGenerateSecretRoundTrips(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("decode generated secret: %v", err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("secret is %d bytes, want %d", len(key), secretSize)
+	}
+
+	now := time.Now()
+	if _, ok := Validate(secret, generate(key, now.Unix()/Period), now); !ok {
+		t.Error("code for a generated secret rejected")
+	}
+}
@@ -149,12 +149,7 @@ func isBase64Image(fl validator.FieldLevel) bool {
 		return false
 	}
 
-	contentType := ""
-	if strings.HasPrefix(string(data), "<svg") {
-		contentType = "image/svg+xml"
-	} else {
-		contentType = http.DetectContentType(data)
-	}
+	contentType := DetectContentType(data)
 
 	if IsEmpty(allowedTypes) {
 		return strings.HasPrefix(contentType, "image/")
@@ -163,6 +158,17 @@ func isBase64Image(fl validator.FieldLevel) bool {
 	return slices.Contains(allowedTypes, contentType)
 }
 
+// DetectContentType sniffs the MIME type of file content, without parameters such as charset.
+// SVG is recognised separately because http.DetectContentType reports it as text.
+func DetectContentType(data []byte) string {
+	if strings.HasPrefix(string(data), "<svg") {
+		return "image/svg+xml"
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	return contentType
+}
+
 func minfileSizeMatch(fl validator.FieldLevel) bool {
 	content := fl.Field().String()
 	minSize, err := strconv.Atoi(fl.Param())
@@ -382,3 +388,26 @@ func ValidateSingle(val any, rules string) error {
 
 	return v.Var(val, rules)
 }
+
+// ParseValidationError flattens validator errors into a field -> message map
+func ParseValidationError(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		messages := make(map[string]string)
+		for _, e := range errs {
+			field := e.Field()
+			tag := e.Tag()
+
+			if fn, found := ValidationMessages[tag]; found {
+				messages[field] = fn(e)
+			} else {
+				messages[field] = e.Error()
+			}
+		}
+		return messages
+	}
+	return map[string]string{"error": err.Error()}
+}